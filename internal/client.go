@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// Protocol versions a client can negotiate with HELLO
+const (
+	Resp2 = 2
+	Resp3 = 3
+)
+
+// Client holds the state of a single client connection.
+type Client struct {
+	ID   int64
+	Conn net.Conn
+
+	// The protocol is read when writing every reply, which can happen on a
+	// different goroutine than the HELLO that changes it.
+	protocol atomic.Int32
+
+	// Set once the connection stops reading commands, so that the commands
+	// still running for the client do not wait on its behalf
+	closed atomic.Bool
+
+	mu   sync.Mutex
+	name string
+}
+
+// NewClient creates a client that speaks RESP2 until it negotiates otherwise.
+func NewClient(id int64, conn net.Conn) *Client {
+	c := &Client{ID: id, Conn: conn}
+	c.protocol.Store(Resp2)
+	return c
+}
+
+func (c *Client) Protocol() int {
+	return int(c.protocol.Load())
+}
+
+func (c *Client) SetProtocol(protocol int) {
+	c.protocol.Store(int32(protocol))
+}
+
+func (c *Client) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.name
+}
+
+func (c *Client) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.name = name
+}
+
+// MarkClosed records that the connection of the client is going away.
+func (c *Client) MarkClosed() {
+	c.closed.Store(true)
+}
+
+func (c *Client) Closed() bool {
+	return c.closed.Load()
+}
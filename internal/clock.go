@@ -0,0 +1,15 @@
+package internal
+
+import "time"
+
+// Clock is the source of time used for key expiry. Tests can swap in their
+// own implementation to move time forward without sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
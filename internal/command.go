@@ -1,17 +1,153 @@
 package internal
 
-import (
-	"fmt"
-	"net"
-
-	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
-)
+import "github.com/ram-the-coder/redisgo/internal/resp/rtypes"
 
 const (
-	CommandGet   = "get"
-	CommandHello = "hello"
-	CommandPing  = "ping"
-	CommandSet   = "set"
+	CommandAppend           = "append"
+	CommandBLMove           = "blmove"
+	CommandBLMPop           = "blmpop"
+	CommandBLPop            = "blpop"
+	CommandBRPop            = "brpop"
+	CommandBRPopLPush       = "brpoplpush"
+	CommandBZMPop           = "bzmpop"
+	CommandBZPopMax         = "bzpopmax"
+	CommandBZPopMin         = "bzpopmin"
+	CommandCommand          = "command"
+	CommandCopy             = "copy"
+	CommandDbSize           = "dbsize"
+	CommandDecr             = "decr"
+	CommandDecrBy           = "decrby"
+	CommandDel              = "del"
+	CommandExists           = "exists"
+	CommandExpire           = "expire"
+	CommandExpireAt         = "expireat"
+	CommandExpireTime       = "expiretime"
+	CommandGet              = "get"
+	CommandGetDel           = "getdel"
+	CommandGetEx            = "getex"
+	CommandGetRange         = "getrange"
+	CommandGetSet           = "getset"
+	CommandHDel             = "hdel"
+	CommandHello            = "hello"
+	CommandHExists          = "hexists"
+	CommandHExpire          = "hexpire"
+	CommandHExpireAt        = "hexpireat"
+	CommandHExpireTime      = "hexpiretime"
+	CommandHGet             = "hget"
+	CommandHGetAll          = "hgetall"
+	CommandHGetEx           = "hgetex"
+	CommandHIncrBy          = "hincrby"
+	CommandHIncrByFloat     = "hincrbyfloat"
+	CommandHKeys            = "hkeys"
+	CommandHLen             = "hlen"
+	CommandHMGet            = "hmget"
+	CommandHMSet            = "hmset"
+	CommandHPersist         = "hpersist"
+	CommandHPExpire         = "hpexpire"
+	CommandHPExpireAt       = "hpexpireat"
+	CommandHPExpireTime     = "hpexpiretime"
+	CommandHPTTL            = "hpttl"
+	CommandHRandField       = "hrandfield"
+	CommandHScan            = "hscan"
+	CommandHSet             = "hset"
+	CommandHSetEx           = "hsetex"
+	CommandHSetNX           = "hsetnx"
+	CommandHStrLen          = "hstrlen"
+	CommandHTTL             = "httl"
+	CommandHVals            = "hvals"
+	CommandIncr             = "incr"
+	CommandIncrBy           = "incrby"
+	CommandIncrByFloat      = "incrbyfloat"
+	CommandKeys             = "keys"
+	CommandLcs              = "lcs"
+	CommandLIndex           = "lindex"
+	CommandLInsert          = "linsert"
+	CommandLLen             = "llen"
+	CommandLMove            = "lmove"
+	CommandLMPop            = "lmpop"
+	CommandLPop             = "lpop"
+	CommandLPos             = "lpos"
+	CommandLPush            = "lpush"
+	CommandLPushX           = "lpushx"
+	CommandLRange           = "lrange"
+	CommandLRem             = "lrem"
+	CommandLSet             = "lset"
+	CommandLTrim            = "ltrim"
+	CommandMGet             = "mget"
+	CommandMSet             = "mset"
+	CommandMSetNX           = "msetnx"
+	CommandObject           = "object"
+	CommandPersist          = "persist"
+	CommandPExpire          = "pexpire"
+	CommandPExpireAt        = "pexpireat"
+	CommandPExpireTime      = "pexpiretime"
+	CommandPing             = "ping"
+	CommandPSetEx           = "psetex"
+	CommandPTTL             = "pttl"
+	CommandRename           = "rename"
+	CommandRenameNX         = "renamenx"
+	CommandRPop             = "rpop"
+	CommandRPopLPush        = "rpoplpush"
+	CommandRPush            = "rpush"
+	CommandRPushX           = "rpushx"
+	CommandSAdd             = "sadd"
+	CommandScan             = "scan"
+	CommandSCard            = "scard"
+	CommandSDiff            = "sdiff"
+	CommandSDiffStore       = "sdiffstore"
+	CommandSet              = "set"
+	CommandSetEx            = "setex"
+	CommandSetNX            = "setnx"
+	CommandSetRange         = "setrange"
+	CommandSInter           = "sinter"
+	CommandSInterCard       = "sintercard"
+	CommandSInterStore      = "sinterstore"
+	CommandSIsMember        = "sismember"
+	CommandSMembers         = "smembers"
+	CommandSMIsMember       = "smismember"
+	CommandSMove            = "smove"
+	CommandSPop             = "spop"
+	CommandSRandMember      = "srandmember"
+	CommandSRem             = "srem"
+	CommandSScan            = "sscan"
+	CommandStrLen           = "strlen"
+	CommandSUnion           = "sunion"
+	CommandSUnionStore      = "sunionstore"
+	CommandTouch            = "touch"
+	CommandTTL              = "ttl"
+	CommandType             = "type"
+	CommandUnlink           = "unlink"
+	CommandZAdd             = "zadd"
+	CommandZCard            = "zcard"
+	CommandZCount           = "zcount"
+	CommandZDiff            = "zdiff"
+	CommandZDiffStore       = "zdiffstore"
+	CommandZIncrBy          = "zincrby"
+	CommandZInter           = "zinter"
+	CommandZInterStore      = "zinterstore"
+	CommandZLexCount        = "zlexcount"
+	CommandZMPop            = "zmpop"
+	CommandZMScore          = "zmscore"
+	CommandZPopMax          = "zpopmax"
+	CommandZPopMin          = "zpopmin"
+	CommandZRandMember      = "zrandmember"
+	CommandZRange           = "zrange"
+	CommandZRangeByLex      = "zrangebylex"
+	CommandZRangeByScore    = "zrangebyscore"
+	CommandZRangeStore      = "zrangestore"
+	CommandZRank            = "zrank"
+	CommandZRem             = "zrem"
+	CommandZRemRangeByLex   = "zremrangebylex"
+	CommandZRemRangeByRank  = "zremrangebyrank"
+	CommandZRemRangeByScore = "zremrangebyscore"
+	CommandZRevRange        = "zrevrange"
+	CommandZRevRangeByLex   = "zrevrangebylex"
+	CommandZRevRangeByScore = "zrevrangebyscore"
+	CommandZRevRank         = "zrevrank"
+	CommandZScan            = "zscan"
+	CommandZScore           = "zscore"
+	CommandZUnion           = "zunion"
+	CommandZUnionStore      = "zunionstore"
 )
 
 const (
@@ -19,15 +155,25 @@ const (
 	CommandTypeGeneral = "general"
 )
 
-var commandToTypeMap = map[string]string{
-	CommandGet:   CommandTypeStore,
-	CommandHello: CommandTypeGeneral,
-	CommandPing:  CommandTypeGeneral,
-	CommandSet:   CommandTypeStore,
+type CommandMeta struct {
+	Client *Client
+	// Protocol the reply is encoded with, the one the client spoke when the
+	// command was read. HELLO replies with the protocol it switches to.
+	Protocol int
+	// ReplyCh receives the reply once the command has run, or nil if no
+	// reply could be built. It must be buffered so the handler never blocks.
+	ReplyCh chan<- Reply
 }
 
-type CommandMeta struct {
-	Conn net.Conn
+// Reply is the reply to a command along with the protocol to encode it with.
+type Reply struct {
+	Value    rtypes.RespDataType
+	Protocol int
+}
+
+// SendReply sends the reply of the command to its connection.
+func (m CommandMeta) SendReply(value rtypes.RespDataType) {
+	m.ReplyCh <- Reply{Value: value, Protocol: m.Protocol}
 }
 
 type Command struct {
@@ -35,14 +181,3 @@ type Command struct {
 	Arguments []rtypes.RespDataType
 	Metadata  CommandMeta
 }
-
-func (c *Command) GetType() (string, error) {
-	if c == nil {
-		return "", fmt.Errorf("c *Command is nil")
-	}
-	cmdType, ok := commandToTypeMap[c.Name]
-	if !ok {
-		return "", fmt.Errorf("command %q does not have a type", c.Name)
-	}
-	return cmdType, nil
-}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+// Config holds the server settings. Field comments name the equivalent
+// redis.conf directive.
+type Config struct {
+	// Maximum size of a single string argument of a request (proto-max-bulk-len)
+	ProtoMaxBulkLen int
+	// Maximum number of arguments of a multibulk request
+	ProtoMaxMultibulkLen int
+	// Maximum size of an inline request, which also bounds the length
+	// headers of a multibulk request
+	ProtoInlineMaxSize int
+	// Size of the pending replies of a client over which it is disconnected
+	// right away (client-output-buffer-limit normal <hard>). 0 means no limit.
+	ClientOutputBufferHardLimit int
+	// Size of the pending replies of a client over which it is disconnected
+	// if it stays over it for ClientOutputBufferSoftPeriod
+	// (client-output-buffer-limit normal <soft> <soft seconds>). 0 means no
+	// limit.
+	ClientOutputBufferSoftLimit  int
+	ClientOutputBufferSoftPeriod time.Duration
+	// Number of fields over which a hash is converted from a listpack to a
+	// hashtable (hash-max-listpack-entries)
+	HashMaxListpackEntries int
+	// Length of a field or a value over which a hash is converted from a
+	// listpack to a hashtable (hash-max-listpack-value)
+	HashMaxListpackValue int
+	// Number of members over which a set of integers is converted from an
+	// intset (set-max-intset-entries)
+	SetMaxIntsetEntries int
+	// Number of members over which a set is converted from a listpack to a
+	// hashtable (set-max-listpack-entries)
+	SetMaxListpackEntries int
+	// Length of a member over which a set is converted from a listpack to a
+	// hashtable (set-max-listpack-value)
+	SetMaxListpackValue int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ProtoMaxBulkLen:      512 * 1024 * 1024,
+		ProtoMaxMultibulkLen: math.MaxInt32,
+		ProtoInlineMaxSize:   64 * 1024,
+		// Like Redis, normal clients have no output buffer limits by default
+		ClientOutputBufferHardLimit:  0,
+		ClientOutputBufferSoftLimit:  0,
+		ClientOutputBufferSoftPeriod: 0,
+		HashMaxListpackEntries:       128,
+		HashMaxListpackValue:         64,
+		SetMaxIntsetEntries:          512,
+		SetMaxListpackEntries:        128,
+		SetMaxListpackValue:          64,
+	}
+}
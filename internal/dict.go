@@ -0,0 +1,327 @@
+package internal
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand/v2"
+)
+
+const (
+	// Number of buckets of an empty dict, like DICT_HT_INITIAL_SIZE in Redis
+	dictInitialSize = 4
+	// A dict shrinks once fewer than 1 in dictMinFill of its buckets would
+	// be used
+	dictMinFill = 8
+	// Empty buckets a single rehash step may skip before giving up, so that
+	// a step takes bounded time
+	dictRehashEmptyVisits = 10
+)
+
+// dict is a hash table with chaining that resizes incrementally like the
+// dict of Redis: while it is rehashing, every access moves one bucket from
+// the old table to the new one. Unlike a Go map, its layout is known, which
+// is what lets scan walk it with a cursor that stays valid across resizes.
+type dict[V any] struct {
+	// tables[1] is only used while rehashing from tables[0] into it
+	tables [2]dictTable[V]
+	// Next bucket of tables[0] to move to tables[1], or -1 when not rehashing
+	rehashIdx int
+	seed      maphash.Seed
+}
+
+// dictTable has a power of two number of buckets, so the bucket of a hash
+// is given by its low bits.
+type dictTable[V any] struct {
+	buckets []*dictEntry[V]
+	used    int
+}
+
+type dictEntry[V any] struct {
+	key   string
+	value V
+	next  *dictEntry[V]
+}
+
+func newDict[V any]() *dict[V] {
+	d := &dict[V]{rehashIdx: -1, seed: maphash.MakeSeed()}
+	d.tables[0].buckets = make([]*dictEntry[V], dictInitialSize)
+	return d
+}
+
+func (t *dictTable[V]) mask() uint64 {
+	return uint64(len(t.buckets) - 1)
+}
+
+func (d *dict[V]) len() int {
+	return d.tables[0].used + d.tables[1].used
+}
+
+func (d *dict[V]) isRehashing() bool {
+	return d.rehashIdx != -1
+}
+
+func (d *dict[V]) hash(key string) uint64 {
+	return maphash.String(d.seed, key)
+}
+
+func (d *dict[V]) get(key string) (V, bool) {
+	if entry := d.find(key); entry != nil {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// set stores the value against the key. Returns true if the key was added
+// rather than updated.
+func (d *dict[V]) set(key string, value V) bool {
+	if entry := d.find(key); entry != nil {
+		entry.value = value
+		return false
+	}
+	d.expandIfNeeded()
+	// New keys go to the new table while rehashing, so that the old one only
+	// ever empties
+	table := &d.tables[0]
+	if d.isRehashing() {
+		table = &d.tables[1]
+	}
+	idx := d.hash(key) & table.mask()
+	table.buckets[idx] = &dictEntry[V]{key: key, value: value, next: table.buckets[idx]}
+	table.used++
+	return true
+}
+
+// delete removes the key and returns its value. The second return value is
+// false if the key did not exist.
+func (d *dict[V]) delete(key string) (V, bool) {
+	if d.isRehashing() {
+		d.rehash(1)
+	}
+	h := d.hash(key)
+	for i := range d.tables {
+		table := &d.tables[i]
+		if len(table.buckets) == 0 {
+			break
+		}
+		idx := h & table.mask()
+		var prev *dictEntry[V]
+		for entry := table.buckets[idx]; entry != nil; prev, entry = entry, entry.next {
+			if entry.key != key {
+				continue
+			}
+			if prev == nil {
+				table.buckets[idx] = entry.next
+			} else {
+				prev.next = entry.next
+			}
+			table.used--
+			d.shrinkIfNeeded()
+			return entry.value, true
+		}
+		if !d.isRehashing() {
+			break
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func (d *dict[V]) find(key string) *dictEntry[V] {
+	if d.isRehashing() {
+		d.rehash(1)
+	}
+	h := d.hash(key)
+	for i := range d.tables {
+		table := &d.tables[i]
+		if len(table.buckets) == 0 {
+			break
+		}
+		for entry := table.buckets[h&table.mask()]; entry != nil; entry = entry.next {
+			if entry.key == key {
+				return entry
+			}
+		}
+		if !d.isRehashing() {
+			break
+		}
+	}
+	return nil
+}
+
+// forEach calls fn for every entry. fn must not modify the dict.
+func (d *dict[V]) forEach(fn func(key string, value V)) {
+	for i := range d.tables {
+		for _, entry := range d.tables[i].buckets {
+			for ; entry != nil; entry = entry.next {
+				fn(entry.key, entry.value)
+			}
+		}
+	}
+}
+
+// random returns an entry picked at random. The second return value is false
+// if the dict is empty. Like dictGetRandomKey in Redis, it picks a random
+// bucket that is not empty and then a random entry of its chain, so entries
+// sharing their bucket with others are less likely to be picked.
+func (d *dict[V]) random() (string, V, bool) {
+	if d.len() == 0 {
+		var zero V
+		return "", zero, false
+	}
+	if d.isRehashing() {
+		d.rehash(1)
+	}
+	var bucket *dictEntry[V]
+	for bucket == nil {
+		if !d.isRehashing() {
+			bucket = d.tables[0].buckets[rand.IntN(len(d.tables[0].buckets))]
+			continue
+		}
+		// The buckets of the old table before rehashIdx are all empty
+		from, to := &d.tables[0], &d.tables[1]
+		idx := d.rehashIdx + rand.IntN(len(from.buckets)-d.rehashIdx+len(to.buckets))
+		if idx < len(from.buckets) {
+			bucket = from.buckets[idx]
+		} else {
+			bucket = to.buckets[idx-len(from.buckets)]
+		}
+	}
+	chainLength := 0
+	for entry := bucket; entry != nil; entry = entry.next {
+		chainLength++
+	}
+	entry := bucket
+	for range rand.IntN(chainLength) {
+		entry = entry.next
+	}
+	return entry.key, entry.value, true
+}
+
+// release unlinks every entry and empties the dict, so that the garbage
+// collector has no chains left to trace. The dict must not be used after.
+func (d *dict[V]) release() {
+	for i := range d.tables {
+		for _, entry := range d.tables[i].buckets {
+			for entry != nil {
+				next := entry.next
+				*entry = dictEntry[V]{}
+				entry = next
+			}
+		}
+		d.tables[i] = dictTable[V]{}
+	}
+}
+
+// scan calls fn for the entries of the bucket at the cursor and returns the
+// cursor of the next bucket, or 0 once every bucket was visited. fn must
+// not modify the dict.
+//
+// Like dictScan in Redis, the cursor is incremented on its reversed bits, so
+// that it walks the buckets in an order where growing or shrinking the table
+// between two calls never skips a bucket that was not visited yet: every
+// key present during the whole scan is returned at least once, although
+// some keys may be returned more than once.
+func (d *dict[V]) scan(cursor uint64, fn func(key string, value V)) uint64 {
+	emit := func(entry *dictEntry[V]) {
+		for ; entry != nil; entry = entry.next {
+			fn(entry.key, entry.value)
+		}
+	}
+
+	small, large := &d.tables[0], &d.tables[1]
+	if !d.isRehashing() {
+		emit(small.buckets[cursor&small.mask()])
+		return nextScanCursor(cursor, small.mask())
+	}
+
+	if len(small.buckets) > len(large.buckets) {
+		small, large = large, small
+	}
+	smallMask, largeMask := small.mask(), large.mask()
+	emit(small.buckets[cursor&smallMask])
+	// Also visit the buckets of the larger table that the bucket of the
+	// smaller one expands to
+	for {
+		emit(large.buckets[cursor&largeMask])
+		cursor = nextScanCursor(cursor, largeMask)
+		// Done once the bits only covered by the larger mask wrap around,
+		// which carries into the bits of the smaller one
+		if cursor&(smallMask^largeMask) == 0 {
+			return cursor
+		}
+	}
+}
+
+// nextScanCursor increments the bits of the cursor covered by the mask, in
+// reverse order.
+func nextScanCursor(cursor uint64, mask uint64) uint64 {
+	cursor |= ^mask
+	cursor = bits.Reverse64(cursor)
+	cursor++
+	return bits.Reverse64(cursor)
+}
+
+func (d *dict[V]) expandIfNeeded() {
+	if d.isRehashing() {
+		return
+	}
+	if d.tables[0].used >= len(d.tables[0].buckets) {
+		d.resize(d.tables[0].used + 1)
+	}
+}
+
+func (d *dict[V]) shrinkIfNeeded() {
+	if d.isRehashing() {
+		return
+	}
+	size := len(d.tables[0].buckets)
+	if size > dictInitialSize && d.tables[0].used*dictMinFill <= size {
+		d.resize(d.tables[0].used)
+	}
+}
+
+// resize starts rehashing into a table with enough buckets for size keys.
+func (d *dict[V]) resize(size int) {
+	buckets := dictInitialSize
+	for buckets < size {
+		buckets *= 2
+	}
+	if buckets == len(d.tables[0].buckets) {
+		return
+	}
+	d.tables[1] = dictTable[V]{buckets: make([]*dictEntry[V], buckets)}
+	d.rehashIdx = 0
+}
+
+// rehash moves up to n buckets from the old table to the new one.
+func (d *dict[V]) rehash(n int) {
+	from, to := &d.tables[0], &d.tables[1]
+	emptyVisits := n * dictRehashEmptyVisits
+	for ; n > 0 && from.used > 0; n-- {
+		for from.buckets[d.rehashIdx] == nil {
+			d.rehashIdx++
+			emptyVisits--
+			if emptyVisits == 0 {
+				return
+			}
+		}
+		entry := from.buckets[d.rehashIdx]
+		for entry != nil {
+			next := entry.next
+			idx := d.hash(entry.key) & to.mask()
+			entry.next = to.buckets[idx]
+			to.buckets[idx] = entry
+			from.used--
+			to.used++
+			entry = next
+		}
+		from.buckets[d.rehashIdx] = nil
+		d.rehashIdx++
+	}
+	if from.used == 0 {
+		d.tables[0] = d.tables[1]
+		d.tables[1] = dictTable[V]{}
+		d.rehashIdx = -1
+	}
+}
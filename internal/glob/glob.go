@@ -0,0 +1,115 @@
+// Package glob implements the glob-style patterns of KEYS, SCAN and the
+// other commands that take a MATCH pattern.
+package glob
+
+// Patterns can't nest stars deeper than this, which bounds the recursion of
+// abusive patterns like Redis does
+const maxNesting = 1000
+
+// Match reports whether str matches the pattern, following the rules of
+// stringmatchlen in Redis:
+//
+//	h?llo    ? matches a single byte
+//	h*llo    * matches any sequence of bytes, including an empty one
+//	h[ae]llo matches one of the bytes in the brackets
+//	h[a-e]o  matches a byte in the range, which can be given in either order
+//	h[^e]llo matches a byte that is not in the brackets
+//	h\*llo   a backslash matches the next byte literally, also in brackets
+//
+// An unterminated bracket matches like it was closed at the end of the
+// pattern.
+func Match(pattern string, str string) bool {
+	skipLongerMatches := false
+	return match(pattern, str, &skipLongerMatches, 0)
+}
+
+func match(pattern string, str string, skipLongerMatches *bool, nesting int) bool {
+	if nesting > maxNesting {
+		return false
+	}
+	p, s := 0, 0
+	for p < len(pattern) && s < len(str) {
+		switch pattern[p] {
+		case '*':
+			for p+1 < len(pattern) && pattern[p+1] == '*' {
+				p++
+			}
+			if p+1 == len(pattern) {
+				return true
+			}
+			for ; s < len(str); s++ {
+				if match(pattern[p+1:], str[s:], skipLongerMatches, nesting+1) {
+					return true
+				}
+				if *skipLongerMatches {
+					return false
+				}
+			}
+			// The rest of the pattern matches nowhere in the rest of the
+			// string, so an earlier star matching more bytes can't help
+			// either.
+			*skipLongerMatches = true
+			return false
+
+		case '?':
+			s++
+
+		case '[':
+			p++
+			negate := p < len(pattern) && pattern[p] == '^'
+			if negate {
+				p++
+			}
+			matched := false
+			for {
+				if p >= len(pattern) {
+					// Unterminated, stay on the last byte of the pattern
+					p--
+					break
+				}
+				if pattern[p] == '\\' && len(pattern)-p >= 2 {
+					p++
+					if pattern[p] == str[s] {
+						matched = true
+					}
+				} else if pattern[p] == ']' {
+					break
+				} else if len(pattern)-p >= 3 && pattern[p+1] == '-' {
+					start, end := pattern[p], pattern[p+2]
+					if start > end {
+						start, end = end, start
+					}
+					if str[s] >= start && str[s] <= end {
+						matched = true
+					}
+					p += 2
+				} else if pattern[p] == str[s] {
+					matched = true
+				}
+				p++
+			}
+			if matched == negate {
+				return false
+			}
+			s++
+
+		case '\\':
+			if len(pattern)-p >= 2 {
+				p++
+			}
+			fallthrough
+		default:
+			if pattern[p] != str[s] {
+				return false
+			}
+			s++
+		}
+		p++
+		if s == len(str) {
+			for p < len(pattern) && pattern[p] == '*' {
+				p++
+			}
+		}
+	}
+	return p == len(pattern) && s == len(str)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// How many tasks can wait to run on the store goroutine before the
+// goroutines posting them wait
+const blockedTasksQueueSize = 64
+
+// errNoReply is returned in place of the reply of a command that replies
+// later, such as a blocked command.
+var errNoReply = errors.New("the command replies later")
+
+// blockRequest is returned in place of an error by the handler of a blocking
+// command that none of its keys can serve yet. The command then waits until
+// one of the keys holds a value of the given type, and gets its reply from
+// serve.
+type blockRequest struct {
+	keys      []string
+	valueType internal.ValueType
+	timeout   time.Duration // 0 to wait forever
+	// Serves the command from the key. Returns a nil reply if the key has
+	// nothing to serve it with.
+	serve func(key string) (rtypes.RespDataType, error)
+}
+
+func (*blockRequest) Error() string {
+	return "the command is blocked"
+}
+
+// serveOrBlock serves a blocking command from the first of its keys that
+// can, or asks for the command to be blocked on all of them.
+func serveOrBlock(keys []string, valueType internal.ValueType, timeout time.Duration,
+	serve func(key string) (rtypes.RespDataType, error)) (rtypes.RespDataType, error) {
+	for _, key := range keys {
+		reply, err := serve(key)
+		if err != nil || reply != nil {
+			return reply, err
+		}
+	}
+	return nil, &blockRequest{keys: keys, valueType: valueType, timeout: timeout, serve: serve}
+}
+
+// BlockedClients keeps the commands that are blocked on keys, and serves
+// them in the order they blocked once the keys are modified. Other than
+// Disconnect, its methods only run on the store goroutine.
+type BlockedClients struct {
+	byKey    map[string][]*blockedCommand
+	byClient map[*internal.Client][]*blockedCommand
+	tasks    chan func()
+	stopCh   <-chan struct{}
+}
+
+type blockedCommand struct {
+	cmd     *internal.Command
+	req     *blockRequest
+	timer   *time.Timer // nil if the command waits forever
+	removed bool
+}
+
+func NewBlockedClients(stopCh <-chan struct{}) *BlockedClients {
+	return &BlockedClients{
+		byKey:    make(map[string][]*blockedCommand),
+		byClient: make(map[*internal.Client][]*blockedCommand),
+		tasks:    make(chan func(), blockedTasksQueueSize),
+		stopCh:   stopCh,
+	}
+}
+
+// Tasks returns the work that has to run on the store goroutine, like
+// replying to the commands that timed out.
+func (b *BlockedClients) Tasks() <-chan func() {
+	return b.tasks
+}
+
+// Disconnect drops the blocked commands of a client that went away. The
+// client must be marked closed first, so that none of its commands still
+// queued can block afterwards.
+func (b *BlockedClients) Disconnect(client *internal.Client) {
+	b.post(func() {
+		for _, bc := range slices.Clone(b.byClient[client]) {
+			b.remove(bc)
+			// Nobody reads the reply, but the connection waits for every
+			// reply slot to be filled before closing
+			bc.cmd.Metadata.SendReply(nil)
+		}
+	})
+}
+
+func (b *BlockedClients) post(task func()) {
+	select {
+	case b.tasks <- task:
+	case <-b.stopCh:
+	}
+}
+
+func (b *BlockedClients) block(cmd *internal.Command, req *blockRequest) {
+	client := cmd.Metadata.Client
+	if client.Closed() {
+		cmd.Metadata.SendReply(nil)
+		return
+	}
+	bc := &blockedCommand{cmd: cmd, req: req}
+	for _, key := range req.keys {
+		b.byKey[key] = append(b.byKey[key], bc)
+	}
+	b.byClient[client] = append(b.byClient[client], bc)
+	if req.timeout > 0 {
+		bc.timer = time.AfterFunc(req.timeout, func() {
+			b.post(func() {
+				if !bc.removed {
+					b.remove(bc)
+					bc.cmd.Metadata.SendReply(&rtypes.Null{Array: true})
+				}
+			})
+		})
+	}
+}
+
+func (b *BlockedClients) remove(bc *blockedCommand) {
+	bc.removed = true
+	if bc.timer != nil {
+		bc.timer.Stop()
+	}
+	for _, key := range bc.req.keys {
+		if waiting := removeBlocked(b.byKey[key], bc); len(waiting) > 0 {
+			b.byKey[key] = waiting
+		} else {
+			delete(b.byKey, key)
+		}
+	}
+	client := bc.cmd.Metadata.Client
+	if waiting := removeBlocked(b.byClient[client], bc); len(waiting) > 0 {
+		b.byClient[client] = waiting
+	} else {
+		delete(b.byClient, client)
+	}
+}
+
+func removeBlocked(waiting []*blockedCommand, bc *blockedCommand) []*blockedCommand {
+	return slices.DeleteFunc(waiting, func(other *blockedCommand) bool { return other == bc })
+}
+
+// afterCommand serves the commands blocked on the keys of a command that
+// just ran, which may have given the keys something to serve them with.
+func (b *BlockedClients) afterCommand(store *internal.Store, cmd *internal.Command) {
+	if len(b.byKey) == 0 {
+		return
+	}
+	keys := commandKeys(cmd)
+	for len(keys) > 0 {
+		key := keys[0]
+		keys = keys[1:]
+		for i := 0; i < len(b.byKey[key]); {
+			bc := b.byKey[key][i]
+			valueType, ok := store.Type(key)
+			if !ok {
+				break
+			}
+			// Commands waiting for another type stay blocked
+			if valueType != bc.req.valueType {
+				i++
+				continue
+			}
+			reply, err := bc.req.serve(key)
+			if err == nil && reply == nil {
+				break
+			}
+			if err != nil {
+				reply = rtypes.NewSimpleError(err.Error())
+			}
+			b.remove(bc)
+			bc.cmd.Metadata.SendReply(reply)
+			// Serving the command may have given more keys something to
+			// serve with, like the destination of BLMOVE
+			keys = append(keys, commandKeys(bc.cmd)...)
+		}
+	}
+}
+
+// commandKeys returns the keys among the arguments of the command.
+func commandKeys(cmd *internal.Command) []string {
+	spec, ok := LookupCommand(cmd.Name)
+	if !ok {
+		return nil
+	}
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil
+	}
+	args = append([]string{cmd.Name}, args...)
+	var keys []string
+	for _, position := range spec.keyPositions(args) {
+		keys = append(keys, args[position])
+	}
+	return keys
+}
+
+// parseTimeout parses the timeout of a blocking command, in seconds with a
+// fractional part, rounded up to milliseconds like Redis does.
+func parseTimeout(str string) (time.Duration, *rtypes.SimpleError) {
+	seconds, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(seconds) {
+		return 0, rtypes.NewSimpleError("ERR timeout is not a float or out of range")
+	}
+	if seconds < 0 {
+		return 0, rtypes.NewSimpleError("ERR timeout is negative")
+	}
+	millis := math.Ceil(seconds * 1000)
+	if millis > math.MaxInt64 {
+		return 0, rtypes.NewSimpleError("ERR timeout is out of range")
+	}
+	// Longer than a Duration can hold is as good as forever
+	if millis > float64(math.MaxInt64/int64(time.Millisecond)) {
+		return 0, nil
+	}
+	return time.Duration(millis) * time.Millisecond, nil
+}
+
+// keysAfterNumKeys locates the keys of the commands that take the number of
+// keys at the given position, followed by the keys, for COMMAND GETKEYS.
+func keysAfterNumKeys(numKeysIndex int) func(args []string) []int {
+	return func(args []string) []int {
+		if len(args) <= numKeysIndex {
+			return nil
+		}
+		numKeys, ok := parseInteger(args[numKeysIndex])
+		if !ok || numKeys <= 0 || numKeys > int64(len(args)-numKeysIndex-1) {
+			return nil
+		}
+		positions := make([]int, numKeys)
+		for i := range positions {
+			positions[i] = numKeysIndex + 1 + i
+		}
+		return positions
+	}
+}
+
+// destinationAndKeysAfterNumKeys locates the keys of the STORE commands that
+// take the destination key before the number of keys, like ZUNIONSTORE.
+func destinationAndKeysAfterNumKeys(args []string) []int {
+	positions := keysAfterNumKeys(2)(args)
+	if positions == nil {
+		return nil
+	}
+	return append([]int{1}, positions...)
+}
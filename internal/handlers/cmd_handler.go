@@ -1,31 +1,153 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/ram-the-coder/redisgo/internal"
-	"github.com/ram-the-coder/redisgo/internal/resp"
 	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
 	"github.com/rs/zerolog/log"
 )
 
+// Errors shared by several commands, worded like Redis
+const (
+	errSyntax       = "ERR syntax error"
+	errNotAnInteger = "ERR value is not an integer or out of range"
+	errNoSuchKey    = "ERR no such key"
+)
+
+// Cron is a job run periodically on the goroutine handling the commands, so
+// it can safely touch the same state as the command handlers.
+type Cron struct {
+	Interval time.Duration
+	Run      func()
+}
+
 func HandleCommands(
 		commandCh <-chan *internal.Command,
 		stopCh <-chan struct{},
-		getResponse func(*internal.Command) (rtypes.RespDataType, error)) {
+		getResponse func(*internal.Command) (rtypes.RespDataType, error),
+		cron *Cron,
+		tasks <-chan func()) {
+	var cronTick <-chan time.Time // nil channel never fires when there is no cron
+	if cron != nil {
+		ticker := time.NewTicker(cron.Interval)
+		defer ticker.Stop()
+		cronTick = ticker.C
+	}
 	for {
 		select {
 		case <-stopCh:
 			return
+		case <-cronTick:
+			cron.Run()
+		case task := <-tasks: // nil channel never fires when there are no tasks
+			task()
 		case command := <-commandCh:
 			log.Trace().Msgf("Handling command: %s. Args: %s", command.Name, command.Arguments)
 			response, err := getResponse(command)
-			if err != nil {
-				log.Err(err).Msgf("failed to build response for command %q", command.Name)
+			if errors.Is(err, errNoReply) {
 				continue
 			}
-			if err := resp.WriteResponse(response, command.Metadata.Conn); err != nil {
-				log.Err(err).Msgf("failed to write response for command %q", command.Name)
-				continue
+			if err != nil {
+				log.Err(err).Msgf("failed to build response for command %q", command.Name)
 			}
+			// The connection writes the replies in the order of its commands
+			command.Metadata.SendReply(response)
 		}
 	}
 }
+
+// execute validates the command against the command table and runs its handler.
+func execute(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	spec, errReply := validate(cmd)
+	if errReply != nil {
+		return errReply, nil
+	}
+	return run(store, spec, cmd)
+}
+
+// validate looks the command up in the command table and checks its number
+// of arguments. Returns the error to reply with if the command can't run.
+func validate(cmd *internal.Command) (*CommandSpec, *rtypes.SimpleError) {
+	spec, ok := LookupCommand(cmd.Name)
+	if !ok {
+		return nil, unknownCommand(cmd)
+	}
+	if !spec.acceptsArgCount(len(cmd.Arguments) + 1) {
+		return nil, wrongNumberOfArguments(cmd)
+	}
+	return spec, nil
+}
+
+// run runs the handler of a command that passed validate.
+func run(store *internal.Store, spec *CommandSpec, cmd *internal.Command) (rtypes.RespDataType, error) {
+	response, err := spec.Handler(store, cmd)
+	if errors.Is(err, internal.ErrWrongType) {
+		return rtypes.NewSimpleError(err.Error()), nil
+	}
+	return response, err
+}
+
+func getString(rdt rtypes.RespDataType) (string, error) {
+	if key, ok := rdt.(*rtypes.BulkString); ok {
+		return string(key.Value), nil
+	} else if key, ok := rdt.(*rtypes.SimpleString); ok {
+		return string(key.Value), nil
+	} else {
+		return "", fmt.Errorf("failed to get string fromL %s", rdt)
+	}
+}
+
+// getBytes returns the content of a bulk string argument without copying it,
+// for the values that end up in the store.
+func getBytes(rdt rtypes.RespDataType) ([]byte, error) {
+	if value, ok := rdt.(*rtypes.BulkString); ok {
+		return value.Value, nil
+	}
+	return nil, fmt.Errorf("failed to get bytes from %s", rdt)
+}
+
+func getStrings(rdts []rtypes.RespDataType) ([]string, error) {
+	strs := make([]string, len(rdts))
+	for i, rdt := range rdts {
+		str, err := getString(rdt)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+// unknownCommand builds the same error Redis replies with, quoting the
+// leading arguments up to a total of 128 bytes.
+func unknownCommand(cmd *internal.Command) *rtypes.SimpleError {
+	var args strings.Builder
+	for _, arg := range cmd.Arguments {
+		if args.Len() >= 128 {
+			break
+		}
+		argStr, err := getString(arg)
+		if err != nil {
+			continue
+		}
+		if remaining := 128 - args.Len(); len(argStr) > remaining {
+			argStr = argStr[:remaining]
+		}
+		fmt.Fprintf(&args, "'%s' ", argStr)
+	}
+	return rtypes.NewSimpleError(sanitizeErrorMessage(fmt.Sprintf(
+		"ERR unknown command '%.128s', with args beginning with: %s", cmd.Name, args.String())))
+}
+
+// sanitizeErrorMessage replaces newlines, which cannot appear in a simple error.
+func sanitizeErrorMessage(msg string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(msg)
+}
+
+func wrongNumberOfArguments(cmd *internal.Command) *rtypes.SimpleError {
+	return rtypes.NewSimpleError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", cmd.Name))
+}
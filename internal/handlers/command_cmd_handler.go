@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// COMMAND [COUNT | INFO [command-name ...] | DOCS [command-name ...] | GETKEYS command [arg ...]]
+func handleCommand(_ *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	if len(cmd.Arguments) == 0 {
+		entries := []rtypes.RespDataType{}
+		for _, spec := range sortedCommandSpecs() {
+			entries = append(entries, commandInfo(spec))
+		}
+		return &rtypes.Array{Elements: entries}, nil
+	}
+
+	subcommand, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subcommand")
+	}
+	names, err := getStrings(cmd.Arguments[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	switch strings.ToLower(subcommand) {
+	case "count":
+		if len(names) != 0 {
+			return wrongNumberOfSubcommandArguments(cmd, "count"), nil
+		}
+		return &rtypes.Int{Value: len(commandTable)}, nil
+
+	case "info":
+		if len(names) == 0 {
+			return handleCommand(nil, &internal.Command{Name: cmd.Name})
+		}
+		entries := make([]rtypes.RespDataType, len(names))
+		for i, name := range names {
+			if spec, ok := LookupCommand(strings.ToLower(name)); ok {
+				entries[i] = commandInfo(spec)
+			} else {
+				entries[i] = &rtypes.Null{}
+			}
+		}
+		return &rtypes.Array{Elements: entries}, nil
+
+	case "docs":
+		specs := sortedCommandSpecs()
+		if len(names) > 0 {
+			specs = nil
+			for _, name := range names {
+				if spec, ok := LookupCommand(strings.ToLower(name)); ok {
+					specs = append(specs, spec)
+				}
+			}
+		}
+		kvPairs := make([][2]rtypes.RespDataType, len(specs))
+		for i, spec := range specs {
+			kvPairs[i] = [2]rtypes.RespDataType{rtypes.NewBulkString(spec.Name), commandDocs(spec)}
+		}
+		return &rtypes.Map{KvPairs: kvPairs}, nil
+
+	case "getkeys":
+		if len(names) == 0 {
+			return wrongNumberOfSubcommandArguments(cmd, "getkeys"), nil
+		}
+		spec, ok := LookupCommand(strings.ToLower(names[0]))
+		if !ok {
+			return rtypes.NewSimpleError("ERR Invalid command specified"), nil
+		}
+		if !spec.acceptsArgCount(len(names)) {
+			return rtypes.NewSimpleError("ERR Invalid number of arguments specified for command"), nil
+		}
+		positions := spec.keyPositions(names)
+		if len(positions) == 0 {
+			return rtypes.NewSimpleError("ERR The command has no key arguments"), nil
+		}
+		keys := make([]rtypes.RespDataType, len(positions))
+		for i, position := range positions {
+			keys[i] = rtypes.NewBulkString(names[position])
+		}
+		return &rtypes.Array{Elements: keys}, nil
+
+	default:
+		return rtypes.NewSimpleError(sanitizeErrorMessage(fmt.Sprintf(
+			"ERR unknown subcommand '%.128s'. Try COMMAND HELP.", subcommand))), nil
+	}
+}
+
+// commandInfo builds the entry of a command in the reply of COMMAND and
+// COMMAND INFO, in the 10 element format used since Redis 7.
+func commandInfo(spec *CommandSpec) rtypes.RespDataType {
+	return &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString(spec.Name),
+		&rtypes.Int{Value: spec.Arity},
+		&rtypes.Set{Elements: simpleStrings(spec.flagNames())},
+		&rtypes.Int{Value: spec.FirstKey},
+		&rtypes.Int{Value: spec.LastKey},
+		&rtypes.Int{Value: spec.KeyStep},
+		&rtypes.Set{Elements: simpleStrings(spec.aclCategories())},
+		&rtypes.Array{Elements: []rtypes.RespDataType{}}, // tips
+		keySpecs(spec),
+		&rtypes.Array{Elements: []rtypes.RespDataType{}}, // subcommands
+	}}
+}
+
+// keySpecs describes the key positions of the command as a single key spec
+// that starts at FirstKey and spans a range up to LastKey.
+func keySpecs(spec *CommandSpec) rtypes.RespDataType {
+	if spec.FirstKey <= 0 {
+		return &rtypes.Array{Elements: []rtypes.RespDataType{}}
+	}
+	flags := []string{"RO", "ACCESS"}
+	if spec.Flags&FlagWrite != 0 {
+		flags = []string{"RW", "UPDATE"}
+	}
+	// In a key spec, a non-negative last key is relative to the first key
+	lastKey := spec.LastKey
+	if lastKey >= 0 {
+		lastKey -= spec.FirstKey
+	}
+	keySpec := &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+		{rtypes.NewBulkString("flags"), &rtypes.Set{Elements: simpleStrings(flags)}},
+		{rtypes.NewBulkString("begin_search"), &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+			{rtypes.NewBulkString("type"), rtypes.NewBulkString("index")},
+			{rtypes.NewBulkString("spec"), &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+				{rtypes.NewBulkString("index"), &rtypes.Int{Value: spec.FirstKey}},
+			}}},
+		}}},
+		{rtypes.NewBulkString("find_keys"), &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+			{rtypes.NewBulkString("type"), rtypes.NewBulkString("range")},
+			{rtypes.NewBulkString("spec"), &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+				{rtypes.NewBulkString("lastkey"), &rtypes.Int{Value: lastKey}},
+				{rtypes.NewBulkString("keystep"), &rtypes.Int{Value: spec.KeyStep}},
+				{rtypes.NewBulkString("limit"), &rtypes.Int{Value: 0}},
+			}}},
+		}}},
+	}}
+	return &rtypes.Array{Elements: []rtypes.RespDataType{keySpec}}
+}
+
+func commandDocs(spec *CommandSpec) rtypes.RespDataType {
+	return &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+		{rtypes.NewBulkString("summary"), rtypes.NewBulkString(spec.Summary)},
+		{rtypes.NewBulkString("since"), rtypes.NewBulkString(spec.Since)},
+		{rtypes.NewBulkString("group"), rtypes.NewBulkString(spec.Group)},
+	}}
+}
+
+func simpleStrings(strs []string) []rtypes.RespDataType {
+	elements := make([]rtypes.RespDataType, len(strs))
+	for i, str := range strs {
+		elements[i] = rtypes.NewSimpleString(str)
+	}
+	return elements
+}
+
+func wrongNumberOfSubcommandArguments(cmd *internal.Command, subcommand string) *rtypes.SimpleError {
+	return rtypes.NewSimpleError(fmt.Sprintf("ERR wrong number of arguments for '%s|%s' command", cmd.Name, subcommand))
+}
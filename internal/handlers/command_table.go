@@ -0,0 +1,971 @@
+package handlers
+
+import (
+	"sort"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// Handler builds the reply to a command. Commands of type
+// internal.CommandTypeGeneral do not run on the store goroutine and are
+// given a nil store.
+type Handler func(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error)
+
+type CommandFlags uint
+
+const (
+	FlagWrite CommandFlags = 1 << iota
+	FlagReadOnly
+	FlagFast
+	FlagAdmin
+	FlagPubSub
+	FlagNoScript
+	FlagBlocking
+)
+
+var commandFlagNames = []struct {
+	flag CommandFlags
+	name string
+}{
+	{FlagWrite, "write"},
+	{FlagReadOnly, "readonly"},
+	{FlagFast, "fast"},
+	{FlagAdmin, "admin"},
+	{FlagPubSub, "pubsub"},
+	{FlagNoScript, "noscript"},
+	{FlagBlocking, "blocking"},
+}
+
+// CommandSpec describes a command the same way Redis' command table does.
+type CommandSpec struct {
+	Name string
+	// Number of arguments including the command name. A negative arity -N
+	// means the command takes N or more arguments.
+	Arity int
+	Flags CommandFlags
+	// Positions of the keys in the arguments, where the command name is at
+	// position 0. A negative LastKey counts back from the last argument.
+	// All three are 0 for commands that take no keys.
+	FirstKey int
+	LastKey  int
+	KeyStep  int
+	// Locates the keys of commands whose keys can't be described by the
+	// positions above, such as those that take the number of keys as an
+	// argument. Given the arguments including the command name.
+	GetKeys func(args []string) []int
+	Type    string
+	Handler Handler
+
+	// Documentation returned by COMMAND DOCS
+	Summary string
+	Since   string
+	Group   string
+}
+
+var commandTable = map[string]*CommandSpec{}
+
+func init() {
+	specs := []*CommandSpec{
+		// Connection
+		{
+			Name: internal.CommandHello, Arity: -1, Flags: FlagFast | FlagNoScript,
+			Type: internal.CommandTypeGeneral, Handler: handleHello,
+			Summary: "Handshakes with the Redis server.", Since: "6.0.0", Group: "connection",
+		},
+		{
+			Name: internal.CommandPing, Arity: -1, Flags: FlagFast,
+			Type: internal.CommandTypeGeneral, Handler: handlePing,
+			Summary: "Returns the server's liveliness response.", Since: "1.0.0", Group: "connection",
+		},
+
+		// Server
+		{
+			Name: internal.CommandCommand, Arity: -1,
+			Type: internal.CommandTypeGeneral, Handler: handleCommand,
+			Summary: "Returns detailed information about all commands.", Since: "2.8.13", Group: "server",
+		},
+		{
+			Name: internal.CommandDbSize, Arity: 1, Flags: FlagReadOnly | FlagFast,
+			Type: internal.CommandTypeStore, Handler: handleDbSize,
+			Summary: "Returns the number of keys in the database.", Since: "1.0.0", Group: "server",
+		},
+
+		// Generic
+		{
+			Name: internal.CommandCopy, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleCopy,
+			Summary: "Copies the value of a key to a new key.", Since: "6.2.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandDel, Arity: -2, Flags: FlagWrite, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleDel,
+			Summary: "Deletes one or more keys.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandExists, Arity: -2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleExists,
+			Summary: "Determines whether one or more keys exist.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandExpire, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleExpire,
+			Summary: "Sets the expiration time of a key in seconds.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandExpireAt, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleExpire,
+			Summary: "Sets the expiration time of a key to a Unix timestamp.", Since: "1.2.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandExpireTime, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleTTL,
+			Summary: "Returns the expiration time of a key as a Unix timestamp.", Since: "7.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandKeys, Arity: 2, Flags: FlagReadOnly,
+			Type: internal.CommandTypeStore, Handler: handleKeys,
+			Summary: "Returns all key names that match a pattern.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandObject, Arity: -2, Flags: FlagReadOnly, FirstKey: 2, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleObject,
+			Summary: "A container for object introspection commands.", Since: "2.2.3", Group: "generic",
+		},
+		{
+			Name: internal.CommandPersist, Arity: 2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePersist,
+			Summary: "Removes the expiration time of a key.", Since: "2.2.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandPExpire, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleExpire,
+			Summary: "Sets the expiration time of a key in milliseconds.", Since: "2.6.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandPExpireAt, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleExpire,
+			Summary: "Sets the expiration time of a key to a Unix milliseconds timestamp.", Since: "2.6.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandPExpireTime, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleTTL,
+			Summary: "Returns the expiration time of a key as a Unix milliseconds timestamp.", Since: "7.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandPTTL, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleTTL,
+			Summary: "Returns the expiration time in milliseconds of a key.", Since: "2.6.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandRename, Arity: 3, Flags: FlagWrite, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleRename,
+			Summary: "Renames a key and overwrites the destination.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandRenameNX, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleRename,
+			Summary: "Renames a key only when the target key name doesn't exist.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandScan, Arity: -2, Flags: FlagReadOnly,
+			Type: internal.CommandTypeStore, Handler: handleScan,
+			Summary: "Iterates over the key names in the database.", Since: "2.8.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandTouch, Arity: -2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleExists,
+			Summary: "Returns the number of existing keys out of those specified after updating the time they were last accessed.",
+			Since:   "3.2.1", Group: "generic",
+		},
+		{
+			Name: internal.CommandTTL, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleTTL,
+			Summary: "Returns the expiration time in seconds of a key.", Since: "1.0.0", Group: "generic",
+		},
+
+		{
+			Name: internal.CommandType, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleType,
+			Summary: "Determines the type of value stored at a key.", Since: "1.0.0", Group: "generic",
+		},
+		{
+			Name: internal.CommandUnlink, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleDel,
+			Summary: "Asynchronously deletes one or more keys.", Since: "4.0.0", Group: "generic",
+		},
+
+		// String
+		{
+			Name: internal.CommandAppend, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleAppend,
+			Summary: "Appends a string to the value of a key. Creates the key if it doesn't exist.",
+			Since:   "2.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandDecr, Arity: 2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleIncr,
+			Summary: "Decrements the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+			Since:   "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandDecrBy, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleIncr,
+			Summary: "Decrements a number from the integer value of a key. Uses 0 as initial value if the key doesn't exist.",
+			Since:   "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandGet, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleGet,
+			Summary: "Returns the string value of a key.", Since: "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandGetDel, Arity: 2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleGetDel,
+			Summary: "Returns the string value of a key after deleting the key.", Since: "6.2.0", Group: "string",
+		},
+		{
+			Name: internal.CommandGetEx, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleGetEx,
+			Summary: "Returns the string value of a key after setting its expiration time.",
+			Since:   "6.2.0", Group: "string",
+		},
+		{
+			Name: internal.CommandGetRange, Arity: 4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleGetRange,
+			Summary: "Returns a substring of the string stored at a key.", Since: "2.4.0", Group: "string",
+		},
+		{
+			Name: internal.CommandGetSet, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleGetSet,
+			Summary: "Returns the previous string value of a key after setting it to a new value.",
+			Since:   "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandIncr, Arity: 2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleIncr,
+			Summary: "Increments the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+			Since:   "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandIncrBy, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleIncr,
+			Summary: "Increments the integer value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+			Since:   "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandIncrByFloat, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleIncrByFloat,
+			Summary: "Increment the floating point value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+			Since:   "2.6.0", Group: "string",
+		},
+		{
+			Name: internal.CommandLcs, Arity: -3, Flags: FlagReadOnly, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLcs,
+			Summary: "Finds the longest common substring.", Since: "7.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandMGet, Arity: -2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleMGet,
+			Summary: "Atomically returns the string values of one or more keys.", Since: "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandMSet, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: -1, KeyStep: 2,
+			Type: internal.CommandTypeStore, Handler: handleMSet,
+			Summary: "Atomically creates or modifies the string values of one or more keys.", Since: "1.0.1", Group: "string",
+		},
+		{
+			Name: internal.CommandMSetNX, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: -1, KeyStep: 2,
+			Type: internal.CommandTypeStore, Handler: handleMSet,
+			Summary: "Atomically modifies the string values of one or more keys only when all keys don't exist.",
+			Since:   "1.0.1", Group: "string",
+		},
+		{
+			Name: internal.CommandPSetEx, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetEx,
+			Summary: "Sets both string value and expiration time in milliseconds of a key. The key is created if it doesn't exist.",
+			Since:   "2.6.0", Group: "string",
+		},
+		{
+			Name: internal.CommandSet, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSet,
+			Summary: "Sets the string value of a key, ignoring its type. The key is created if it doesn't exist.",
+			Since:   "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandSetEx, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetEx,
+			Summary: "Sets the string value and expiration time of a key. Creates the key if it doesn't exist.",
+			Since:   "2.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandSetNX, Arity: 3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetNX,
+			Summary: "Set the string value of a key only when the key doesn't exist.", Since: "1.0.0", Group: "string",
+		},
+		{
+			Name: internal.CommandSetRange, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetRange,
+			Summary: "Overwrites a part of a string value with another by an offset. Creates the key if it doesn't exist.",
+			Since:   "2.2.0", Group: "string",
+		},
+		{
+			Name: internal.CommandStrLen, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleStrLen,
+			Summary: "Returns the length of a string value.", Since: "2.2.0", Group: "string",
+		},
+
+		// List
+		{
+			Name: internal.CommandBLMove, Arity: 6, Flags: FlagWrite | FlagBlocking, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleBLMove,
+			Summary: "Pops an element from a list, pushes it to another list and returns it. Blocks until an element is available otherwise. Deletes the list if the last element was moved.",
+			Since:   "6.2.0", Group: "list",
+		},
+		{
+			Name: internal.CommandBLMPop, Arity: -5, Flags: FlagWrite | FlagBlocking, GetKeys: keysAfterNumKeys(2),
+			Type: internal.CommandTypeStore, Handler: handleBLMPop,
+			Summary: "Pops the first element from one of multiple lists. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+			Since:   "7.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandBLPop, Arity: -3, Flags: FlagWrite | FlagBlocking, FirstKey: 1, LastKey: -2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleBPop,
+			Summary: "Removes and returns the first element in a list. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+			Since:   "2.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandBRPop, Arity: -3, Flags: FlagWrite | FlagBlocking, FirstKey: 1, LastKey: -2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleBPop,
+			Summary: "Removes and returns the last element in a list. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+			Since:   "2.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandBRPopLPush, Arity: 4, Flags: FlagWrite | FlagBlocking, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleBLMove,
+			Summary: "Pops an element from a list, pushes it to another list and returns it. Block until an element is available otherwise. Deletes the list if the last element was popped.",
+			Since:   "2.2.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLIndex, Arity: 3, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLIndex,
+			Summary: "Returns an element from a list by its index.", Since: "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLInsert, Arity: 5, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLInsert,
+			Summary: "Inserts an element before or after another element in a list.", Since: "2.2.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLLen, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLLen,
+			Summary: "Returns the length of a list.", Since: "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLMove, Arity: 5, Flags: FlagWrite, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLMove,
+			Summary: "Returns an element after popping it from one list and pushing it to another. Deletes the list if the last element was moved.",
+			Since:   "6.2.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLMPop, Arity: -4, Flags: FlagWrite, GetKeys: keysAfterNumKeys(1),
+			Type: internal.CommandTypeStore, Handler: handleLMPop,
+			Summary: "Returns multiple elements from a list after removing them. Deletes the list if the last element was popped.",
+			Since:   "7.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLPop, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePop,
+			Summary: "Returns the first elements in a list after removing it. Deletes the list if the last element was popped.",
+			Since:   "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLPos, Arity: -3, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLPos,
+			Summary: "Returns the index of matching elements in a list.", Since: "6.0.6", Group: "list",
+		},
+		{
+			Name: internal.CommandLPush, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePush,
+			Summary: "Prepends one or more elements to a list. Creates the key if it doesn't exist.",
+			Since:   "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLPushX, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePush,
+			Summary: "Prepends one or more elements to a list only when the list exists.", Since: "2.2.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLRange, Arity: 4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLRange,
+			Summary: "Returns a range of elements from a list.", Since: "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLRem, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLRem,
+			Summary: "Removes elements from a list. Deletes the list if the last element was removed.",
+			Since:   "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLSet, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLSet,
+			Summary: "Sets the value of an element in a list by its index.", Since: "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandLTrim, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLTrim,
+			Summary: "Removes elements from both ends a list. Deletes the list if all elements were trimmed.",
+			Since:   "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandRPop, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePop,
+			Summary: "Returns and removes the last elements of a list. Deletes the list if the last element was popped.",
+			Since:   "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandRPopLPush, Arity: 3, Flags: FlagWrite, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleLMove,
+			Summary: "Returns the last element of a list after removing and pushing it to another list. Deletes the list if the last element was popped.",
+			Since:   "1.2.0", Group: "list",
+		},
+		{
+			Name: internal.CommandRPush, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePush,
+			Summary: "Appends one or more elements to a list. Creates the key if it doesn't exist.",
+			Since:   "1.0.0", Group: "list",
+		},
+		{
+			Name: internal.CommandRPushX, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handlePush,
+			Summary: "Appends an element to a list only when the list exists.", Since: "2.2.0", Group: "list",
+		},
+
+		// Hash
+		{
+			Name: internal.CommandHDel, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHDel,
+			Summary: "Deletes one or more fields and their values from a hash. Deletes the hash if no fields remain.",
+			Since:   "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHExists, Arity: 3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHExists,
+			Summary: "Determines whether a field exists in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHExpire, Arity: -6, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHExpire,
+			Summary: "Set expiry for hash field using relative time to expire (seconds)",
+			Since:   "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHExpireAt, Arity: -6, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHExpire,
+			Summary: "Set expiry for hash field using an absolute Unix timestamp (seconds)",
+			Since:   "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHExpireTime, Arity: -5, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHTTL,
+			Summary: "Returns the expiration time of a hash field as a Unix timestamp, in seconds.",
+			Since:   "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHGet, Arity: 3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHGet,
+			Summary: "Returns the value of a field in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHGetAll, Arity: 2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHGetAll,
+			Summary: "Returns all fields and values in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHGetEx, Arity: -5, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHGetEx,
+			Summary: "Get the value of one or more fields of a given hash key, and optionally set their expiration.",
+			Since:   "8.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHIncrBy, Arity: 4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHIncrBy,
+			Summary: "Increments the integer value of a field in a hash by a number. Uses 0 as initial value if the field doesn't exist.",
+			Since:   "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHIncrByFloat, Arity: 4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHIncrByFloat,
+			Summary: "Increments the floating point value of a field by a number. Uses 0 as initial value if the field doesn't exist.",
+			Since:   "2.6.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHKeys, Arity: 2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHGetAll,
+			Summary: "Returns all fields in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHLen, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHLen,
+			Summary: "Returns the number of fields in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHMGet, Arity: -3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHMGet,
+			Summary: "Returns the values of all fields in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHMSet, Arity: -4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHSet,
+			Summary: "Sets the values of multiple fields.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHPersist, Arity: -5, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHPersist,
+			Summary: "Removes the expiration time for each specified field", Since: "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHPExpire, Arity: -6, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHExpire,
+			Summary: "Set expiry for hash field using relative time to expire (milliseconds)",
+			Since:   "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHPExpireAt, Arity: -6, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHExpire,
+			Summary: "Set expiry for hash field using an absolute Unix timestamp (milliseconds)",
+			Since:   "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHPExpireTime, Arity: -5, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHTTL,
+			Summary: "Returns the expiration time of a hash field as a Unix timestamp, in msec.",
+			Since:   "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHPTTL, Arity: -5, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHTTL,
+			Summary: "Returns the TTL in milliseconds of a hash field.", Since: "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHRandField, Arity: -2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHRandField,
+			Summary: "Returns one or more random fields from a hash.", Since: "6.2.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHScan, Arity: -3, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHScan,
+			Summary: "Iterates over fields and values of a hash.", Since: "2.8.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHSet, Arity: -4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHSet,
+			Summary: "Creates or modifies the value of a field in a hash.", Since: "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHSetEx, Arity: -6, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHSetEx,
+			Summary: "Set the value of one or more fields of a given hash key, and optionally set their expiration.",
+			Since:   "8.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHSetNX, Arity: 4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHSetNX,
+			Summary: "Sets the value of a field in a hash only when the field doesn't exist.",
+			Since:   "2.0.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHStrLen, Arity: 3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHExists,
+			Summary: "Returns the length of the value of a field.", Since: "3.2.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHTTL, Arity: -5, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHTTL,
+			Summary: "Returns the TTL in seconds of a hash field.", Since: "7.4.0", Group: "hash",
+		},
+		{
+			Name: internal.CommandHVals, Arity: 2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleHGetAll,
+			Summary: "Returns all values in a hash.", Since: "2.0.0", Group: "hash",
+		},
+
+		// Set
+		{
+			Name: internal.CommandSAdd, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSAdd,
+			Summary: "Adds one or more members to a set. Creates the key if it doesn't exist.",
+			Since:   "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSCard, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSCard,
+			Summary: "Returns the number of members in a set.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSDiff, Arity: -2, Flags: FlagReadOnly, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetOperation,
+			Summary: "Returns the difference of multiple sets.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSDiffStore, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetOperationStore,
+			Summary: "Stores the difference of multiple sets in a key.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSInter, Arity: -2, Flags: FlagReadOnly, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetOperation,
+			Summary: "Returns the intersect of multiple sets.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSInterCard, Arity: -3, Flags: FlagReadOnly, GetKeys: keysAfterNumKeys(1),
+			Type: internal.CommandTypeStore, Handler: handleSInterCard,
+			Summary: "Returns the number of members of the intersect of multiple sets.", Since: "7.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSInterStore, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetOperationStore,
+			Summary: "Stores the intersect of multiple sets in a key.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSIsMember, Arity: 3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSIsMember,
+			Summary: "Determines whether a member belongs to a set.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSMembers, Arity: 2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSMembers,
+			Summary: "Returns all members of a set.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSMIsMember, Arity: -3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSIsMember,
+			Summary: "Determines whether multiple members belong to a set.", Since: "6.2.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSMove, Arity: 4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSMove,
+			Summary: "Moves a member from one set to another.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSPop, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSPop,
+			Summary: "Returns one or more random members from a set after removing them. Deletes the set if the last member was popped.",
+			Since:   "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSRandMember, Arity: -2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSRandMember,
+			Summary: "Get one or multiple random members from a set.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSRem, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSRem,
+			Summary: "Removes one or more members from a set. Deletes the set if the last member was removed.",
+			Since:   "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSScan, Arity: -3, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSScan,
+			Summary: "Iterates over members of a set.", Since: "2.8.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSUnion, Arity: -2, Flags: FlagReadOnly, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetOperation,
+			Summary: "Returns the union of multiple sets.", Since: "1.0.0", Group: "set",
+		},
+		{
+			Name: internal.CommandSUnionStore, Arity: -3, Flags: FlagWrite, FirstKey: 1, LastKey: -1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleSetOperationStore,
+			Summary: "Stores the union of multiple sets in a key.", Since: "1.0.0", Group: "set",
+		},
+
+		// Sorted set
+		{
+			Name: internal.CommandBZMPop, Arity: -5, Flags: FlagWrite | FlagBlocking, GetKeys: keysAfterNumKeys(2),
+			Type: internal.CommandTypeStore, Handler: handleBZMPop,
+			Summary: "Removes and returns a member by score from one or more sorted sets. Blocks until a member is available otherwise. Deletes the sorted set if the last element was popped.",
+			Since:   "7.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandBZPopMax, Arity: -3, Flags: FlagWrite | FlagFast | FlagBlocking, FirstKey: 1, LastKey: -2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleBZPop,
+			Summary: "Removes and returns the member with the highest score from one or more sorted sets. Blocks until a member is available otherwise. Deletes the sorted set if the last element was popped.",
+			Since:   "5.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandBZPopMin, Arity: -3, Flags: FlagWrite | FlagFast | FlagBlocking, FirstKey: 1, LastKey: -2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleBZPop,
+			Summary: "Removes and returns the member with the lowest score from one or more sorted sets. Blocks until a member is available otherwise. Deletes the sorted set if the last element was popped.",
+			Since:   "5.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZAdd, Arity: -4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZAdd,
+			Summary: "Adds one or more members to a sorted set, or updates their scores. Creates the key if it doesn't exist.",
+			Since:   "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZCard, Arity: 2, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZCard,
+			Summary: "Returns the number of members in a sorted set.", Since: "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZCount, Arity: 4, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZCount,
+			Summary: "Returns the count of members in a sorted set that have scores within a range.",
+			Since:   "2.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZDiff, Arity: -3, Flags: FlagReadOnly, GetKeys: keysAfterNumKeys(1),
+			Type: internal.CommandTypeStore, Handler: handleZSetOperation,
+			Summary: "Returns the difference between multiple sorted sets.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZDiffStore, Arity: -4, Flags: FlagWrite, GetKeys: destinationAndKeysAfterNumKeys,
+			Type: internal.CommandTypeStore, Handler: handleZSetOperation,
+			Summary: "Stores the difference of multiple sorted sets in a key.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZIncrBy, Arity: 4, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZIncrBy,
+			Summary: "Increments the score of a member in a sorted set.", Since: "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZInter, Arity: -3, Flags: FlagReadOnly, GetKeys: keysAfterNumKeys(1),
+			Type: internal.CommandTypeStore, Handler: handleZSetOperation,
+			Summary: "Returns the intersect of multiple sorted sets.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZInterStore, Arity: -4, Flags: FlagWrite, GetKeys: destinationAndKeysAfterNumKeys,
+			Type: internal.CommandTypeStore, Handler: handleZSetOperation,
+			Summary: "Stores the intersect of multiple sorted sets in a key.", Since: "2.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZLexCount, Arity: 4, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZCount,
+			Summary: "Returns the number of members in a sorted set within a lexicographical range.",
+			Since:   "2.8.9", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZMPop, Arity: -4, Flags: FlagWrite, GetKeys: keysAfterNumKeys(1),
+			Type: internal.CommandTypeStore, Handler: handleZMPop,
+			Summary: "Returns the highest- or lowest-scoring members from one or more sorted sets after removing them. Deletes the sorted set if the last member was popped.",
+			Since:   "7.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZMScore, Arity: -3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZScore,
+			Summary: "Returns the score of one or more members in a sorted set.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZPopMax, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZPop,
+			Summary: "Returns the highest-scoring members from a sorted set after removing them. Deletes the sorted set if the last member was popped.",
+			Since:   "5.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZPopMin, Arity: -2, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZPop,
+			Summary: "Returns the lowest-scoring members from a sorted set after removing them. Deletes the sorted set if the last member was popped.",
+			Since:   "5.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRandMember, Arity: -2, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRandMember,
+			Summary: "Returns one or more random members from a sorted set.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRange, Arity: -4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Returns members in a sorted set within a range of indexes.", Since: "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRangeByLex, Arity: -4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Returns members in a sorted set within a lexicographical range.",
+			Since:   "2.8.9", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRangeByScore, Arity: -4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Returns members in a sorted set within a range of scores.", Since: "1.0.5", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRangeStore, Arity: -5, Flags: FlagWrite, FirstKey: 1, LastKey: 2, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Stores a range of members from sorted set in a key.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRank, Arity: -3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRank,
+			Summary: "Returns the index of a member in a sorted set ordered by ascending scores.",
+			Since:   "2.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRem, Arity: -3, Flags: FlagWrite | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRem,
+			Summary: "Removes one or more members from a sorted set. Deletes the sorted set if all members were removed.",
+			Since:   "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRemRangeByLex, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRemRange,
+			Summary: "Removes members in a sorted set within a lexicographical range. Deletes the sorted set if all members were removed.",
+			Since:   "2.8.9", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRemRangeByRank, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRemRange,
+			Summary: "Removes members in a sorted set within a range of indexes. Deletes the sorted set if all members were removed.",
+			Since:   "2.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRemRangeByScore, Arity: 4, Flags: FlagWrite, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRemRange,
+			Summary: "Removes members in a sorted set within a range of scores. Deletes the sorted set if all members were removed.",
+			Since:   "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRevRange, Arity: -4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Returns members in a sorted set within a range of indexes in reverse order.",
+			Since:   "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRevRangeByLex, Arity: -4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Returns members in a sorted set within a lexicographical range in reverse order.",
+			Since:   "2.8.9", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRevRangeByScore, Arity: -4, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRange,
+			Summary: "Returns members in a sorted set within a range of scores in reverse order.",
+			Since:   "2.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZRevRank, Arity: -3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZRank,
+			Summary: "Returns the index of a member in a sorted set ordered by descending scores.",
+			Since:   "2.0.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZScan, Arity: -3, Flags: FlagReadOnly, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZScan,
+			Summary: "Iterates over members and scores of a sorted set.", Since: "2.8.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZScore, Arity: 3, Flags: FlagReadOnly | FlagFast, FirstKey: 1, LastKey: 1, KeyStep: 1,
+			Type: internal.CommandTypeStore, Handler: handleZScore,
+			Summary: "Returns the score of a member in a sorted set.", Since: "1.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZUnion, Arity: -3, Flags: FlagReadOnly, GetKeys: keysAfterNumKeys(1),
+			Type: internal.CommandTypeStore, Handler: handleZSetOperation,
+			Summary: "Returns the union of multiple sorted sets.", Since: "6.2.0", Group: "sorted-set",
+		},
+		{
+			Name: internal.CommandZUnionStore, Arity: -4, Flags: FlagWrite, GetKeys: destinationAndKeysAfterNumKeys,
+			Type: internal.CommandTypeStore, Handler: handleZSetOperation,
+			Summary: "Stores the union of multiple sorted sets in a key.", Since: "2.0.0", Group: "sorted-set",
+		},
+	}
+	for _, spec := range specs {
+		commandTable[spec.Name] = spec
+	}
+}
+
+// LookupCommand returns the spec of the command with the given lowercase name.
+func LookupCommand(name string) (*CommandSpec, bool) {
+	spec, ok := commandTable[name]
+	return spec, ok
+}
+
+// sortedCommandSpecs returns every command spec ordered by name.
+func sortedCommandSpecs() []*CommandSpec {
+	specs := make([]*CommandSpec, 0, len(commandTable))
+	for _, spec := range commandTable {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// acceptsArgCount reports whether argc, the number of arguments including
+// the command name, satisfies the arity of the command.
+func (spec *CommandSpec) acceptsArgCount(argc int) bool {
+	if spec.Arity >= 0 {
+		return argc == spec.Arity
+	}
+	return argc >= -spec.Arity
+}
+
+// keyPositions returns the positions of the keys in a call of the command
+// with the arguments (including the command name).
+func (spec *CommandSpec) keyPositions(args []string) []int {
+	if spec.GetKeys != nil {
+		return spec.GetKeys(args)
+	}
+	argc := len(args)
+	if spec.FirstKey <= 0 {
+		return nil
+	}
+	last := spec.LastKey
+	if last < 0 {
+		last = argc + last
+	}
+	var positions []int
+	for i := spec.FirstKey; i <= last && i < argc; i += spec.KeyStep {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+func (spec *CommandSpec) flagNames() []string {
+	var names []string
+	for _, f := range commandFlagNames {
+		if spec.Flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	if spec.GetKeys != nil {
+		names = append(names, "movablekeys")
+	}
+	return names
+}
+
+// aclCategories derives the ACL categories of the command from its flags
+// and group, which is how Redis assigns most of them.
+func (spec *CommandSpec) aclCategories() []string {
+	var categories []string
+	switch spec.Group {
+	case "generic":
+		categories = append(categories, "@keyspace")
+	case "string", "list", "hash", "set", "connection":
+		categories = append(categories, "@"+spec.Group)
+	case "sorted-set":
+		categories = append(categories, "@sortedset")
+	}
+	if spec.Flags&FlagWrite != 0 {
+		categories = append(categories, "@write")
+	}
+	if spec.Flags&FlagReadOnly != 0 {
+		categories = append(categories, "@read")
+	}
+	if spec.Flags&FlagAdmin != 0 {
+		categories = append(categories, "@admin", "@dangerous")
+	}
+	if spec.Flags&FlagPubSub != 0 {
+		categories = append(categories, "@pubsub")
+	}
+	if spec.Flags&FlagBlocking != 0 {
+		categories = append(categories, "@blocking")
+	}
+	if spec.Flags&FlagFast != 0 {
+		categories = append(categories, "@fast")
+	} else {
+		categories = append(categories, "@slow")
+	}
+	return categories
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+const (
+	errOverflow      = "ERR increment or decrement would overflow"
+	errNotAFloat     = "ERR value is not a valid float"
+	errNaNOrInfinity = "ERR increment would produce NaN or Infinity"
+)
+
+// INCR key
+// DECR key
+// INCRBY key increment
+// DECRBY key decrement
+func handleIncr(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	var incr int64 = 1
+	if len(cmd.Arguments) == 2 {
+		incrStr, err := getString(cmd.Arguments[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse increment")
+		}
+		var ok bool
+		if incr, ok = parseInteger(incrStr); !ok {
+			return rtypes.NewSimpleError(errNotAnInteger), nil
+		}
+	}
+	if cmd.Name == internal.CommandDecr || cmd.Name == internal.CommandDecrBy {
+		// The decrement can't be negated
+		if incr == math.MinInt64 {
+			return rtypes.NewSimpleError("ERR decrement would overflow"), nil
+		}
+		incr = -incr
+	}
+
+	current, err := getCounter(store, key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := parseInteger(string(current))
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	if (incr < 0 && value < 0 && incr < math.MinInt64-value) ||
+		(incr > 0 && value > 0 && incr > math.MaxInt64-value) {
+		return rtypes.NewSimpleError(errOverflow), nil
+	}
+	value += incr
+	store.SetKeepTTL(key, strconv.AppendInt(nil, value, 10))
+	return &rtypes.Int{Value: int(value)}, nil
+}
+
+// INCRBYFLOAT key increment
+func handleIncrByFloat(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	incrStr, err := getString(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse increment")
+	}
+	incr, ok := parseLongDouble(incrStr)
+	if !ok {
+		return rtypes.NewSimpleError(errNotAFloat), nil
+	}
+
+	current, err := getCounter(store, key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := parseLongDouble(string(current))
+	if !ok {
+		return rtypes.NewSimpleError(errNotAFloat), nil
+	}
+	formatted, ok := addLongDoubles(value, incr)
+	if !ok {
+		return rtypes.NewSimpleError(errNaNOrInfinity), nil
+	}
+	store.SetKeepTTL(key, []byte(formatted))
+	return rtypes.NewBulkString(formatted), nil
+}
+
+// getCounter returns the value of the key, or "0" if it does not exist.
+func getCounter(store *internal.Store, key string) ([]byte, error) {
+	value, ok, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []byte("0"), nil
+	}
+	return value, nil
+}
+
+// parseInteger parses a 64 bit integer as strictly as string2ll in Redis,
+// which rejects spaces, a leading '+' and leading zeros.
+func parseInteger(str string) (int64, bool) {
+	if str == "0" {
+		return 0, true
+	}
+	digits := strings.TrimPrefix(str, "-")
+	if len(digits) == 0 || digits[0] < '1' || digits[0] > '9' {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(str, 10, 64)
+	return value, err == nil
+}
+
+// parseFloat parses a float like string2ld in Redis, which rejects spaces,
+// NaN and values out of range.
+func parseFloat(str string) (float64, bool) {
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(value) {
+		return 0, false
+	}
+	return value, true
+}
+
+// Precision and range of the long double INCRBYFLOAT computes with in Redis
+// on x86, which is the x87 extended precision format: a 64 bit mantissa, and
+// at most 1.19e4932, whose binary exponent as returned by big.Float.MantExp
+// is 16384
+const (
+	longDoublePrec   = 64
+	longDoubleMaxExp = 16384
+)
+
+// parseLongDouble parses a float like parseFloat, but with the precision and
+// range of a long double. Values beyond the range are infinite.
+func parseLongDouble(str string) (*big.Float, bool) {
+	// The syntax is that of a float64, only the range differs
+	value, err := strconv.ParseFloat(str, 64)
+	if (err != nil && !errors.Is(err, strconv.ErrRange)) || math.IsNaN(value) {
+		return nil, false
+	}
+	if err == nil && math.IsInf(value, 0) {
+		return new(big.Float).SetInf(value < 0), true
+	}
+	ld, _, err := big.ParseFloat(str, 0, longDoublePrec, big.ToNearestEven)
+	if err != nil {
+		return nil, false
+	}
+	if ld.MantExp(nil) > longDoubleMaxExp {
+		return new(big.Float).SetInf(ld.Signbit()), true
+	}
+	return ld, true
+}
+
+// addLongDoubles adds the values and formats the sum like INCRBYFLOAT.
+// Returns false if the sum is not finite as a long double.
+func addLongDoubles(x, y *big.Float) (string, bool) {
+	if x.IsInf() || y.IsInf() {
+		return "", false
+	}
+	sum := new(big.Float).SetPrec(longDoublePrec).Add(x, y)
+	if sum.MantExp(nil) > longDoubleMaxExp {
+		return "", false
+	}
+	return formatLongDouble(sum), true
+}
+
+// formatLongDouble formats the value in the human friendly form of
+// INCRBYFLOAT, like ld2string in Redis: 17 digits after the point, without
+// the trailing zeros, so that 0.1 plus 0.2 is 0.3.
+func formatLongDouble(value *big.Float) string {
+	formatted := strings.TrimRight(value.Text('f', 17), "0")
+	formatted = strings.TrimSuffix(formatted, ".")
+	if formatted == "-0" {
+		return "0"
+	}
+	return formatted
+}
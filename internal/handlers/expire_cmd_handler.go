@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// EXPIRE | PEXPIRE | EXPIREAT | PEXPIREAT key time [NX | XX | GT | LT]
+func handleExpire(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	timeStr, err := getString(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse time")
+	}
+	when, err := strconv.ParseInt(timeStr, 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+
+	var nx, xx, gt, lt bool
+	for _, arg := range cmd.Arguments[2:] {
+		option, err := getString(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse option")
+		}
+		switch strings.ToLower(option) {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "gt":
+			gt = true
+		case "lt":
+			lt = true
+		default:
+			return rtypes.NewSimpleError("ERR Unsupported option " + option), nil
+		}
+	}
+	if nx && (xx || gt || lt) {
+		return rtypes.NewSimpleError("ERR NX and XX, GT or LT options at the same time are not compatible"), nil
+	}
+	if gt && lt {
+		return rtypes.NewSimpleError("ERR GT and LT options at the same time are not compatible"), nil
+	}
+
+	// Convert the time to an absolute unix time in milliseconds
+	invalidExpireTime := rtypes.NewSimpleError(fmt.Sprintf("ERR invalid expire time in '%s' command", cmd.Name))
+	if cmd.Name == internal.CommandExpire || cmd.Name == internal.CommandExpireAt {
+		if when > math.MaxInt64/1000 || when < math.MinInt64/1000 {
+			return invalidExpireTime, nil
+		}
+		when *= 1000
+	}
+	if cmd.Name == internal.CommandExpire || cmd.Name == internal.CommandPExpire {
+		nowMillis := store.Now().UnixMilli()
+		if when > math.MaxInt64-nowMillis {
+			return invalidExpireTime, nil
+		}
+		when += nowMillis
+	}
+
+	if !store.Exists(key) {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	current, hasExpiry := store.Expiry(key)
+	// A key without a TTL is treated as having an infinite TTL by GT and LT
+	if (nx && hasExpiry) ||
+		(xx && !hasExpiry) ||
+		(gt && (!hasExpiry || when <= current.UnixMilli())) ||
+		(lt && hasExpiry && when >= current.UnixMilli()) {
+		return &rtypes.Int{Value: 0}, nil
+	}
+
+	if when <= store.Now().UnixMilli() {
+		store.Delete(key)
+	} else {
+		store.SetExpiry(key, time.UnixMilli(when))
+	}
+	return &rtypes.Int{Value: 1}, nil
+}
+
+// TTL | PTTL | EXPIRETIME | PEXPIRETIME key
+func handleTTL(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	if !store.Exists(key) {
+		return &rtypes.Int{Value: -2}, nil
+	}
+	deadline, ok := store.Expiry(key)
+	if !ok {
+		return &rtypes.Int{Value: -1}, nil
+	}
+
+	var ttl int64
+	switch cmd.Name {
+	case internal.CommandExpireTime, internal.CommandPExpireTime:
+		ttl = deadline.UnixMilli()
+	default:
+		ttl = max(deadline.UnixMilli()-store.Now().UnixMilli(), 0)
+	}
+	// Like Redis, seconds are rounded to the nearest
+	if cmd.Name == internal.CommandTTL || cmd.Name == internal.CommandExpireTime {
+		ttl = (ttl + 500) / 1000
+	}
+	return &rtypes.Int{Value: int(ttl)}, nil
+}
+
+// PERSIST key
+func handlePersist(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	if store.Persist(key) {
+		return &rtypes.Int{Value: 1}, nil
+	}
+	return &rtypes.Int{Value: 0}, nil
+}
@@ -1,30 +1,105 @@
 package handlers
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/ram-the-coder/redisgo/internal"
 	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
-	"github.com/rs/zerolog/log"
 )
 
 func GetResponseForGeneralCommand() func(*internal.Command) (rtypes.RespDataType, error) {
 	return func(cmd *internal.Command) (rtypes.RespDataType, error) {
-		switch cmd.Name {
-		case internal.CommandHello:
-			kvPairs := [][2]rtypes.RespDataType{
-				{rtypes.NewBulkString("server"), rtypes.NewBulkString("redis")},
-				{rtypes.NewBulkString("version"), rtypes.NewBulkString("8.4.0")},
-				{rtypes.NewBulkString("proto"), &rtypes.Int{Value: 3}},
-				{rtypes.NewBulkString("id"), &rtypes.Int{Value: 1}},
-				{rtypes.NewBulkString("mode"), rtypes.NewBulkString("standalone")},
-				{rtypes.NewBulkString("role"), rtypes.NewBulkString("master")},
-				{rtypes.NewBulkString("modules"), &rtypes.Array{Elements: []rtypes.RespDataType{}}},
+		return execute(nil, cmd)
+	}
+}
+
+// HELLO [protover [AUTH username password] [SETNAME clientname]]
+func handleHello(_ *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	client := cmd.Metadata.Client
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+
+	protocol := client.Protocol()
+	if len(args) > 0 {
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return rtypes.NewSimpleError("ERR Protocol version is not an integer or out of range"), nil
+		}
+		if version < internal.Resp2 || version > internal.Resp3 {
+			return rtypes.NewSimpleError("NOPROTO unsupported protocol version"), nil
+		}
+		protocol = int(version)
+	}
+
+	var name *string
+	for i := 1; i < len(args); i++ {
+		remaining := len(args) - i - 1
+		switch option := strings.ToLower(args[i]); {
+		case option == "auth" && remaining >= 2:
+			// There is no ACL system, so only the default user exists and it
+			// accepts any password, like Redis without requirepass.
+			if args[i+1] != "default" {
+				return rtypes.NewSimpleError("WRONGPASS invalid username-password pair or user is disabled."), nil
+			}
+			i += 2
+		case option == "setname" && remaining >= 1:
+			if !isValidClientName(args[i+1]) {
+				return rtypes.NewSimpleError("ERR Client names cannot contain spaces, newlines or special characters."), nil
 			}
-			return &rtypes.Map{KvPairs: kvPairs}, nil
-		case internal.CommandPing:
-			return rtypes.NewSimpleString("PONG"), nil
+			name = &args[i+1]
+			i++
 		default:
-			log.Error().Msgf("unknown general command: %s", cmd.Name)
-			return rtypes.NewSimpleError("ERR unknown command"), nil
+			return rtypes.NewSimpleError(sanitizeErrorMessage(fmt.Sprintf(
+				"ERR Syntax error in HELLO option '%.128s'", args[i]))), nil
+		}
+	}
+
+	// Only switch once all options are known to be valid
+	if name != nil {
+		client.SetName(*name)
+	}
+	client.SetProtocol(protocol)
+	cmd.Metadata.Protocol = protocol
+
+	kvPairs := [][2]rtypes.RespDataType{
+		{rtypes.NewBulkString("server"), rtypes.NewBulkString("redis")},
+		{rtypes.NewBulkString("version"), rtypes.NewBulkString("8.4.0")},
+		{rtypes.NewBulkString("proto"), &rtypes.Int{Value: protocol}},
+		{rtypes.NewBulkString("id"), &rtypes.Int{Value: int(client.ID)}},
+		{rtypes.NewBulkString("mode"), rtypes.NewBulkString("standalone")},
+		{rtypes.NewBulkString("role"), rtypes.NewBulkString("master")},
+		{rtypes.NewBulkString("modules"), &rtypes.Array{Elements: []rtypes.RespDataType{}}},
+	}
+	return &rtypes.Map{KvPairs: kvPairs}, nil
+}
+
+// isValidClientName reports whether the name only contains printable
+// characters other than space, the same rule Redis applies.
+func isValidClientName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if name[i] < '!' || name[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// PING [message]
+func handlePing(_ *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	switch len(cmd.Arguments) {
+	case 0:
+		return rtypes.NewSimpleString("PONG"), nil
+	case 1:
+		message, err := getString(cmd.Arguments[0])
+		if err != nil {
+			return nil, err
 		}
+		return rtypes.NewBulkString(message), nil
+	default:
+		return wrongNumberOfArguments(cmd), nil
 	}
 }
@@ -0,0 +1,461 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/glob"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+const (
+	errHashValueNotAnInteger = "ERR hash value is not an integer"
+	errHashValueNotAFloat    = "ERR hash value is not a float"
+)
+
+// HSET key field value [field value ...]
+// HMSET key field value [field value ...]
+func handleHSet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	if len(cmd.Arguments)%2 != 1 {
+		return wrongNumberOfArguments(cmd), nil
+	}
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	fields := make([]string, 0, len(cmd.Arguments)/2)
+	values := make([][]byte, 0, len(cmd.Arguments)/2)
+	for i := 1; i < len(cmd.Arguments); i += 2 {
+		field, err := getString(cmd.Arguments[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse field")
+		}
+		value, err := getBytes(cmd.Arguments[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value")
+		}
+		fields = append(fields, field)
+		values = append(values, value)
+	}
+
+	hash, err := getOrCreateHash(store, key)
+	if err != nil {
+		return nil, err
+	}
+	added := 0
+	for i, field := range fields {
+		if hash.Set(field, values[i]) {
+			added++
+		}
+	}
+	if cmd.Name == internal.CommandHMSet {
+		return rtypes.NewSimpleString("OK"), nil
+	}
+	return &rtypes.Int{Value: added}, nil
+}
+
+// HSETNX key field value
+func handleHSetNX(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments[:2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	value, err := getBytes(cmd.Arguments[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+	}
+	hash, err := getOrCreateHash(store, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := hash.Get(args[1]); exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	hash.Set(args[1], value)
+	return &rtypes.Int{Value: 1}, nil
+}
+
+// HGET key field
+func handleHGet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Null{}, nil
+	}
+	value, ok := hash.Get(args[1])
+	if !ok {
+		return &rtypes.Null{}, nil
+	}
+	return &rtypes.BulkString{Value: value}, nil
+}
+
+// HMGET key field [field ...]
+func handleHMGet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]rtypes.RespDataType, 0, len(args)-1)
+	for _, field := range args[1:] {
+		if value, ok := hashGet(hash, exists, field); ok {
+			elements = append(elements, &rtypes.BulkString{Value: value})
+		} else {
+			elements = append(elements, &rtypes.Null{})
+		}
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// HDEL key field [field ...]
+func handleHDel(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	deleted := 0
+	for _, field := range args[1:] {
+		if hash.Delete(field) {
+			deleted++
+		}
+	}
+	if hash.Len() == 0 {
+		store.Delete(args[0])
+	}
+	return &rtypes.Int{Value: deleted}, nil
+}
+
+// HGETALL key
+// HKEYS key
+// HVALS key
+func handleHGetAll(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	hash, exists, err := store.GetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Name == internal.CommandHGetAll {
+		reply := &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{}}
+		if exists {
+			hash.ForEach(func(field string, value []byte) {
+				reply.KvPairs = append(reply.KvPairs, [2]rtypes.RespDataType{
+					rtypes.NewBulkString(field), &rtypes.BulkString{Value: value},
+				})
+			})
+		}
+		return reply, nil
+	}
+	elements := []rtypes.RespDataType{}
+	if exists {
+		hash.ForEach(func(field string, value []byte) {
+			if cmd.Name == internal.CommandHKeys {
+				elements = append(elements, rtypes.NewBulkString(field))
+			} else {
+				elements = append(elements, &rtypes.BulkString{Value: value})
+			}
+		})
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// HLEN key
+func handleHLen(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	hash, exists, err := store.GetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	return &rtypes.Int{Value: hash.Len()}, nil
+}
+
+// HEXISTS key field
+// HSTRLEN key field
+func handleHExists(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value, ok := hashGet(hash, exists, args[1])
+	if cmd.Name == internal.CommandHStrLen {
+		return &rtypes.Int{Value: len(value)}, nil
+	}
+	if ok {
+		return &rtypes.Int{Value: 1}, nil
+	}
+	return &rtypes.Int{Value: 0}, nil
+}
+
+// HINCRBY key field increment
+func handleHIncrBy(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	incr, ok := parseInteger(args[2])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var value int64
+	if current, ok := hashGet(hash, exists, args[1]); ok {
+		value, ok = parseInteger(string(current))
+		if !ok {
+			return rtypes.NewSimpleError(errHashValueNotAnInteger), nil
+		}
+	}
+	if (incr > 0 && value > math.MaxInt64-incr) || (incr < 0 && value < math.MinInt64-incr) {
+		return rtypes.NewSimpleError(errOverflow), nil
+	}
+	value += incr
+	if !exists {
+		hash = store.NewHash()
+		store.SetValue(args[0], hash)
+	}
+	// Like Redis, the field keeps its TTL
+	hash.SetKeepTTL(args[1], []byte(strconv.FormatInt(value, 10)))
+	return &rtypes.Int{Value: int(value)}, nil
+}
+
+// HINCRBYFLOAT key field increment
+func handleHIncrByFloat(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	incr, ok := parseLongDouble(args[2])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAFloat), nil
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value := new(big.Float)
+	if current, ok := hashGet(hash, exists, args[1]); ok {
+		value, ok = parseLongDouble(string(current))
+		if !ok {
+			return rtypes.NewSimpleError(errHashValueNotAFloat), nil
+		}
+	}
+	formatted, ok := addLongDoubles(value, incr)
+	if !ok {
+		return rtypes.NewSimpleError(errNaNOrInfinity), nil
+	}
+	if !exists {
+		hash = store.NewHash()
+		store.SetValue(args[0], hash)
+	}
+	hash.SetKeepTTL(args[1], []byte(formatted))
+	return rtypes.NewBulkString(formatted), nil
+}
+
+// HRANDFIELD key [count [WITHVALUES]]
+func handleHRandField(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		if !exists {
+			return &rtypes.Null{}, nil
+		}
+		field, _ := hash.Random()
+		return rtypes.NewBulkString(field), nil
+	}
+
+	count, ok := parseInteger(args[1])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	if len(args) > 3 || (len(args) == 3 && !strings.EqualFold(args[2], "withvalues")) {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	withValues := len(args) == 3
+	if count == math.MinInt64 {
+		return rtypes.NewSimpleError(fmt.Sprintf("ERR value is out of range, value must between %d and %d",
+			-math.MaxInt64, math.MaxInt64)), nil
+	}
+	// The reply holds twice as many elements
+	if withValues && (count > math.MaxInt64/2 || count < -math.MaxInt64/2) {
+		return rtypes.NewSimpleError("ERR value is out of range"), nil
+	}
+	if !exists {
+		return &rtypes.Array{Elements: []rtypes.RespDataType{}}, nil
+	}
+
+	fields, values := randomHashFields(hash, count)
+	elements := make([]rtypes.RespDataType, len(fields))
+	for i, field := range fields {
+		if withValues {
+			elements[i] = &rtypes.Array{Elements: []rtypes.RespDataType{
+				rtypes.NewBulkString(field), &rtypes.BulkString{Value: values[i]},
+			}}
+		} else {
+			elements[i] = rtypes.NewBulkString(field)
+		}
+	}
+	return &rtypes.Array{Elements: elements, Pairs: withValues}, nil
+}
+
+// randomHashFields picks count distinct fields of the hash at random, or
+// -count fields that may repeat if count is negative, the way HRANDFIELD
+// does.
+func randomHashFields(hash *internal.Hash, count int64) ([]string, [][]byte) {
+	var fields []string
+	var values [][]byte
+	add := func(field string, value []byte) {
+		fields = append(fields, field)
+		values = append(values, value)
+	}
+	switch {
+	case count < 0:
+		for range -count {
+			add(hash.Random())
+		}
+	case count >= int64(hash.Len()):
+		hash.ForEach(add)
+	case count*3 > int64(hash.Len()):
+		// Picking at random would keep finding fields already picked, so
+		// shuffle all of them instead, like Redis does
+		hash.ForEach(add)
+		rand.Shuffle(len(fields), func(i, j int) {
+			fields[i], fields[j] = fields[j], fields[i]
+			values[i], values[j] = values[j], values[i]
+		})
+		fields, values = fields[:count], values[:count]
+	default:
+		picked := make(map[string]bool, count)
+		for int64(len(picked)) < count {
+			field, value := hash.Random()
+			if !picked[field] {
+				picked[field] = true
+				add(field, value)
+			}
+		}
+	}
+	return fields, values
+}
+
+// HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES]
+func handleHScan(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError("ERR invalid cursor"), nil
+	}
+	pattern, count, noValues := "*", 10, false
+	for i := 2; i < len(args); i++ {
+		option := strings.ToLower(args[i])
+		if option == "novalues" {
+			noValues = true
+			continue
+		}
+		if i+1 == len(args) {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+		i++
+		switch option {
+		case "match":
+			pattern = args[i]
+		case "count":
+			count, err = strconv.Atoi(args[i])
+			if err != nil {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			if count < 1 {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := []rtypes.RespDataType{}
+	if exists {
+		cursor = hash.Scan(cursor, count, func(field string, value []byte) {
+			if pattern != "*" && !glob.Match(pattern, field) {
+				return
+			}
+			elements = append(elements, rtypes.NewBulkString(field))
+			if !noValues {
+				elements = append(elements, &rtypes.BulkString{Value: value})
+			}
+		})
+	} else {
+		cursor = 0
+	}
+	return &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString(strconv.FormatUint(cursor, 10)),
+		&rtypes.Array{Elements: elements},
+	}}, nil
+}
+
+// hashGet returns the value of the field of a hash that may not exist.
+func hashGet(hash *internal.Hash, exists bool, field string) ([]byte, bool) {
+	if !exists {
+		return nil, false
+	}
+	return hash.Get(field)
+}
+
+// getOrCreateHash returns the hash held by the key, storing an empty one
+// first if the key does not exist.
+func getOrCreateHash(store *internal.Store, key string) (*internal.Hash, error) {
+	hash, exists, err := store.GetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		hash = store.NewHash()
+		store.SetValue(key, hash)
+	}
+	return hash, nil
+}
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// Latest deadline a field can have, in unix milliseconds, like
+// HFE_MAX_ABS_TIME_MSEC in Redis
+const maxFieldDeadlineMillis = 1<<46 - 1
+
+// Status codes of the fields in the replies of the field expiry commands
+const (
+	fieldNotFound        = -2
+	fieldHasNoTTL        = -1
+	fieldConditionNotMet = 0
+	fieldUpdated         = 1
+	fieldDeleted         = 2
+)
+
+// HEXPIRE | HPEXPIRE | HEXPIREAT | HPEXPIREAT key time [NX | XX | GT | LT]
+// FIELDS numfields field [field ...]
+func handleHExpire(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	when, ok := parseInteger(args[1])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	condition, fieldsIndex := "", 2
+	switch option := strings.ToLower(args[2]); option {
+	case "nx", "xx", "gt", "lt":
+		condition, fieldsIndex = option, 3
+	}
+	fields, errReply := parseFields(args, fieldsIndex, 1)
+	if errReply != nil {
+		return errReply, nil
+	}
+
+	// Convert the time to an absolute unix time in milliseconds
+	if when < 0 {
+		return rtypes.NewSimpleError("ERR invalid expire time, must be >= 0"), nil
+	}
+	invalidExpireTime := rtypes.NewSimpleError(fmt.Sprintf("ERR invalid expire time in '%s' command", cmd.Name))
+	if cmd.Name == internal.CommandHExpire || cmd.Name == internal.CommandHExpireAt {
+		if when > maxFieldDeadlineMillis/1000 {
+			return invalidExpireTime, nil
+		}
+		when *= 1000
+	}
+	now := store.Now()
+	if cmd.Name == internal.CommandHExpire || cmd.Name == internal.CommandHPExpire {
+		if when > maxFieldDeadlineMillis-now.UnixMilli() {
+			return invalidExpireTime, nil
+		}
+		when += now.UnixMilli()
+	}
+	deadline := time.UnixMilli(when)
+
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]rtypes.RespDataType, len(fields))
+	for i, field := range fields {
+		status := fieldNotFound
+		if exists {
+			status = expireHashField(hash, field, deadline, condition, now)
+		}
+		elements[i] = &rtypes.Int{Value: status}
+	}
+	if exists {
+		updateHashAfterFieldExpiry(store, args[0], hash)
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// expireHashField sets the deadline of the field if the condition allows it,
+// and returns the status of the field for the reply of HEXPIRE.
+func expireHashField(hash *internal.Hash, field string, deadline time.Time, condition string, now time.Time) int {
+	if _, ok := hash.Get(field); !ok {
+		return fieldNotFound
+	}
+	current, hasTTL := hash.Expiry(field)
+	// A field without a TTL is treated as having an infinite TTL by GT and LT
+	switch condition {
+	case "nx":
+		if hasTTL {
+			return fieldConditionNotMet
+		}
+	case "xx":
+		if !hasTTL {
+			return fieldConditionNotMet
+		}
+	case "gt":
+		if !hasTTL || !deadline.After(current) {
+			return fieldConditionNotMet
+		}
+	case "lt":
+		if hasTTL && !deadline.Before(current) {
+			return fieldConditionNotMet
+		}
+	}
+	if !deadline.After(now) {
+		hash.Delete(field)
+		return fieldDeleted
+	}
+	hash.SetExpiry(field, deadline)
+	return fieldUpdated
+}
+
+// HTTL | HPTTL | HEXPIRETIME | HPEXPIRETIME key FIELDS numfields field
+// [field ...]
+func handleHTTL(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	fields, errReply := parseFields(args, 1, 1)
+	if errReply != nil {
+		return errReply, nil
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	nowMillis := store.Now().UnixMilli()
+	elements := make([]rtypes.RespDataType, len(fields))
+	for i, field := range fields {
+		if _, ok := hashGet(hash, exists, field); !ok {
+			elements[i] = &rtypes.Int{Value: fieldNotFound}
+			continue
+		}
+		deadline, ok := hash.Expiry(field)
+		if !ok {
+			elements[i] = &rtypes.Int{Value: fieldHasNoTTL}
+			continue
+		}
+		// Unlike the TTL of keys, seconds are rounded up
+		millis := deadline.UnixMilli()
+		switch cmd.Name {
+		case internal.CommandHTTL:
+			elements[i] = &rtypes.Int{Value: int((millis - nowMillis + 999) / 1000)}
+		case internal.CommandHPTTL:
+			elements[i] = &rtypes.Int{Value: int(millis - nowMillis)}
+		case internal.CommandHExpireTime:
+			elements[i] = &rtypes.Int{Value: int((millis + 999) / 1000)}
+		default:
+			elements[i] = &rtypes.Int{Value: int(millis)}
+		}
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// HPERSIST key FIELDS numfields field [field ...]
+func handleHPersist(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	fields, errReply := parseFields(args, 1, 1)
+	if errReply != nil {
+		return errReply, nil
+	}
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]rtypes.RespDataType, len(fields))
+	for i, field := range fields {
+		switch _, ok := hashGet(hash, exists, field); {
+		case !ok:
+			elements[i] = &rtypes.Int{Value: fieldNotFound}
+		case hash.Persist(field):
+			elements[i] = &rtypes.Int{Value: fieldUpdated}
+		default:
+			elements[i] = &rtypes.Int{Value: fieldHasNoTTL}
+		}
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// HGETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds |
+// PXAT unix-time-milliseconds | PERSIST] FIELDS numfields field [field ...]
+func handleHGetEx(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	now := store.Now()
+	var deadline time.Time
+	expire, persist := false, false
+	i := 1
+	for ; i < len(args) && !strings.EqualFold(args[i], "fields"); i++ {
+		switch option := strings.ToLower(args[i]); option {
+		case "persist":
+			if expire || persist {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+			persist = true
+		case "ex", "px", "exat", "pxat":
+			if expire || persist || i+1 == len(args) {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+			i++
+			var errReply *rtypes.SimpleError
+			if deadline, errReply = parseFieldDeadline(cmd.Name, option, cmd.Arguments[i], now); errReply != nil {
+				return errReply, nil
+			}
+			expire = true
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+	fields, errReply := parseFields(args, i, 1)
+	if errReply != nil {
+		return errReply, nil
+	}
+
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]rtypes.RespDataType, len(fields))
+	for j, field := range fields {
+		value, ok := hashGet(hash, exists, field)
+		if !ok {
+			elements[j] = &rtypes.Null{}
+			continue
+		}
+		elements[j] = &rtypes.BulkString{Value: value}
+		switch {
+		case persist:
+			hash.Persist(field)
+		case expire && !deadline.After(now):
+			hash.Delete(field)
+		case expire:
+			hash.SetExpiry(field, deadline)
+		}
+	}
+	if exists {
+		updateHashAfterFieldExpiry(store, args[0], hash)
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// HSETEX key [FNX | FXX] [EX seconds | PX milliseconds | EXAT unix-time-seconds |
+// PXAT unix-time-milliseconds | KEEPTTL] FIELDS numfields field value
+// [field value ...]
+func handleHSetEx(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	now := store.Now()
+	var deadline time.Time
+	condition, expire, keepTTL := "", false, false
+	i := 1
+	for ; i < len(args) && !strings.EqualFold(args[i], "fields"); i++ {
+		switch option := strings.ToLower(args[i]); option {
+		case "fnx", "fxx":
+			if condition != "" {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+			condition = option
+		case "keepttl":
+			if expire || keepTTL {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+			keepTTL = true
+		case "ex", "px", "exat", "pxat":
+			if expire || keepTTL || i+1 == len(args) {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+			i++
+			var errReply *rtypes.SimpleError
+			if deadline, errReply = parseFieldDeadline(cmd.Name, option, cmd.Arguments[i], now); errReply != nil {
+				return errReply, nil
+			}
+			expire = true
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+	pairs, errReply := parseFields(args, i, 2)
+	if errReply != nil {
+		return errReply, nil
+	}
+
+	hash, exists, err := store.GetHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	// FNX sets the fields only if none of them exist, FXX only if all of them
+	// do
+	for j := 0; j < len(pairs) && condition != ""; j += 2 {
+		_, ok := hashGet(hash, exists, pairs[j])
+		if (condition == "fnx" && ok) || (condition == "fxx" && !ok) {
+			return &rtypes.Int{Value: 0}, nil
+		}
+	}
+	if !exists {
+		hash = store.NewHash()
+		store.SetValue(args[0], hash)
+	}
+	for j := 0; j < len(pairs); j += 2 {
+		field, value := pairs[j], []byte(pairs[j+1])
+		switch {
+		case keepTTL:
+			hash.SetKeepTTL(field, value)
+		case expire && !deadline.After(now):
+			hash.Delete(field)
+		case expire:
+			hash.Set(field, value)
+			hash.SetExpiry(field, deadline)
+		default:
+			hash.Set(field, value)
+		}
+	}
+	updateHashAfterFieldExpiry(store, args[0], hash)
+	return &rtypes.Int{Value: 1}, nil
+}
+
+// parseFields parses the FIELDS numfields arguments that start at the index,
+// followed by the fields that end the command, each made of perField
+// arguments.
+func parseFields(args []string, index int, perField int) ([]string, *rtypes.SimpleError) {
+	if index+1 >= len(args) || !strings.EqualFold(args[index], "fields") {
+		return nil, rtypes.NewSimpleError("ERR Mandatory argument FIELDS is missing or not at the right position")
+	}
+	numFields, ok := parseInteger(args[index+1])
+	if !ok || numFields <= 0 {
+		return nil, rtypes.NewSimpleError("ERR Parameter `numFields` should be greater than 0")
+	}
+	fields := args[index+2:]
+	if numFields > int64(len(fields)) || int(numFields)*perField != len(fields) {
+		return nil, rtypes.NewSimpleError("ERR The `numfields` parameter must match the number of arguments")
+	}
+	return fields, nil
+}
+
+// parseFieldDeadline parses the argument of one of the EX, PX, EXAT and PXAT
+// options of HGETEX and HSETEX like the same options of SET, within the
+// deadlines a field can have.
+func parseFieldDeadline(cmdName string, option string, arg rtypes.RespDataType, now time.Time) (time.Time, *rtypes.SimpleError) {
+	deadline, errReply := parseDeadline(cmdName, option, arg, now)
+	if errReply != nil {
+		return time.Time{}, errReply
+	}
+	if deadline.UnixMilli() > maxFieldDeadlineMillis {
+		return time.Time{}, rtypes.NewSimpleError(fmt.Sprintf("ERR invalid expire time in '%s' command", cmdName))
+	}
+	return deadline, nil
+}
+
+// updateHashAfterFieldExpiry deletes the hash held by the key once the TTLs
+// given to its fields deleted all of them, and registers it with the active
+// expire cycle otherwise.
+func updateHashAfterFieldExpiry(store *internal.Store, key string, hash *internal.Hash) {
+	if hash.Len() == 0 {
+		store.Delete(key)
+	} else if hash.HasExpiringFields() {
+		store.TrackHashFieldExpiry(key)
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// DEL key [key ...]
+// UNLINK key [key ...]
+func handleDel(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keys, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keys")
+	}
+	deleted := 0
+	for _, key := range keys {
+		var ok bool
+		if cmd.Name == internal.CommandUnlink {
+			ok = store.Unlink(key)
+		} else {
+			ok = store.Delete(key)
+		}
+		if ok {
+			deleted++
+		}
+	}
+	return &rtypes.Int{Value: deleted}, nil
+}
+
+// EXISTS key [key ...]
+// TOUCH key [key ...]
+func handleExists(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keys, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keys")
+	}
+	// A key given several times is counted every time
+	count := 0
+	for _, key := range keys {
+		if store.Exists(key) {
+			count++
+		}
+	}
+	return &rtypes.Int{Value: count}, nil
+}
+
+// TYPE key
+func handleType(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	valueType, ok := store.Type(key)
+	if !ok {
+		return rtypes.NewSimpleString("none"), nil
+	}
+	return rtypes.NewSimpleString(valueType.String()), nil
+}
+
+// RENAME key newkey
+// RENAMENX key newkey
+func handleRename(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keys, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keys")
+	}
+	src, dst := keys[0], keys[1]
+	nx := cmd.Name == internal.CommandRenameNX
+	if !store.Exists(src) {
+		return rtypes.NewSimpleError(errNoSuchKey), nil
+	}
+	if src == dst {
+		if nx {
+			return &rtypes.Int{Value: 0}, nil
+		}
+		return rtypes.NewSimpleString("OK"), nil
+	}
+	if nx {
+		if store.Exists(dst) {
+			return &rtypes.Int{Value: 0}, nil
+		}
+		store.Rename(src, dst)
+		return &rtypes.Int{Value: 1}, nil
+	}
+	store.Rename(src, dst)
+	return rtypes.NewSimpleString("OK"), nil
+}
+
+// COPY source destination [DB destination-db] [REPLACE]
+func handleCopy(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	src, dst := args[0], args[1]
+	replace := false
+	for i := 2; i < len(args); i++ {
+		switch {
+		case strings.EqualFold(args[i], "replace"):
+			replace = true
+		case strings.EqualFold(args[i], "db") && i+1 < len(args):
+			db, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			// There is a single database
+			if db != 0 {
+				return rtypes.NewSimpleError("ERR DB index is out of range"), nil
+			}
+			i++
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	if src == dst {
+		return rtypes.NewSimpleError("ERR source and destination objects are the same"), nil
+	}
+	if !store.Exists(src) || (!replace && store.Exists(dst)) {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	store.Copy(src, dst)
+	return &rtypes.Int{Value: 1}, nil
+}
+
+// OBJECT ENCODING key
+func handleObject(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	switch subcommand := strings.ToLower(args[0]); subcommand {
+	case "encoding":
+		if len(args) != 2 {
+			return wrongNumberOfSubcommandArguments(cmd, subcommand), nil
+		}
+		value, ok := store.Lookup(args[1])
+		if !ok {
+			return &rtypes.Null{}, nil
+		}
+		return rtypes.NewBulkString(value.Encoding().String()), nil
+	default:
+		return rtypes.NewSimpleError(sanitizeErrorMessage(fmt.Sprintf(
+			"ERR unknown subcommand '%.128s'. Try OBJECT HELP.", args[0]))), nil
+	}
+}
+
+// KEYS pattern
+func handleKeys(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	pattern, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pattern")
+	}
+	return bulkStrings(store.Keys(pattern)), nil
+}
+
+// Names of the types a SCAN can be filtered by
+var scanTypeNames = map[string]bool{
+	"string": true, "list": true, "set": true, "zset": true, "hash": true, "stream": true,
+}
+
+// SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]
+func handleScan(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError("ERR invalid cursor"), nil
+	}
+	pattern, count, typeName := "*", 10, ""
+	for i := 1; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+		switch strings.ToLower(args[i]) {
+		case "match":
+			pattern = args[i+1]
+		case "count":
+			count, err = strconv.Atoi(args[i+1])
+			if err != nil {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			if count < 1 {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+		case "type":
+			typeName = strings.ToLower(args[i+1])
+			if !scanTypeNames[typeName] {
+				return rtypes.NewSimpleError(sanitizeErrorMessage(fmt.Sprintf("ERR unknown type name '%s'", args[i+1]))), nil
+			}
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	cursor, keys := store.Scan(cursor, count, pattern)
+	if typeName != "" {
+		matching := keys[:0]
+		for _, key := range keys {
+			if valueType, ok := store.Type(key); ok && valueType.String() == typeName {
+				matching = append(matching, key)
+			}
+		}
+		keys = matching
+	}
+	return &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString(strconv.FormatUint(cursor, 10)),
+		bulkStrings(keys),
+	}}, nil
+}
+
+func bulkStrings(strs []string) rtypes.RespDataType {
+	elements := make([]rtypes.RespDataType, len(strs))
+	for i, str := range strs {
+		elements[i] = rtypes.NewBulkString(str)
+	}
+	return &rtypes.Array{Elements: elements}
+}
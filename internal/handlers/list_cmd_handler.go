@@ -0,0 +1,616 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+const errIndexOutOfRange = "ERR index out of range"
+
+// LPUSH key element [element ...]
+// RPUSH key element [element ...]
+// LPUSHX key element [element ...]
+// RPUSHX key element [element ...]
+func handlePush(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	end := internal.ListTail
+	if cmd.Name == internal.CommandLPush || cmd.Name == internal.CommandLPushX {
+		end = internal.ListHead
+	}
+	list, exists, err := store.GetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if cmd.Name == internal.CommandLPushX || cmd.Name == internal.CommandRPushX {
+			return &rtypes.Int{Value: 0}, nil
+		}
+		list = internal.NewList()
+		store.SetValue(key, list)
+	}
+	for _, arg := range cmd.Arguments[1:] {
+		element, err := getBytes(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse element")
+		}
+		list.Push(element, end)
+	}
+	return &rtypes.Int{Value: list.Len()}, nil
+}
+
+// LPOP key [count]
+// RPOP key [count]
+func handlePop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	count := -1 // a single element is popped and replied without an array
+	if len(args) == 2 {
+		n, ok := parseInteger(args[1])
+		if !ok || n < 0 {
+			return rtypes.NewSimpleError("ERR value is out of range, must be positive"), nil
+		}
+		count = int(min(n, math.MaxInt32))
+	}
+	end := internal.ListTail
+	if cmd.Name == internal.CommandLPop {
+		end = internal.ListHead
+	}
+
+	list, exists, err := store.GetList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Null{Array: count >= 0}, nil
+	}
+	if count < 0 {
+		element := popListElements(store, args[0], list, end, 1)[0]
+		return &rtypes.BulkString{Value: element}, nil
+	}
+	return bulkStringArray(popListElements(store, args[0], list, end, count)), nil
+}
+
+// LMPOP numkeys key [key ...] LEFT | RIGHT [COUNT count]
+func handleLMPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	keys, end, count, errReply := parseMPopArgs(args, parseListEnd)
+	if errReply != nil {
+		return errReply, nil
+	}
+	for _, key := range keys {
+		list, exists, err := store.GetList(key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return &rtypes.Array{Elements: []rtypes.RespDataType{
+				rtypes.NewBulkString(key),
+				bulkStringArray(popListElements(store, key, list, end, count)),
+			}}, nil
+		}
+	}
+	return &rtypes.Null{Array: true}, nil
+}
+
+// parseMPopArgs parses the arguments of LMPOP and ZMPOP, starting at numkeys,
+// with parseEnd parsing the end to pop from.
+func parseMPopArgs[T any](args []string, parseEnd func(string) (T, bool)) ([]string, T, int, *rtypes.SimpleError) {
+	var noEnd T
+	numKeys, ok := parseInteger(args[0])
+	if !ok || numKeys <= 0 {
+		return nil, noEnd, 0, rtypes.NewSimpleError("ERR numkeys should be greater than 0")
+	}
+	if numKeys >= int64(len(args)-1) {
+		return nil, noEnd, 0, rtypes.NewSimpleError(errSyntax)
+	}
+	keys := args[1 : numKeys+1]
+	end, ok := parseEnd(args[numKeys+1])
+	if !ok {
+		return nil, noEnd, 0, rtypes.NewSimpleError(errSyntax)
+	}
+	count := -1
+	for i := int(numKeys) + 2; i < len(args); i++ {
+		if count != -1 || !strings.EqualFold(args[i], "count") || i+1 == len(args) {
+			return nil, noEnd, 0, rtypes.NewSimpleError(errSyntax)
+		}
+		i++
+		n, ok := parseInteger(args[i])
+		if !ok || n <= 0 {
+			return nil, noEnd, 0, rtypes.NewSimpleError("ERR count should be greater than 0")
+		}
+		count = int(min(n, math.MaxInt32))
+	}
+	if count == -1 {
+		count = 1
+	}
+	return keys, end, count, nil
+}
+
+// popListElements pops up to count elements from the list held by the key,
+// and deletes the key once the list is empty.
+func popListElements(store *internal.Store, key string, list *internal.List, end internal.ListEnd, count int) [][]byte {
+	elements := make([][]byte, 0, min(count, list.Len()))
+	for range count {
+		element, ok := list.Pop(end)
+		if !ok {
+			break
+		}
+		elements = append(elements, element)
+	}
+	if list.Len() == 0 {
+		store.Delete(key)
+	}
+	return elements
+}
+
+// LLEN key
+func handleLLen(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	list, exists, err := store.GetList(key)
+	if err != nil || !exists {
+		return &rtypes.Int{Value: 0}, err
+	}
+	return &rtypes.Int{Value: list.Len()}, nil
+}
+
+// LRANGE key start stop
+func handleLRange(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	start, startOk := parseInteger(args[1])
+	stop, stopOk := parseInteger(args[2])
+	if !startOk || !stopOk {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	list, exists, err := store.GetList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Array{Elements: []rtypes.RespDataType{}}, nil
+	}
+	return bulkStringArray(list.Range(clampIndex(start), clampIndex(stop))), nil
+}
+
+// LINDEX key index
+func handleLIndex(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	index, ok := parseInteger(args[1])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	list, exists, err := store.GetList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Null{}, nil
+	}
+	element, ok := list.Index(clampIndex(index))
+	if !ok {
+		return &rtypes.Null{}, nil
+	}
+	return &rtypes.BulkString{Value: element}, nil
+}
+
+// LSET key index element
+func handleLSet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	indexStr, err := getString(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index")
+	}
+	element, err := getBytes(cmd.Arguments[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse element")
+	}
+	index, ok := parseInteger(indexStr)
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	list, exists, err := store.GetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return rtypes.NewSimpleError(errNoSuchKey), nil
+	}
+	if !list.Set(clampIndex(index), element) {
+		return rtypes.NewSimpleError(errIndexOutOfRange), nil
+	}
+	return rtypes.NewSimpleString("OK"), nil
+}
+
+// LINSERT key BEFORE | AFTER pivot element
+func handleLInsert(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	where, err := getString(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse position")
+	}
+	pivot, err := getBytes(cmd.Arguments[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pivot")
+	}
+	element, err := getBytes(cmd.Arguments[3])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse element")
+	}
+	var after bool
+	switch strings.ToLower(where) {
+	case "before":
+	case "after":
+		after = true
+	default:
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	list, exists, err := store.GetList(key)
+	if err != nil || !exists {
+		return &rtypes.Int{Value: 0}, err
+	}
+	if !list.Insert(pivot, element, after) {
+		return &rtypes.Int{Value: -1}, nil
+	}
+	return &rtypes.Int{Value: list.Len()}, nil
+}
+
+// LREM key count element
+func handleLRem(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	countStr, err := getString(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse count")
+	}
+	element, err := getBytes(cmd.Arguments[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse element")
+	}
+	count, ok := parseInteger(countStr)
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	list, exists, err := store.GetList(key)
+	if err != nil || !exists {
+		return &rtypes.Int{Value: 0}, err
+	}
+
+	// A negative count removes the occurrences closest to the tail
+	var it *internal.ListIterator
+	if count < 0 {
+		it = list.Iterator(list.Len()-1, true)
+		count = -count
+	} else {
+		it = list.Iterator(0, false)
+	}
+	removed := 0
+	for count == 0 || int64(removed) < count {
+		current, ok := it.Next()
+		if !ok {
+			break
+		}
+		if string(current) == string(element) {
+			it.Remove()
+			removed++
+		}
+	}
+	if list.Len() == 0 {
+		store.Delete(key)
+	}
+	return &rtypes.Int{Value: removed}, nil
+}
+
+// LTRIM key start stop
+func handleLTrim(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	start, startOk := parseInteger(args[1])
+	stop, stopOk := parseInteger(args[2])
+	if !startOk || !stopOk {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	list, exists, err := store.GetList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		list.Trim(clampIndex(start), clampIndex(stop))
+		if list.Len() == 0 {
+			store.Delete(args[0])
+		}
+	}
+	return rtypes.NewSimpleString("OK"), nil
+}
+
+// LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+func handleLPos(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	element, err := getBytes(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse element")
+	}
+	options, err := getStrings(cmd.Arguments[2:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse options")
+	}
+	var rank, count, maxLen int64 = 1, -1, 0 // a count of -1 replies with a single position
+	for i := 0; i < len(options); i += 2 {
+		if i+1 == len(options) {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+		n, ok := parseInteger(options[i+1])
+		switch strings.ToLower(options[i]) {
+		case "rank":
+			if !ok || n == math.MinInt64 {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			if n == 0 {
+				return rtypes.NewSimpleError("ERR RANK can't be zero: use 1 to start from the first match, " +
+					"2 from the second ... or use negative to start from the end of the list"), nil
+			}
+			rank = n
+		case "count":
+			if !ok || n < 0 {
+				return rtypes.NewSimpleError("ERR COUNT can't be negative"), nil
+			}
+			count = n
+		case "maxlen":
+			if !ok || n < 0 {
+				return rtypes.NewSimpleError("ERR MAXLEN can't be negative"), nil
+			}
+			maxLen = n
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	list, exists, err := store.GetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if count >= 0 {
+			return &rtypes.Array{Elements: []rtypes.RespDataType{}}, nil
+		}
+		return &rtypes.Null{}, nil
+	}
+
+	// A negative rank looks for the matches from the tail
+	reverse := rank < 0
+	var it *internal.ListIterator
+	if reverse {
+		rank = -rank
+		it = list.Iterator(list.Len()-1, true)
+	} else {
+		it = list.Iterator(0, false)
+	}
+	positions := []rtypes.RespDataType{}
+	var matches int64
+	for scanned := int64(0); maxLen == 0 || scanned < maxLen; scanned++ {
+		current, ok := it.Next()
+		if !ok {
+			break
+		}
+		if string(current) != string(element) {
+			continue
+		}
+		matches++
+		if matches < rank {
+			continue
+		}
+		position := scanned
+		if reverse {
+			position = int64(list.Len()) - 1 - scanned
+		}
+		positions = append(positions, &rtypes.Int{Value: int(position)})
+		if count != 0 && int64(len(positions)) >= max(count, 1) {
+			break
+		}
+	}
+	if count >= 0 {
+		return &rtypes.Array{Elements: positions}, nil
+	}
+	if len(positions) == 0 {
+		return &rtypes.Null{}, nil
+	}
+	return positions[0], nil
+}
+
+// LMOVE source destination LEFT | RIGHT LEFT | RIGHT
+// RPOPLPUSH source destination
+func handleLMove(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	from, to := internal.ListTail, internal.ListHead
+	if cmd.Name == internal.CommandLMove {
+		var fromOk, toOk bool
+		from, fromOk = parseListEnd(args[2])
+		to, toOk = parseListEnd(args[3])
+		if !fromOk || !toOk {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+	src, exists, err := store.GetList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Null{}, nil
+	}
+	element, err := moveListElement(store, args[0], src, args[1], from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &rtypes.BulkString{Value: element}, nil
+}
+
+// moveListElement pops an element from src, held by srcKey, and pushes it
+// to the list held by dstKey. Nothing is popped if dstKey holds another
+// type.
+func moveListElement(store *internal.Store, srcKey string, src *internal.List, dstKey string,
+	from internal.ListEnd, to internal.ListEnd) ([]byte, error) {
+	dst, exists, err := store.GetList(dstKey)
+	if err != nil {
+		return nil, err
+	}
+	element, _ := src.Pop(from)
+	if !exists {
+		dst = internal.NewList()
+		store.SetValue(dstKey, dst)
+	}
+	// When both keys are the same, the list is not empty again until the
+	// element is pushed back
+	dst.Push(element, to)
+	if src.Len() == 0 {
+		store.Delete(srcKey)
+	}
+	return element, nil
+}
+
+// BLPOP key [key ...] timeout
+// BRPOP key [key ...] timeout
+func handleBPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	timeout, errReply := parseTimeout(args[len(args)-1])
+	if errReply != nil {
+		return errReply, nil
+	}
+	end := internal.ListTail
+	if cmd.Name == internal.CommandBLPop {
+		end = internal.ListHead
+	}
+	return serveOrBlock(args[:len(args)-1], internal.TypeList, timeout, func(key string) (rtypes.RespDataType, error) {
+		list, exists, err := store.GetList(key)
+		if err != nil || !exists {
+			return nil, err
+		}
+		element := popListElements(store, key, list, end, 1)[0]
+		return &rtypes.Array{Elements: []rtypes.RespDataType{
+			rtypes.NewBulkString(key),
+			&rtypes.BulkString{Value: element},
+		}}, nil
+	})
+}
+
+// BLMPOP timeout numkeys key [key ...] LEFT | RIGHT [COUNT count]
+func handleBLMPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	timeout, errReply := parseTimeout(args[0])
+	if errReply != nil {
+		return errReply, nil
+	}
+	keys, end, count, errReply := parseMPopArgs(args[1:], parseListEnd)
+	if errReply != nil {
+		return errReply, nil
+	}
+	return serveOrBlock(keys, internal.TypeList, timeout, func(key string) (rtypes.RespDataType, error) {
+		list, exists, err := store.GetList(key)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return &rtypes.Array{Elements: []rtypes.RespDataType{
+			rtypes.NewBulkString(key),
+			bulkStringArray(popListElements(store, key, list, end, count)),
+		}}, nil
+	})
+}
+
+// BLMOVE source destination LEFT | RIGHT LEFT | RIGHT timeout
+// BRPOPLPUSH source destination timeout
+func handleBLMove(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	from, to := internal.ListTail, internal.ListHead
+	if cmd.Name == internal.CommandBLMove {
+		var fromOk, toOk bool
+		from, fromOk = parseListEnd(args[2])
+		to, toOk = parseListEnd(args[3])
+		if !fromOk || !toOk {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+	timeout, errReply := parseTimeout(args[len(args)-1])
+	if errReply != nil {
+		return errReply, nil
+	}
+	src, dst := args[0], args[1]
+	return serveOrBlock([]string{src}, internal.TypeList, timeout, func(key string) (rtypes.RespDataType, error) {
+		list, exists, err := store.GetList(key)
+		if err != nil || !exists {
+			return nil, err
+		}
+		element, err := moveListElement(store, key, list, dst, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.BulkString{Value: element}, nil
+	})
+}
+
+func parseListEnd(str string) (internal.ListEnd, bool) {
+	switch strings.ToLower(str) {
+	case "left":
+		return internal.ListHead, true
+	case "right":
+		return internal.ListTail, true
+	default:
+		return 0, false
+	}
+}
+
+// clampIndex converts an index to an int, clamping the values that do not
+// fit, which are out of range of any list anyway.
+func clampIndex(index int64) int {
+	return int(max(min(index, math.MaxInt32), math.MinInt32))
+}
+
+func bulkStringArray(values [][]byte) rtypes.RespDataType {
+	elements := make([]rtypes.RespDataType, len(values))
+	for i, value := range values {
+		elements[i] = &rtypes.BulkString{Value: value}
+	}
+	return &rtypes.Array{Elements: elements}
+}
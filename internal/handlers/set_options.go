@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// setOptions holds the parsed form of
+// SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]
+type setOptions struct {
+	nx       bool
+	xx       bool
+	get      bool
+	keepTTL  bool
+	deadline time.Time // zero if no expiry was requested
+}
+
+// parseSetOptions parses the arguments following the key and the value.
+// On failure the returned error is the reply to be sent to the client.
+func parseSetOptions(args []rtypes.RespDataType, now time.Time) (*setOptions, *rtypes.SimpleError) {
+	opts := &setOptions{}
+	expireOption := ""
+	var expireArg rtypes.RespDataType
+	for i := 0; i < len(args); i++ {
+		option, err := getString(args[i])
+		if err != nil {
+			return nil, rtypes.NewSimpleError(errSyntax)
+		}
+		option = strings.ToLower(option)
+		hasNext := i+1 < len(args)
+		switch {
+		case option == "nx" && !opts.xx:
+			opts.nx = true
+		case option == "xx" && !opts.nx:
+			opts.xx = true
+		case option == "get":
+			opts.get = true
+		case option == "keepttl" && expireOption == "":
+			opts.keepTTL = true
+		case (option == "ex" || option == "px" || option == "exat" || option == "pxat") &&
+			!opts.keepTTL && expireOption == "" && hasNext:
+			expireOption = option
+			i++
+			expireArg = args[i]
+		default:
+			return nil, rtypes.NewSimpleError(errSyntax)
+		}
+	}
+	if expireOption == "" {
+		return opts, nil
+	}
+
+	deadline, errReply := parseDeadline(internal.CommandSet, expireOption, expireArg, now)
+	if errReply != nil {
+		return nil, errReply
+	}
+	opts.deadline = deadline
+	return opts, nil
+}
+
+// parseDeadline converts the argument of one of the EX, PX, EXAT and PXAT
+// options of cmdName to an absolute deadline.
+func parseDeadline(cmdName string, option string, arg rtypes.RespDataType, now time.Time) (time.Time, *rtypes.SimpleError) {
+	invalidExpireTime := rtypes.NewSimpleError(fmt.Sprintf("ERR invalid expire time in '%s' command", cmdName))
+	str, err := getString(arg)
+	if err != nil {
+		return time.Time{}, rtypes.NewSimpleError(errNotAnInteger)
+	}
+	value, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return time.Time{}, rtypes.NewSimpleError(errNotAnInteger)
+	}
+	if value <= 0 {
+		return time.Time{}, invalidExpireTime
+	}
+
+	millis := value
+	if option == "ex" || option == "exat" {
+		if value > math.MaxInt64/1000 {
+			return time.Time{}, invalidExpireTime
+		}
+		millis = value * 1000
+	}
+	if option == "ex" || option == "px" {
+		nowMillis := now.UnixMilli()
+		if millis > math.MaxInt64-nowMillis {
+			return time.Time{}, invalidExpireTime
+		}
+		millis += nowMillis
+	}
+	return time.UnixMilli(millis), nil
+}
@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/glob"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// SADD key member [member ...]
+func handleSAdd(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	set, exists, err := store.GetSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		set = store.NewSet()
+		store.SetValue(args[0], set)
+	}
+	added := 0
+	for _, member := range args[1:] {
+		if set.Add(member) {
+			added++
+		}
+	}
+	return &rtypes.Int{Value: added}, nil
+}
+
+// SREM key member [member ...]
+func handleSRem(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	set, exists, err := store.GetSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	removed := 0
+	for _, member := range args[1:] {
+		if set.Remove(member) {
+			removed++
+		}
+	}
+	if set.Len() == 0 {
+		store.Delete(args[0])
+	}
+	return &rtypes.Int{Value: removed}, nil
+}
+
+// SISMEMBER key member
+// SMISMEMBER key member [member ...]
+func handleSIsMember(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	set, exists, err := store.GetSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]rtypes.RespDataType, 0, len(args)-1)
+	for _, member := range args[1:] {
+		isMember := 0
+		if exists && set.Contains(member) {
+			isMember = 1
+		}
+		elements = append(elements, &rtypes.Int{Value: isMember})
+	}
+	if cmd.Name == internal.CommandSIsMember {
+		return elements[0], nil
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// SMEMBERS key
+func handleSMembers(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	set, exists, err := store.GetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return setReply(nil), nil
+	}
+	return setReply(set.Members()), nil
+}
+
+// SCARD key
+func handleSCard(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	set, exists, err := store.GetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	return &rtypes.Int{Value: set.Len()}, nil
+}
+
+// SPOP key [count]
+func handleSPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	if len(args) > 2 {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	count := int64(-1)
+	if len(args) == 2 {
+		var ok bool
+		count, ok = parseInteger(args[1])
+		if !ok || count < 0 {
+			return rtypes.NewSimpleError("ERR value is out of range, must be positive"), nil
+		}
+	}
+	set, exists, err := store.GetSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if count == -1 {
+		if !exists {
+			return &rtypes.Null{}, nil
+		}
+		member := set.Pop()
+		if set.Len() == 0 {
+			store.Delete(args[0])
+		}
+		return rtypes.NewBulkString(member), nil
+	}
+	if !exists || count == 0 {
+		return setReply(nil), nil
+	}
+	if count >= int64(set.Len()) {
+		store.Delete(args[0])
+		return setReply(set.Members()), nil
+	}
+	members := make([]string, count)
+	for i := range members {
+		members[i] = set.Pop()
+	}
+	return setReply(members), nil
+}
+
+// SRANDMEMBER key [count]
+func handleSRandMember(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	if len(args) > 2 {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	set, exists, err := store.GetSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		if !exists {
+			return &rtypes.Null{}, nil
+		}
+		return rtypes.NewBulkString(set.Random()), nil
+	}
+
+	count, ok := parseInteger(args[1])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	if count == math.MinInt64 {
+		return rtypes.NewSimpleError(fmt.Sprintf("ERR value is out of range, value must between %d and %d",
+			-math.MaxInt64, math.MaxInt64)), nil
+	}
+	if !exists {
+		return &rtypes.Array{Elements: []rtypes.RespDataType{}}, nil
+	}
+	return bulkStrings(randomSetMembers(set, count)), nil
+}
+
+// randomSetMembers picks count distinct members of the set at random, or
+// -count members that may repeat if count is negative, the way SRANDMEMBER
+// does.
+func randomSetMembers(set *internal.Set, count int64) []string {
+	switch {
+	case count < 0:
+		members := make([]string, -count)
+		for i := range members {
+			members[i] = set.Random()
+		}
+		return members
+	case count >= int64(set.Len()):
+		return set.Members()
+	case count*3 > int64(set.Len()):
+		// Picking at random would keep finding members already picked, so
+		// shuffle all of them instead, like Redis does
+		members := set.Members()
+		rand.Shuffle(len(members), func(i, j int) {
+			members[i], members[j] = members[j], members[i]
+		})
+		return members[:count]
+	default:
+		members := make([]string, 0, count)
+		picked := make(map[string]bool, count)
+		for int64(len(members)) < count {
+			member := set.Random()
+			if !picked[member] {
+				picked[member] = true
+				members = append(members, member)
+			}
+		}
+		return members
+	}
+}
+
+// SMOVE source destination member
+func handleSMove(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	src, dst, member := args[0], args[1], args[2]
+	srcSet, exists, err := store.GetSet(src)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	dstSet, dstExists, err := store.GetSet(dst)
+	if err != nil {
+		return nil, err
+	}
+	if src == dst {
+		if srcSet.Contains(member) {
+			return &rtypes.Int{Value: 1}, nil
+		}
+		return &rtypes.Int{Value: 0}, nil
+	}
+	if !srcSet.Remove(member) {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	if srcSet.Len() == 0 {
+		store.Delete(src)
+	}
+	if !dstExists {
+		dstSet = store.NewSet()
+		store.SetValue(dst, dstSet)
+	}
+	dstSet.Add(member)
+	return &rtypes.Int{Value: 1}, nil
+}
+
+// SINTER key [key ...]
+// SUNION key [key ...]
+// SDIFF key [key ...]
+func handleSetOperation(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keys, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	result, err := combineSets(store, cmd.Name, keys)
+	if err != nil {
+		return nil, err
+	}
+	return setReply(result.Members()), nil
+}
+
+// SINTERSTORE destination key [key ...]
+// SUNIONSTORE destination key [key ...]
+// SDIFFSTORE destination key [key ...]
+func handleSetOperationStore(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	result, err := combineSets(store, storeOperations[cmd.Name], args[1:])
+	if err != nil {
+		return nil, err
+	}
+	// Like Redis, an empty result deletes the destination rather than
+	// storing an empty set
+	if result.Len() == 0 {
+		store.Delete(args[0])
+	} else {
+		store.SetValue(args[0], result)
+	}
+	return &rtypes.Int{Value: result.Len()}, nil
+}
+
+// storeOperations maps each of the STORE variants to the command whose result
+// it stores.
+var storeOperations = map[string]string{
+	internal.CommandSInterStore: internal.CommandSInter,
+	internal.CommandSUnionStore: internal.CommandSUnion,
+	internal.CommandSDiffStore:  internal.CommandSDiff,
+}
+
+// combineSets returns the intersection, union or difference of the sets held
+// by the keys, as named by the command. A key that does not exist counts as
+// an empty set.
+func combineSets(store *internal.Store, operation string, keys []string) (*internal.Set, error) {
+	sets, err := lookupSets(store, keys)
+	if err != nil {
+		return nil, err
+	}
+	result := store.NewSet()
+	switch operation {
+	case internal.CommandSInter:
+		if slices.Contains(sets, nil) {
+			return result, nil
+		}
+		// Check the members of the smallest set against the others
+		slices.SortFunc(sets, func(a, b *internal.Set) int { return a.Len() - b.Len() })
+		sets[0].ForEach(func(member string) {
+			if inAllSets(sets[1:], member) {
+				result.Add(member)
+			}
+		})
+	case internal.CommandSUnion:
+		for _, set := range sets {
+			if set != nil {
+				set.ForEach(func(member string) { result.Add(member) })
+			}
+		}
+	case internal.CommandSDiff:
+		if sets[0] == nil {
+			return result, nil
+		}
+		sets[0].ForEach(func(member string) {
+			if !inAnySet(sets[1:], member) {
+				result.Add(member)
+			}
+		})
+	}
+	return result, nil
+}
+
+// SINTERCARD numkeys key [key ...] [LIMIT limit]
+func handleSInterCard(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	numKeys, ok := parseInteger(args[0])
+	if !ok || numKeys <= 0 {
+		return rtypes.NewSimpleError("ERR numkeys should be greater than 0"), nil
+	}
+	if numKeys > int64(len(args)-1) {
+		return rtypes.NewSimpleError("ERR Number of keys can't be greater than number of args"), nil
+	}
+	keys := args[1 : numKeys+1]
+	limit := int64(0)
+	for i := int(numKeys) + 1; i < len(args); i++ {
+		if !strings.EqualFold(args[i], "limit") || i+1 == len(args) {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+		i++
+		limit, ok = parseInteger(args[i])
+		if !ok || limit < 0 {
+			return rtypes.NewSimpleError("ERR LIMIT can't be negative"), nil
+		}
+	}
+
+	sets, err := lookupSets(store, keys)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(sets, nil) {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	slices.SortFunc(sets, func(a, b *internal.Set) int { return a.Len() - b.Len() })
+	cardinality := 0
+	for _, member := range sets[0].Members() {
+		if inAllSets(sets[1:], member) {
+			cardinality++
+			if int64(cardinality) == limit {
+				break
+			}
+		}
+	}
+	return &rtypes.Int{Value: cardinality}, nil
+}
+
+// lookupSets returns the sets held by the keys, with nil for the keys that do
+// not exist. Fails if any of the keys holds a value of another type.
+func lookupSets(store *internal.Store, keys []string) ([]*internal.Set, error) {
+	sets := make([]*internal.Set, len(keys))
+	for i, key := range keys {
+		set, exists, err := store.GetSet(key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			sets[i] = set
+		}
+	}
+	return sets, nil
+}
+
+func inAllSets(sets []*internal.Set, member string) bool {
+	for _, set := range sets {
+		if !set.Contains(member) {
+			return false
+		}
+	}
+	return true
+}
+
+func inAnySet(sets []*internal.Set, member string) bool {
+	for _, set := range sets {
+		if set != nil && set.Contains(member) {
+			return true
+		}
+	}
+	return false
+}
+
+// SSCAN key cursor [MATCH pattern] [COUNT count]
+func handleSScan(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError("ERR invalid cursor"), nil
+	}
+	pattern, count := "*", 10
+	for i := 2; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+		switch strings.ToLower(args[i]) {
+		case "match":
+			pattern = args[i+1]
+		case "count":
+			count, err = strconv.Atoi(args[i+1])
+			if err != nil {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			if count < 1 {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	set, exists, err := store.GetSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := []rtypes.RespDataType{}
+	if exists {
+		cursor = set.Scan(cursor, count, func(member string) {
+			if pattern == "*" || glob.Match(pattern, member) {
+				elements = append(elements, rtypes.NewBulkString(member))
+			}
+		})
+	} else {
+		cursor = 0
+	}
+	return &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString(strconv.FormatUint(cursor, 10)),
+		&rtypes.Array{Elements: elements},
+	}}, nil
+}
+
+// setReply replies with the members as a set, which RESP2 clients receive as
+// an array.
+func setReply(members []string) *rtypes.Set {
+	elements := make([]rtypes.RespDataType, len(members))
+	for i, member := range members {
+		elements[i] = rtypes.NewBulkString(member)
+	}
+	return &rtypes.Set{Elements: elements}
+}
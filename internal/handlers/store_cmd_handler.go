@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/ram-the-coder/redisgo/internal"
@@ -8,50 +9,92 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func GetResponseForStoreCommand(store *internal.Store) func(*internal.Command) (rtypes.RespDataType, error) {
+func GetResponseForStoreCommand(store *internal.Store, blocked *BlockedClients) func(*internal.Command) (rtypes.RespDataType, error) {
 	return func(cmd *internal.Command) (rtypes.RespDataType, error) {
-		switch cmd.Name {
-		case internal.CommandSet:
-			keyStr, err := getString(cmd.Arguments[0])
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse key")
-			}
-			valueStr, err := getString(cmd.Arguments[1])
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse value")
-
-			}
-			store.Set(keyStr, valueStr)
-			return rtypes.NewSimpleString("OK"), nil
-
-		case internal.CommandGet:
-			keyStr, err := getString(cmd.Arguments[0])
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse key")
-			}
-
-			value, ok, err := store.Get(keyStr)
-			log.Trace().Msgf("Got value: %s", value)
-			if err != nil {
-				return nil, err
-			}
-			if !ok {
-				return &rtypes.Null{}, nil
-			}
-			return rtypes.NewBulkString(string(value)), nil
-		default:
-			log.Error().Msgf("unknown store command: %s", cmd.Name)
-			return rtypes.NewSimpleError("ERR unknown command"), nil
+		spec, errReply := validate(cmd)
+		if errReply != nil {
+			return errReply, nil
 		}
+		response, err := run(store, spec, cmd)
+		var req *blockRequest
+		if errors.As(err, &req) {
+			blocked.block(cmd, req)
+			return nil, errNoReply
+		}
+		// Only a command that ran can have given the blocked clients
+		// something to be served with
+		blocked.afterCommand(store, cmd)
+		return response, err
 	}
 }
 
-func getString(rdt rtypes.RespDataType) (string, error) {
-	if key, ok := rdt.(*rtypes.BulkString); ok {
-		return string(key.Value), nil
-	} else if key, ok := rdt.(*rtypes.SimpleString); ok {
-		return string(key.Value), nil
+// SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]
+func handleSet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keyStr, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	value, err := getBytes(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+
+	}
+	opts, errReply := parseSetOptions(cmd.Arguments[2:], store.Now())
+	if errReply != nil {
+		return errReply, nil
+	}
+
+	// Only SET with GET cares about the type of the value it replaces
+	exists := store.Exists(keyStr)
+	var reply rtypes.RespDataType = rtypes.NewSimpleString("OK")
+	if opts.get {
+		oldValue, _, err := store.Get(keyStr)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			reply = &rtypes.BulkString{Value: oldValue}
+		} else {
+			reply = &rtypes.Null{}
+		}
+	}
+	if (opts.nx && exists) || (opts.xx && !exists) {
+		if opts.get {
+			return reply, nil
+		}
+		return &rtypes.Null{}, nil
+	}
+
+	if opts.keepTTL {
+		store.SetKeepTTL(keyStr, value)
 	} else {
-		return "", fmt.Errorf("failed to get string fromL %s", rdt)
+		store.Set(keyStr, value)
+	}
+	if !opts.deadline.IsZero() {
+		store.SetExpiry(keyStr, opts.deadline)
+	}
+	return reply, nil
+}
+
+// GET key
+func handleGet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keyStr, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
 	}
+
+	value, ok, err := store.Get(keyStr)
+	log.Trace().Msgf("Got value: %s", value)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &rtypes.Null{}, nil
+	}
+	return &rtypes.BulkString{Value: value}, nil
+}
+
+// DBSIZE
+func handleDbSize(store *internal.Store, _ *internal.Command) (rtypes.RespDataType, error) {
+	return &rtypes.Int{Value: store.KeyCount()}, nil
 }
@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+const errStringTooLong = "ERR string exceeds maximum allowed size (proto-max-bulk-len)"
+
+// APPEND key value
+func handleAppend(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	suffix, err := getBytes(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+	}
+	value, exists, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		store.Set(key, suffix)
+		return &rtypes.Int{Value: len(suffix)}, nil
+	}
+	if len(value)+len(suffix) > store.MaxStringLength() {
+		return rtypes.NewSimpleError(errStringTooLong), nil
+	}
+	// Appending never overwrites the bytes replies may still hold
+	value = append(value, suffix...)
+	store.SetKeepTTL(key, value)
+	return &rtypes.Int{Value: len(value)}, nil
+}
+
+// STRLEN key
+func handleStrLen(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	value, _, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return &rtypes.Int{Value: len(value)}, nil
+}
+
+// GETRANGE key start end
+func handleGetRange(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	start, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	end, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	value, _, err := store.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Negative offsets count back from the end of the string, and the range
+	// is clamped to the string
+	length := int64(len(value))
+	if start < 0 && end < 0 && start > end {
+		return rtypes.NewBulkString(""), nil
+	}
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if end < 0 {
+		end = max(length+end, 0)
+	}
+	end = min(end, length-1)
+	if start > end || length == 0 {
+		return rtypes.NewBulkString(""), nil
+	}
+	return &rtypes.BulkString{Value: value[start : end+1]}, nil
+}
+
+// SETRANGE key offset value
+func handleSetRange(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	offsetStr, err := getString(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse offset")
+	}
+	patch, err := getBytes(cmd.Arguments[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+	}
+	offset, err := strconv.ParseInt(offsetStr, 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	if offset < 0 {
+		return rtypes.NewSimpleError("ERR offset is out of range"), nil
+	}
+
+	value, exists, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	// Setting nothing does not create the key nor pad it
+	if len(patch) == 0 {
+		return &rtypes.Int{Value: len(value)}, nil
+	}
+	if offset+int64(len(patch)) > int64(store.MaxStringLength()) {
+		return rtypes.NewSimpleError(errStringTooLong), nil
+	}
+
+	end := int(offset) + len(patch)
+	var updated []byte
+	if int(offset) < len(value) {
+		// The patch overwrites existing bytes, so work on a copy
+		updated = make([]byte, max(len(value), end))
+		copy(updated, value)
+	} else {
+		// Zero pad the value up to the offset
+		updated = append(value, make([]byte, end-len(value))...)
+	}
+	copy(updated[offset:], patch)
+	if exists {
+		store.SetKeepTTL(key, updated)
+	} else {
+		store.Set(key, updated)
+	}
+	return &rtypes.Int{Value: len(updated)}, nil
+}
+
+// GETDEL key
+func handleGetDel(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	value, exists, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Null{}, nil
+	}
+	store.Delete(key)
+	return &rtypes.BulkString{Value: value}, nil
+}
+
+// GETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+func handleGetEx(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	expireOption := ""
+	var expireArg rtypes.RespDataType
+	persist := false
+	for i := 1; i < len(cmd.Arguments); i++ {
+		option, err := getString(cmd.Arguments[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse option")
+		}
+		option = strings.ToLower(option)
+		switch {
+		case option == "persist" && expireOption == "" && !persist:
+			persist = true
+		case (option == "ex" || option == "px" || option == "exat" || option == "pxat") &&
+			expireOption == "" && !persist && i+1 < len(cmd.Arguments):
+			expireOption = option
+			i++
+			expireArg = cmd.Arguments[i]
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	var deadline time.Time
+	if expireOption != "" {
+		var errReply *rtypes.SimpleError
+		deadline, errReply = parseDeadline(cmd.Name, expireOption, expireArg, store.Now())
+		if errReply != nil {
+			return errReply, nil
+		}
+	}
+
+	value, exists, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Null{}, nil
+	}
+	switch {
+	case persist:
+		store.Persist(key)
+	case expireOption != "" && !deadline.After(store.Now()):
+		// A deadline in the past deletes the key right away
+		store.Delete(key)
+	case expireOption != "":
+		store.SetExpiry(key, deadline)
+	}
+	return &rtypes.BulkString{Value: value}, nil
+}
+
+// GETSET key value
+func handleGetSet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	value, err := getBytes(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+	}
+	oldValue, exists, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	store.Set(key, value)
+	if !exists {
+		return &rtypes.Null{}, nil
+	}
+	return &rtypes.BulkString{Value: oldValue}, nil
+}
+
+// SETNX key value
+func handleSetNX(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	value, err := getBytes(cmd.Arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+	}
+	if store.Exists(key) {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	store.Set(key, value)
+	return &rtypes.Int{Value: 1}, nil
+}
+
+// MGET key [key ...]
+func handleMGet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	keys, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keys")
+	}
+	values := make([]rtypes.RespDataType, len(keys))
+	for i, key := range keys {
+		value, exists, err := store.Get(key)
+		if err != nil || !exists {
+			values[i] = &rtypes.Null{}
+			continue
+		}
+		values[i] = &rtypes.BulkString{Value: value}
+	}
+	return &rtypes.Array{Elements: values}, nil
+}
+
+// MSET key value [key value ...]
+// MSETNX key value [key value ...]
+//
+// Both are atomic since no other command runs on the store until all the
+// keys are set.
+func handleMSet(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	if len(cmd.Arguments)%2 != 0 {
+		return wrongNumberOfArguments(cmd), nil
+	}
+	keys := make([]string, 0, len(cmd.Arguments)/2)
+	values := make([][]byte, 0, len(cmd.Arguments)/2)
+	for i := 0; i < len(cmd.Arguments); i += 2 {
+		key, err := getString(cmd.Arguments[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key")
+		}
+		value, err := getBytes(cmd.Arguments[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value")
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	nx := cmd.Name == internal.CommandMSetNX
+	if nx {
+		for _, key := range keys {
+			if store.Exists(key) {
+				return &rtypes.Int{Value: 0}, nil
+			}
+		}
+	}
+	// A key given several times ends up with its last value
+	for i, key := range keys {
+		store.Set(key, values[i])
+	}
+	if nx {
+		return &rtypes.Int{Value: 1}, nil
+	}
+	return rtypes.NewSimpleString("OK"), nil
+}
+
+// SETEX key seconds value
+// PSETEX key milliseconds value
+func handleSetEx(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	value, err := getBytes(cmd.Arguments[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse value")
+	}
+	option := "ex"
+	if cmd.Name == internal.CommandPSetEx {
+		option = "px"
+	}
+	deadline, errReply := parseDeadline(cmd.Name, option, cmd.Arguments[1], store.Now())
+	if errReply != nil {
+		return errReply, nil
+	}
+	store.Set(key, value)
+	store.SetExpiry(key, deadline)
+	return rtypes.NewSimpleString("OK"), nil
+}
+
+// LCS key1 key2 [LEN] [IDX] [MINMATCHLEN min-match-len] [WITHMATCHLEN]
+func handleLcs(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	var getLen, getIdx, withMatchLen bool
+	var minMatchLen int64
+	for i := 2; i < len(args); i++ {
+		switch {
+		case strings.EqualFold(args[i], "len"):
+			getLen = true
+		case strings.EqualFold(args[i], "idx"):
+			getIdx = true
+		case strings.EqualFold(args[i], "withmatchlen"):
+			withMatchLen = true
+		case strings.EqualFold(args[i], "minmatchlen") && i+1 < len(args):
+			minMatchLen, err = strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			minMatchLen = max(minMatchLen, 0)
+			i++
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+	if getLen && getIdx {
+		return rtypes.NewSimpleError("ERR If you want both the length and indexes, please just use IDX."), nil
+	}
+
+	// Missing keys are empty strings
+	a, _, err := store.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+	b, _, err := store.Get(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if (len(a)+1)*(len(b)+1)*4 > store.MaxStringLength() {
+		return rtypes.NewSimpleError("ERR Insufficient memory, transient memory for LCS exceeds proto-max-bulk-len"), nil
+	}
+
+	table := lcsTable(a, b)
+	lcsLen := table.at(len(a), len(b))
+	if getLen {
+		return &rtypes.Int{Value: lcsLen}, nil
+	}
+
+	// Walk the table back from the end of both strings, collecting the
+	// common subsequence and the ranges of contiguous matches
+	lcs := make([]byte, lcsLen)
+	matches := []rtypes.RespDataType{}
+	emitRange := func(aStart, aEnd, bStart, bEnd int) {
+		matchLen := aEnd - aStart + 1
+		if int64(matchLen) < minMatchLen {
+			return
+		}
+		match := []rtypes.RespDataType{
+			&rtypes.Array{Elements: []rtypes.RespDataType{&rtypes.Int{Value: aStart}, &rtypes.Int{Value: aEnd}}},
+			&rtypes.Array{Elements: []rtypes.RespDataType{&rtypes.Int{Value: bStart}, &rtypes.Int{Value: bEnd}}},
+		}
+		if withMatchLen {
+			match = append(match, &rtypes.Int{Value: matchLen})
+		}
+		matches = append(matches, &rtypes.Array{Elements: match})
+	}
+	inRange := false
+	var aStart, aEnd, bStart, bEnd int
+	i, j, idx := len(a), len(b), lcsLen
+	for i > 0 && j > 0 {
+		if a[i-1] == b[j-1] {
+			lcs[idx-1] = a[i-1]
+			if !inRange {
+				inRange = true
+				aStart, aEnd, bStart, bEnd = i-1, i-1, j-1, j-1
+			} else {
+				// Matches right after a match are always contiguous
+				aStart--
+				bStart--
+			}
+			idx--
+			i--
+			j--
+			// The range can't extend past the start of either string
+			if aStart == 0 || bStart == 0 {
+				emitRange(aStart, aEnd, bStart, bEnd)
+				inRange = false
+			}
+			continue
+		}
+		if table.at(i-1, j) > table.at(i, j-1) {
+			i--
+		} else {
+			j--
+		}
+		if inRange {
+			emitRange(aStart, aEnd, bStart, bEnd)
+			inRange = false
+		}
+	}
+
+	if !getIdx {
+		return &rtypes.BulkString{Value: lcs}, nil
+	}
+	return &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+		{rtypes.NewBulkString("matches"), &rtypes.Array{Elements: matches}},
+		{rtypes.NewBulkString("len"), &rtypes.Int{Value: lcsLen}},
+	}}, nil
+}
+
+// lcsMatrix holds at (i, j) the length of the longest common subsequence of
+// the first i bytes of a and the first j bytes of b.
+type lcsMatrix struct {
+	lengths []uint32
+	columns int
+}
+
+func (m *lcsMatrix) at(i int, j int) int {
+	return int(m.lengths[i*m.columns+j])
+}
+
+func lcsTable(a []byte, b []byte) *lcsMatrix {
+	m := &lcsMatrix{lengths: make([]uint32, (len(a)+1)*(len(b)+1)), columns: len(b) + 1}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				m.lengths[i*m.columns+j] = m.lengths[(i-1)*m.columns+j-1] + 1
+			} else {
+				m.lengths[i*m.columns+j] = max(m.lengths[(i-1)*m.columns+j], m.lengths[i*m.columns+j-1])
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,1010 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/glob"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+const (
+	errScoreNotAFloat    = "ERR value is not a valid float"
+	errScoreIsNaN        = "ERR resulting score is not a number (NaN)"
+	errMinOrMaxNotAFloat = "ERR min or max is not a float"
+	errInvalidLexRange   = "ERR min or max not valid string range item"
+)
+
+// zaddFlags are the options of ZADD, which ZINCRBY is a form of.
+type zaddFlags struct {
+	nx, xx, gt, lt, ch, incr bool
+}
+
+// ZADD key [NX | XX] [GT | LT] [CH] [INCR] score member [score member ...]
+func handleZAdd(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	var flags zaddFlags
+	i := 1
+options:
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			flags.nx = true
+		case "xx":
+			flags.xx = true
+		case "gt":
+			flags.gt = true
+		case "lt":
+			flags.lt = true
+		case "ch":
+			flags.ch = true
+		case "incr":
+			flags.incr = true
+		default:
+			break options
+		}
+	}
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	if flags.nx && flags.xx {
+		return rtypes.NewSimpleError("ERR XX and NX options at the same time are not compatible"), nil
+	}
+	if (flags.gt && flags.nx) || (flags.lt && flags.nx) || (flags.gt && flags.lt) {
+		return rtypes.NewSimpleError("ERR GT, LT, and/or NX options at the same time are not compatible"), nil
+	}
+	if flags.incr && len(pairs) > 2 {
+		return rtypes.NewSimpleError("ERR INCR option supports a single increment-element pair"), nil
+	}
+	scores := make([]float64, 0, len(pairs)/2)
+	members := make([]string, 0, len(pairs)/2)
+	for j := 0; j < len(pairs); j += 2 {
+		score, ok := parseFloat(pairs[j])
+		if !ok {
+			return rtypes.NewSimpleError(errScoreNotAFloat), nil
+		}
+		scores = append(scores, score)
+		members = append(members, pairs[j+1])
+	}
+	return zadd(store, args[0], flags, scores, members)
+}
+
+// ZINCRBY key increment member
+func handleZIncrBy(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	increment, ok := parseFloat(args[1])
+	if !ok {
+		return rtypes.NewSimpleError(errScoreNotAFloat), nil
+	}
+	return zadd(store, args[0], zaddFlags{incr: true}, []float64{increment}, []string{args[2]})
+}
+
+// zadd adds the members with the scores to the sorted set held by the key,
+// or updates their scores, the way ZADD does with the flags.
+func zadd(store *internal.Store, key string, flags zaddFlags, scores []float64, members []string) (rtypes.RespDataType, error) {
+	zset, exists, err := store.GetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if flags.xx {
+			if flags.incr {
+				return &rtypes.Null{}, nil
+			}
+			return &rtypes.Int{Value: 0}, nil
+		}
+		zset = store.NewZSet()
+		store.SetValue(key, zset)
+	}
+
+	added, updated := 0, 0
+	var incrReply rtypes.RespDataType = &rtypes.Null{}
+	for i, member := range members {
+		score := scores[i]
+		current, ok := zset.Score(member)
+		if !ok {
+			if flags.xx {
+				continue
+			}
+			zset.Set(member, score)
+			added++
+			incrReply = &rtypes.Double{Value: score}
+			continue
+		}
+		if flags.nx {
+			continue
+		}
+		if flags.incr {
+			score += current
+			if math.IsNaN(score) {
+				return rtypes.NewSimpleError(errScoreIsNaN), nil
+			}
+		}
+		if (flags.gt && score <= current) || (flags.lt && score >= current) {
+			continue
+		}
+		if score != current {
+			zset.Set(member, score)
+			updated++
+		}
+		incrReply = &rtypes.Double{Value: score}
+	}
+	if flags.incr {
+		return incrReply, nil
+	}
+	if flags.ch {
+		added += updated
+	}
+	return &rtypes.Int{Value: added}, nil
+}
+
+// ZCARD key
+func handleZCard(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	key, err := getString(cmd.Arguments[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key")
+	}
+	zset, exists, err := store.GetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	return &rtypes.Int{Value: zset.Len()}, nil
+}
+
+// ZSCORE key member
+// ZMSCORE key member [member ...]
+func handleZScore(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]rtypes.RespDataType, 0, len(args)-1)
+	for _, member := range args[1:] {
+		var score float64
+		ok := false
+		if exists {
+			score, ok = zset.Score(member)
+		}
+		if ok {
+			elements = append(elements, &rtypes.Double{Value: score})
+		} else {
+			elements = append(elements, &rtypes.Null{})
+		}
+	}
+	if cmd.Name == internal.CommandZScore {
+		return elements[0], nil
+	}
+	return &rtypes.Array{Elements: elements}, nil
+}
+
+// ZREM key member [member ...]
+func handleZRem(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	removed := 0
+	for _, member := range args[1:] {
+		if zset.Remove(member) {
+			removed++
+		}
+	}
+	if zset.Len() == 0 {
+		store.Delete(args[0])
+	}
+	return &rtypes.Int{Value: removed}, nil
+}
+
+// ZRANK key member [WITHSCORE]
+// ZREVRANK key member [WITHSCORE]
+func handleZRank(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	if len(args) > 3 || (len(args) == 3 && !strings.EqualFold(args[2], "withscore")) {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	withScore := len(args) == 3
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	rank, ok := 0, false
+	if exists {
+		rank, ok = zset.Rank(args[1])
+	}
+	if !ok {
+		return &rtypes.Null{Array: withScore}, nil
+	}
+	if cmd.Name == internal.CommandZRevRank {
+		rank = zset.Len() - 1 - rank
+	}
+	if !withScore {
+		return &rtypes.Int{Value: rank}, nil
+	}
+	score, _ := zset.Score(args[1])
+	return &rtypes.Array{Elements: []rtypes.RespDataType{&rtypes.Int{Value: rank}, &rtypes.Double{Value: score}}}, nil
+}
+
+// ZCOUNT key min max
+// ZLEXCOUNT key min max
+func handleZCount(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	var scoreRange internal.ScoreRange
+	var lexRange internal.LexRange
+	var ok bool
+	if cmd.Name == internal.CommandZCount {
+		scoreRange, ok = parseScoreRange(args[1], args[2])
+		if !ok {
+			return rtypes.NewSimpleError(errMinOrMaxNotAFloat), nil
+		}
+	} else {
+		lexRange, ok = parseLexRange(args[1], args[2])
+		if !ok {
+			return rtypes.NewSimpleError(errInvalidLexRange), nil
+		}
+	}
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	if cmd.Name == internal.CommandZCount {
+		return &rtypes.Int{Value: zset.CountByScore(scoreRange)}, nil
+	}
+	return &rtypes.Int{Value: zset.CountByLex(lexRange)}, nil
+}
+
+// zrangeBy is the kind of range that ZRANGE and its older forms select.
+type zrangeBy int
+
+const (
+	zrangeByRank zrangeBy = iota
+	zrangeByScore
+	zrangeByLex
+)
+
+// zrangeQuery is a range asked for by ZRANGE or one of its older forms.
+type zrangeQuery struct {
+	by      zrangeBy
+	reverse bool
+	// start and stop for ranks, otherwise the lowest and highest ends
+	min, max string
+	// LIMIT offset count, where a negative count selects all the entries
+	// past the offset
+	offset, count int64
+}
+
+// ZRANGE key start stop [BYSCORE | BYLEX] [REV] [LIMIT offset count] [WITHSCORES]
+// ZRANGESTORE dst src min max [BYSCORE | BYLEX] [REV] [LIMIT offset count]
+// ZREVRANGE key start stop [WITHSCORES]
+// ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count]
+// ZREVRANGEBYSCORE key max min [WITHSCORES] [LIMIT offset count]
+// ZRANGEBYLEX key min max [LIMIT offset count]
+// ZREVRANGEBYLEX key max min [LIMIT offset count]
+func handleZRange(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	dst := ""
+	if cmd.Name == internal.CommandZRangeStore {
+		dst, args = args[0], args[1:]
+	}
+	query := zrangeQuery{min: args[1], max: args[2], count: -1}
+	// The older forms fix what ZRANGE takes as options
+	fixed := true
+	switch cmd.Name {
+	case internal.CommandZRange, internal.CommandZRangeStore:
+		fixed = false
+	case internal.CommandZRevRange:
+		query.reverse = true
+	case internal.CommandZRangeByScore:
+		query.by = zrangeByScore
+	case internal.CommandZRevRangeByScore:
+		query.by, query.reverse = zrangeByScore, true
+	case internal.CommandZRangeByLex:
+		query.by = zrangeByLex
+	case internal.CommandZRevRangeByLex:
+		query.by, query.reverse = zrangeByLex, true
+	}
+	byGiven, revGiven := fixed, fixed
+	withScores, limited := false, false
+	for i := 3; i < len(args); i++ {
+		option := strings.ToLower(args[i])
+		switch {
+		case option == "withscores" && dst == "":
+			withScores = true
+		case option == "limit" && i+2 < len(args):
+			offset, offsetOk := parseInteger(args[i+1])
+			count, countOk := parseInteger(args[i+2])
+			if !offsetOk || !countOk {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			query.offset, query.count, limited = offset, count, true
+			i += 2
+		case option == "rev" && !revGiven:
+			query.reverse, revGiven = true, true
+		case option == "byscore" && !byGiven:
+			query.by, byGiven = zrangeByScore, true
+		case option == "bylex" && !byGiven:
+			query.by, byGiven = zrangeByLex, true
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+	if limited && query.by == zrangeByRank {
+		return rtypes.NewSimpleError("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"), nil
+	}
+	if withScores && query.by == zrangeByLex {
+		return rtypes.NewSimpleError("ERR syntax error, WITHSCORES not supported in combination with BYLEX"), nil
+	}
+	// Reversed score and lex ranges are given from the highest end
+	if query.reverse && query.by != zrangeByRank {
+		query.min, query.max = query.max, query.min
+	}
+
+	entries, errReply, err := zrange(store, args[0], query)
+	if errReply != nil || err != nil {
+		return errReply, err
+	}
+	if dst != "" {
+		return &rtypes.Int{Value: storeZSetEntries(store, dst, entries)}, nil
+	}
+	return zsetEntriesReply(entries, withScores), nil
+}
+
+// zrange returns the entries of the sorted set held by the key that are
+// within the range. Returns an error reply if the ends of the range are not
+// valid.
+func zrange(store *internal.Store, key string, query zrangeQuery) ([]internal.ZSetEntry, rtypes.RespDataType, error) {
+	var start, stop int64
+	var scoreRange internal.ScoreRange
+	var lexRange internal.LexRange
+	switch query.by {
+	case zrangeByRank:
+		var startOk, stopOk bool
+		start, startOk = parseInteger(query.min)
+		stop, stopOk = parseInteger(query.max)
+		if !startOk || !stopOk {
+			return nil, rtypes.NewSimpleError(errNotAnInteger), nil
+		}
+	case zrangeByScore:
+		var ok bool
+		if scoreRange, ok = parseScoreRange(query.min, query.max); !ok {
+			return nil, rtypes.NewSimpleError(errMinOrMaxNotAFloat), nil
+		}
+	case zrangeByLex:
+		var ok bool
+		if lexRange, ok = parseLexRange(query.min, query.max); !ok {
+			return nil, rtypes.NewSimpleError(errInvalidLexRange), nil
+		}
+	}
+
+	zset, exists, err := store.GetZSet(key)
+	if err != nil || !exists || query.offset < 0 {
+		return nil, nil, err
+	}
+	offset := int(min(query.offset, math.MaxInt32))
+	count := int(max(min(query.count, math.MaxInt32), -1))
+	switch query.by {
+	case zrangeByScore:
+		return zset.RangeByScore(scoreRange, query.reverse, offset, count), nil, nil
+	case zrangeByLex:
+		return zset.RangeByLex(lexRange, query.reverse, offset, count), nil, nil
+	default:
+		return zset.RangeByRank(clampIndex(start), clampIndex(stop), query.reverse), nil, nil
+	}
+}
+
+// ZREMRANGEBYRANK key start stop
+// ZREMRANGEBYSCORE key min max
+// ZREMRANGEBYLEX key min max
+func handleZRemRange(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	query := zrangeQuery{min: args[1], max: args[2], count: -1}
+	switch cmd.Name {
+	case internal.CommandZRemRangeByScore:
+		query.by = zrangeByScore
+	case internal.CommandZRemRangeByLex:
+		query.by = zrangeByLex
+	}
+	entries, errReply, err := zrange(store, args[0], query)
+	if errReply != nil || err != nil {
+		return errReply, err
+	}
+	if len(entries) == 0 {
+		return &rtypes.Int{Value: 0}, nil
+	}
+	zset, _, _ := store.GetZSet(args[0])
+	for _, entry := range entries {
+		zset.Remove(entry.Member)
+	}
+	if zset.Len() == 0 {
+		store.Delete(args[0])
+	}
+	return &rtypes.Int{Value: len(entries)}, nil
+}
+
+// ZPOPMIN key [count]
+// ZPOPMAX key [count]
+func handleZPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	if len(args) > 2 {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	count := int64(1)
+	if len(args) == 2 {
+		var ok bool
+		count, ok = parseInteger(args[1])
+		if !ok || count < 0 {
+			return rtypes.NewSimpleError("ERR value is out of range, must be positive"), nil
+		}
+	}
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !exists || count == 0 {
+		return &rtypes.Array{Elements: []rtypes.RespDataType{}}, nil
+	}
+	entries := popZSetEntries(store, args[0], zset, cmd.Name == internal.CommandZPopMax, int(min(count, math.MaxInt32)))
+	// Without a count the entry is not nested in an array, not even for
+	// RESP3 clients
+	if len(args) == 1 {
+		return &rtypes.Array{Elements: zsetEntryElements(entries[0])}, nil
+	}
+	return zsetEntriesReply(entries, true), nil
+}
+
+// ZMPOP numkeys key [key ...] MIN | MAX [COUNT count]
+func handleZMPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	keys, highest, count, errReply := parseMPopArgs(args, parseZSetEnd)
+	if errReply != nil {
+		return errReply, nil
+	}
+	for _, key := range keys {
+		zset, exists, err := store.GetZSet(key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return zmpopReply(key, popZSetEntries(store, key, zset, highest, count)), nil
+		}
+	}
+	return &rtypes.Null{Array: true}, nil
+}
+
+// BZPOPMIN key [key ...] timeout
+// BZPOPMAX key [key ...] timeout
+func handleBZPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	timeout, errReply := parseTimeout(args[len(args)-1])
+	if errReply != nil {
+		return errReply, nil
+	}
+	highest := cmd.Name == internal.CommandBZPopMax
+	return serveOrBlock(args[:len(args)-1], internal.TypeZSet, timeout, func(key string) (rtypes.RespDataType, error) {
+		zset, exists, err := store.GetZSet(key)
+		if err != nil || !exists {
+			return nil, err
+		}
+		entry := popZSetEntries(store, key, zset, highest, 1)[0]
+		return &rtypes.Array{Elements: append([]rtypes.RespDataType{rtypes.NewBulkString(key)}, zsetEntryElements(entry)...)}, nil
+	})
+}
+
+// BZMPOP timeout numkeys key [key ...] MIN | MAX [COUNT count]
+func handleBZMPop(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	timeout, errReply := parseTimeout(args[0])
+	if errReply != nil {
+		return errReply, nil
+	}
+	keys, highest, count, errReply := parseMPopArgs(args[1:], parseZSetEnd)
+	if errReply != nil {
+		return errReply, nil
+	}
+	return serveOrBlock(keys, internal.TypeZSet, timeout, func(key string) (rtypes.RespDataType, error) {
+		zset, exists, err := store.GetZSet(key)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return zmpopReply(key, popZSetEntries(store, key, zset, highest, count)), nil
+	})
+}
+
+// parseZSetEnd parses the MIN or MAX of ZMPOP and BZMPOP, returning true for MAX.
+func parseZSetEnd(str string) (bool, bool) {
+	switch strings.ToLower(str) {
+	case "min":
+		return false, true
+	case "max":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// popZSetEntries pops up to count entries with the lowest scores from the
+// sorted set held by the key, or with the highest if highest is set, and
+// deletes the key once the sorted set is empty.
+func popZSetEntries(store *internal.Store, key string, zset *internal.ZSet, highest bool, count int) []internal.ZSetEntry {
+	entries := make([]internal.ZSetEntry, 0, min(count, zset.Len()))
+	for len(entries) < count && zset.Len() > 0 {
+		entries = append(entries, zset.Pop(highest))
+	}
+	if zset.Len() == 0 {
+		store.Delete(key)
+	}
+	return entries
+}
+
+// zmpopReply replies with the key and the entries popped from it, nested in
+// arrays even for RESP2 clients.
+func zmpopReply(key string, entries []internal.ZSetEntry) rtypes.RespDataType {
+	elements := make([]rtypes.RespDataType, len(entries))
+	for i, entry := range entries {
+		elements[i] = &rtypes.Array{Elements: zsetEntryElements(entry)}
+	}
+	return &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString(key), &rtypes.Array{Elements: elements},
+	}}
+}
+
+// ZRANDMEMBER key [count [WITHSCORES]]
+func handleZRandMember(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		if !exists {
+			return &rtypes.Null{}, nil
+		}
+		return rtypes.NewBulkString(zset.Random().Member), nil
+	}
+
+	count, ok := parseInteger(args[1])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	if len(args) > 3 || (len(args) == 3 && !strings.EqualFold(args[2], "withscores")) {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	withScores := len(args) == 3
+	if count == math.MinInt64 {
+		return rtypes.NewSimpleError(fmt.Sprintf("ERR value is out of range, value must between %d and %d",
+			-math.MaxInt64, math.MaxInt64)), nil
+	}
+	// The reply holds twice as many elements
+	if withScores && (count > math.MaxInt64/2 || count < -math.MaxInt64/2) {
+		return rtypes.NewSimpleError("ERR value is out of range"), nil
+	}
+	if !exists {
+		return &rtypes.Array{Elements: []rtypes.RespDataType{}}, nil
+	}
+	return zsetEntriesReply(randomZSetEntries(zset, count), withScores), nil
+}
+
+// randomZSetEntries picks count distinct entries of the sorted set at random,
+// or -count entries that may repeat if count is negative, the way
+// ZRANDMEMBER does.
+func randomZSetEntries(zset *internal.ZSet, count int64) []internal.ZSetEntry {
+	switch {
+	case count < 0:
+		entries := make([]internal.ZSetEntry, -count)
+		for i := range entries {
+			entries[i] = zset.Random()
+		}
+		return entries
+	case count >= int64(zset.Len()):
+		return zset.RangeByRank(0, -1, false)
+	case count*3 > int64(zset.Len()):
+		// Picking at random would keep finding entries already picked, so
+		// shuffle all of them instead, like Redis does
+		entries := zset.RangeByRank(0, -1, false)
+		rand.Shuffle(len(entries), func(i, j int) {
+			entries[i], entries[j] = entries[j], entries[i]
+		})
+		return entries[:count]
+	default:
+		entries := make([]internal.ZSetEntry, 0, count)
+		picked := make(map[string]bool, count)
+		for int64(len(entries)) < count {
+			entry := zset.Random()
+			if !picked[entry.Member] {
+				picked[entry.Member] = true
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+}
+
+// zsetStoreOperations maps each of the STORE variants to the command whose
+// result it stores.
+var zsetStoreOperations = map[string]string{
+	internal.CommandZUnionStore: internal.CommandZUnion,
+	internal.CommandZInterStore: internal.CommandZInter,
+	internal.CommandZDiffStore:  internal.CommandZDiff,
+}
+
+// ZUNION numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM | MIN | MAX] [WITHSCORES]
+// ZINTER numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM | MIN | MAX] [WITHSCORES]
+// ZDIFF numkeys key [key ...] [WITHSCORES]
+// ZUNIONSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM | MIN | MAX]
+// ZINTERSTORE destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM | MIN | MAX]
+// ZDIFFSTORE destination numkeys key [key ...]
+func handleZSetOperation(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	operation, dst := cmd.Name, ""
+	if storeOf, ok := zsetStoreOperations[cmd.Name]; ok {
+		operation, dst, args = storeOf, args[0], args[1:]
+	}
+	numKeys, ok := parseInteger(args[0])
+	if !ok {
+		return rtypes.NewSimpleError(errNotAnInteger), nil
+	}
+	if numKeys < 1 {
+		return rtypes.NewSimpleError(fmt.Sprintf("ERR at least 1 input key is needed for '%s' command", cmd.Name)), nil
+	}
+	if numKeys > int64(len(args)-1) {
+		return rtypes.NewSimpleError(errSyntax), nil
+	}
+	inputs := make([]zsetInput, numKeys)
+	for i := range inputs {
+		inputs[i].weight = 1
+	}
+	aggregate, withScores := "sum", false
+	for i := int(numKeys) + 1; i < len(args); i++ {
+		option := strings.ToLower(args[i])
+		switch {
+		case option == "weights" && operation != internal.CommandZDiff && i+len(inputs) < len(args):
+			for j := range inputs {
+				weight, ok := parseFloat(args[i+1+j])
+				if !ok {
+					return rtypes.NewSimpleError("ERR weight value is not a float"), nil
+				}
+				inputs[j].weight = weight
+			}
+			i += len(inputs)
+		case option == "aggregate" && operation != internal.CommandZDiff && i+1 < len(args):
+			i++
+			aggregate = strings.ToLower(args[i])
+			if aggregate != "sum" && aggregate != "min" && aggregate != "max" {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+		case option == "withscores" && dst == "":
+			withScores = true
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	for i, key := range args[1 : numKeys+1] {
+		value, exists := store.Lookup(key)
+		if !exists {
+			continue
+		}
+		switch v := value.(type) {
+		case *internal.ZSet:
+			inputs[i].zset = v
+		case *internal.Set:
+			inputs[i].set = v
+		default:
+			return nil, internal.ErrWrongType
+		}
+	}
+	result := combineZSets(store, operation, inputs, aggregate)
+	entries := result.RangeByRank(0, -1, false)
+	if dst != "" {
+		return &rtypes.Int{Value: storeZSetEntries(store, dst, entries)}, nil
+	}
+	return zsetEntriesReply(entries, withScores), nil
+}
+
+// zsetInput is a key given to ZUNION, ZINTER or ZDIFF. Like Redis, a key may
+// hold a set, whose members all score 1, and a key that does not exist counts
+// as empty.
+type zsetInput struct {
+	zset   *internal.ZSet
+	set    *internal.Set
+	weight float64
+}
+
+func (in zsetInput) len() int {
+	switch {
+	case in.zset != nil:
+		return in.zset.Len()
+	case in.set != nil:
+		return in.set.Len()
+	default:
+		return 0
+	}
+}
+
+func (in zsetInput) score(member string) (float64, bool) {
+	switch {
+	case in.zset != nil:
+		return in.zset.Score(member)
+	case in.set != nil:
+		return 1, in.set.Contains(member)
+	default:
+		return 0, false
+	}
+}
+
+func (in zsetInput) forEach(fn func(member string, score float64)) {
+	switch {
+	case in.zset != nil:
+		in.zset.ForEach(fn)
+	case in.set != nil:
+		in.set.ForEach(func(member string) { fn(member, 1) })
+	}
+}
+
+// weighted returns the score multiplied by the weight of the input, where
+// Redis takes the NaN of multiplying an infinite score by 0 as 0.
+func (in zsetInput) weighted(score float64) float64 {
+	score *= in.weight
+	if math.IsNaN(score) {
+		return 0
+	}
+	return score
+}
+
+// combineZSets returns the union, intersection or difference of the inputs,
+// as named by the command.
+func combineZSets(store *internal.Store, operation string, inputs []zsetInput, aggregate string) *internal.ZSet {
+	result := store.NewZSet()
+	switch operation {
+	case internal.CommandZUnion:
+		for _, in := range inputs {
+			in.forEach(func(member string, score float64) {
+				score = in.weighted(score)
+				if current, ok := result.Score(member); ok {
+					score = aggregateScores(aggregate, current, score)
+				}
+				result.Set(member, score)
+			})
+		}
+	case internal.CommandZInter:
+		// Check the members of the smallest input against the others
+		inputs = slices.Clone(inputs)
+		slices.SortStableFunc(inputs, func(a, b zsetInput) int { return a.len() - b.len() })
+		inputs[0].forEach(func(member string, score float64) {
+			score = inputs[0].weighted(score)
+			for _, other := range inputs[1:] {
+				otherScore, ok := other.score(member)
+				if !ok {
+					return
+				}
+				score = aggregateScores(aggregate, score, other.weighted(otherScore))
+			}
+			result.Set(member, score)
+		})
+	case internal.CommandZDiff:
+		inputs[0].forEach(func(member string, score float64) {
+			for _, other := range inputs[1:] {
+				if _, ok := other.score(member); ok {
+					return
+				}
+			}
+			result.Set(member, score)
+		})
+	}
+	return result
+}
+
+// aggregateScores combines the scores of a member found in several inputs,
+// as AGGREGATE asks for.
+func aggregateScores(aggregate string, a float64, b float64) float64 {
+	switch aggregate {
+	case "min":
+		return min(a, b)
+	case "max":
+		return max(a, b)
+	default:
+		// The sum of opposite infinities is NaN, which Redis takes as 0
+		if sum := a + b; !math.IsNaN(sum) {
+			return sum
+		}
+		return 0
+	}
+}
+
+// ZSCAN key cursor [MATCH pattern] [COUNT count] [NOSCORES]
+func handleZScan(store *internal.Store, cmd *internal.Command) (rtypes.RespDataType, error) {
+	args, err := getStrings(cmd.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments")
+	}
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return rtypes.NewSimpleError("ERR invalid cursor"), nil
+	}
+	pattern, count, noScores := "*", 10, false
+	for i := 2; i < len(args); i++ {
+		option := strings.ToLower(args[i])
+		if option == "noscores" {
+			noScores = true
+			continue
+		}
+		if i+1 == len(args) {
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+		i++
+		switch option {
+		case "match":
+			pattern = args[i]
+		case "count":
+			count, err = strconv.Atoi(args[i])
+			if err != nil {
+				return rtypes.NewSimpleError(errNotAnInteger), nil
+			}
+			if count < 1 {
+				return rtypes.NewSimpleError(errSyntax), nil
+			}
+		default:
+			return rtypes.NewSimpleError(errSyntax), nil
+		}
+	}
+
+	zset, exists, err := store.GetZSet(args[0])
+	if err != nil {
+		return nil, err
+	}
+	elements := []rtypes.RespDataType{}
+	if exists {
+		cursor = zset.Scan(cursor, count, func(member string, score float64) {
+			if pattern != "*" && !glob.Match(pattern, member) {
+				return
+			}
+			elements = append(elements, rtypes.NewBulkString(member))
+			if !noScores {
+				elements = append(elements, rtypes.NewBulkString(rtypes.FormatDouble(score)))
+			}
+		})
+	} else {
+		cursor = 0
+	}
+	return &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString(strconv.FormatUint(cursor, 10)),
+		&rtypes.Array{Elements: elements},
+	}}, nil
+}
+
+// parseScoreRange parses the ends of a range of scores, where a "(" prefix
+// makes an end exclusive.
+func parseScoreRange(lower string, upper string) (internal.ScoreRange, bool) {
+	var r internal.ScoreRange
+	var minOk, maxOk bool
+	r.Min, r.MinExclusive, minOk = parseScoreBound(lower)
+	r.Max, r.MaxExclusive, maxOk = parseScoreBound(upper)
+	return r, minOk && maxOk
+}
+
+func parseScoreBound(str string) (float64, bool, bool) {
+	exclusive := strings.HasPrefix(str, "(")
+	if exclusive {
+		str = str[1:]
+	}
+	score, ok := parseFloat(str)
+	return score, exclusive, ok
+}
+
+// parseLexRange parses the ends of a range of members, each of which is "-"
+// or "+" for the lowest and highest of all, or a member prefixed by "[" or
+// by "(" when the end is exclusive.
+func parseLexRange(lower string, upper string) (internal.LexRange, bool) {
+	var r internal.LexRange
+	var minOk, maxOk bool
+	r.Min, minOk = parseLexBound(lower)
+	r.Max, maxOk = parseLexBound(upper)
+	return r, minOk && maxOk
+}
+
+func parseLexBound(str string) (internal.LexBound, bool) {
+	switch {
+	case str == "-":
+		return internal.LexBound{Infinity: -1}, true
+	case str == "+":
+		return internal.LexBound{Infinity: 1}, true
+	case strings.HasPrefix(str, "["):
+		return internal.LexBound{Value: str[1:]}, true
+	case strings.HasPrefix(str, "("):
+		return internal.LexBound{Value: str[1:], Exclusive: true}, true
+	default:
+		return internal.LexBound{}, false
+	}
+}
+
+// storeZSetEntries stores the entries as a sorted set against the key,
+// whatever it held before, and returns how many there are. Like Redis, no
+// entries delete the key rather than storing an empty sorted set.
+func storeZSetEntries(store *internal.Store, key string, entries []internal.ZSetEntry) int {
+	if len(entries) == 0 {
+		store.Delete(key)
+		return 0
+	}
+	zset := store.NewZSet()
+	for _, entry := range entries {
+		zset.Set(entry.Member, entry.Score)
+	}
+	store.SetValue(key, zset)
+	return len(entries)
+}
+
+// zsetEntriesReply replies with the members of the entries, or with pairs of
+// members and scores, which RESP2 clients get flattened.
+func zsetEntriesReply(entries []internal.ZSetEntry, withScores bool) *rtypes.Array {
+	elements := make([]rtypes.RespDataType, len(entries))
+	for i, entry := range entries {
+		if withScores {
+			elements[i] = &rtypes.Array{Elements: zsetEntryElements(entry)}
+		} else {
+			elements[i] = rtypes.NewBulkString(entry.Member)
+		}
+	}
+	return &rtypes.Array{Elements: elements, Pairs: withScores}
+}
+
+func zsetEntryElements(entry internal.ZSetEntry) []rtypes.RespDataType {
+	return []rtypes.RespDataType{rtypes.NewBulkString(entry.Member), &rtypes.Double{Value: entry.Score}}
+}
@@ -0,0 +1,288 @@
+package internal
+
+import (
+	"container/heap"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Stale entries the heap of field deadlines of a hash may hold besides one
+// per field with a TTL, before it is rebuilt
+const hashStaleDeadlinesSlack = 16
+
+// Hash is the value of a hash key. Like the entries of lists, its values are
+// shared with replies, so a value is replaced rather than modified.
+type Hash struct {
+	// Fields in insertion order while the hash is small, the way Redis keeps
+	// them in a listpack. Unused once the hash is converted to table.
+	pairs []hashPair
+	// Redis converts a hash to a hashtable once it outgrows the listpack
+	// limits, and never converts it back. nil until then.
+	table *dict[[]byte]
+
+	// Deadlines of the fields that have a TTL, which Redis 7.4 added
+	expires map[string]time.Time
+	// Min-heap of the deadlines, so that the fields due are found without
+	// visiting the others. An entry is stale once the TTL of its field was
+	// changed or removed, and is skipped.
+	deadlines fieldDeadlines
+	// Redis moves a listpack to its listpackex encoding once a field gets a
+	// TTL
+	packedWithTTL bool
+
+	maxListpackEntries int
+	maxListpackValue   int
+}
+
+type hashPair struct {
+	field string
+	value []byte
+}
+
+func newHash(maxListpackEntries int, maxListpackValue int) *Hash {
+	return &Hash{maxListpackEntries: maxListpackEntries, maxListpackValue: maxListpackValue}
+}
+
+func (*Hash) Type() ValueType {
+	return TypeHash
+}
+
+func (h *Hash) Encoding() Encoding {
+	switch {
+	case h.table != nil:
+		return EncodingHashtable
+	case h.packedWithTTL:
+		return EncodingListpackEx
+	default:
+		return EncodingListpack
+	}
+}
+
+func (h *Hash) Len() int {
+	if h.table != nil {
+		return h.table.len()
+	}
+	return len(h.pairs)
+}
+
+func (h *Hash) Get(field string) ([]byte, bool) {
+	if h.table != nil {
+		return h.table.get(field)
+	}
+	if i := h.indexOf(field); i >= 0 {
+		return h.pairs[i].value, true
+	}
+	return nil, false
+}
+
+// Set stores the value against the field and clears any TTL the field had.
+// Returns true if the field was added rather than updated.
+func (h *Hash) Set(field string, value []byte) bool {
+	delete(h.expires, field)
+	return h.SetKeepTTL(field, value)
+}
+
+// SetKeepTTL stores the value against the field, retaining its current TTL.
+// Returns true if the field was added rather than updated.
+func (h *Hash) SetKeepTTL(field string, value []byte) bool {
+	if h.table == nil && (len(field) > h.maxListpackValue || len(value) > h.maxListpackValue) {
+		h.convert()
+	}
+	if h.table != nil {
+		return h.table.set(field, value)
+	}
+	if i := h.indexOf(field); i >= 0 {
+		h.pairs[i].value = value
+		return false
+	}
+	h.pairs = append(h.pairs, hashPair{field: field, value: value})
+	if len(h.pairs) > h.maxListpackEntries {
+		h.convert()
+	}
+	return true
+}
+
+// Delete removes the field. Returns false if the field did not exist.
+func (h *Hash) Delete(field string) bool {
+	delete(h.expires, field)
+	if h.table != nil {
+		_, ok := h.table.delete(field)
+		return ok
+	}
+	i := h.indexOf(field)
+	if i < 0 {
+		return false
+	}
+	h.pairs = slices.Delete(h.pairs, i, i+1)
+	return true
+}
+
+// ForEach calls fn for every field. fn must not modify the hash.
+func (h *Hash) ForEach(fn func(field string, value []byte)) {
+	if h.table != nil {
+		h.table.forEach(fn)
+		return
+	}
+	for _, pair := range h.pairs {
+		fn(pair.field, pair.value)
+	}
+}
+
+// Scan calls fn for some of the fields, starting at the cursor, and returns
+// the cursor to continue from, with the guarantees of SCAN. The scan is over
+// once the returned cursor is 0. Like Redis, a hash still in a listpack is
+// returned whole by the first call. fn must not modify the hash.
+func (h *Hash) Scan(cursor uint64, count int, fn func(field string, value []byte)) uint64 {
+	if h.table == nil {
+		h.ForEach(fn)
+		return 0
+	}
+	visited := 0
+	maxIterations := count * 10
+	for {
+		cursor = h.table.scan(cursor, func(field string, value []byte) {
+			fn(field, value)
+			visited++
+		})
+		maxIterations--
+		if cursor == 0 || maxIterations == 0 || visited >= count {
+			return cursor
+		}
+	}
+}
+
+// Random returns a field picked at random. The hash must not be empty.
+func (h *Hash) Random() (string, []byte) {
+	if h.table != nil {
+		field, value, _ := h.table.random()
+		return field, value
+	}
+	pair := h.pairs[rand.IntN(len(h.pairs))]
+	return pair.field, pair.value
+}
+
+// Expiry returns the deadline of the field. The second return value is false
+// if the field does not exist or has no TTL.
+func (h *Hash) Expiry(field string) (time.Time, bool) {
+	deadline, ok := h.expires[field]
+	return deadline, ok
+}
+
+// SetExpiry sets the deadline after which the field is deleted. Returns false
+// if the field does not exist.
+func (h *Hash) SetExpiry(field string, deadline time.Time) bool {
+	if _, ok := h.Get(field); !ok {
+		return false
+	}
+	if h.expires == nil {
+		h.expires = make(map[string]time.Time)
+	}
+	h.expires[field] = deadline
+	heap.Push(&h.deadlines, fieldDeadline{deadline: deadline, field: field})
+	if h.table == nil {
+		h.packedWithTTL = true
+	}
+	if len(h.deadlines) > 2*len(h.expires)+hashStaleDeadlinesSlack {
+		h.deadlines = h.deadlines[:0]
+		for field, deadline := range h.expires {
+			h.deadlines = append(h.deadlines, fieldDeadline{deadline: deadline, field: field})
+		}
+		heap.Init(&h.deadlines)
+	}
+	return true
+}
+
+// Persist removes the TTL of the field. Returns false if the field does not
+// exist or has no TTL.
+func (h *Hash) Persist(field string) bool {
+	if _, ok := h.expires[field]; !ok {
+		return false
+	}
+	delete(h.expires, field)
+	return true
+}
+
+// HasExpiringFields reports whether some of the fields have a TTL.
+func (h *Hash) HasExpiringFields() bool {
+	return len(h.expires) > 0
+}
+
+// ExpireFields deletes the fields whose deadline is not after now, and
+// returns how many were deleted.
+func (h *Hash) ExpireFields(now time.Time) int {
+	expired := 0
+	for len(h.deadlines) > 0 && !now.Before(h.deadlines[0].deadline) {
+		next := heap.Pop(&h.deadlines).(fieldDeadline)
+		if deadline, ok := h.expires[next.field]; ok && deadline.Equal(next.deadline) {
+			h.Delete(next.field)
+			expired++
+		}
+	}
+	if len(h.expires) == 0 {
+		h.expires, h.deadlines = nil, nil
+	}
+	return expired
+}
+
+func (h *Hash) indexOf(field string) int {
+	return slices.IndexFunc(h.pairs, func(pair hashPair) bool { return pair.field == field })
+}
+
+func (h *Hash) convert() {
+	h.table = newDict[[]byte]()
+	for _, pair := range h.pairs {
+		h.table.set(pair.field, pair.value)
+	}
+	h.pairs = nil
+}
+
+func cloneHash(h *Hash) *Hash {
+	clone := newHash(h.maxListpackEntries, h.maxListpackValue)
+	clone.expires = maps.Clone(h.expires)
+	clone.deadlines = slices.Clone(h.deadlines)
+	clone.packedWithTTL = h.packedWithTTL
+	if h.table == nil {
+		clone.pairs = slices.Clone(h.pairs)
+		return clone
+	}
+	clone.table = newDict[[]byte]()
+	h.table.forEach(func(field string, value []byte) {
+		clone.table.set(field, value)
+	})
+	return clone
+}
+
+// fieldDeadline is an entry of the heap of the deadlines of a hash's fields.
+type fieldDeadline struct {
+	deadline time.Time
+	field    string
+}
+
+// fieldDeadlines implements heap.Interface, with the earliest deadline first.
+type fieldDeadlines []fieldDeadline
+
+func (d fieldDeadlines) Len() int {
+	return len(d)
+}
+
+func (d fieldDeadlines) Less(i, j int) bool {
+	return d[i].deadline.Before(d[j].deadline)
+}
+
+func (d fieldDeadlines) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
+
+func (d *fieldDeadlines) Push(x any) {
+	*d = append(*d, x.(fieldDeadline))
+}
+
+func (d *fieldDeadlines) Pop() any {
+	old := *d
+	last := old[len(old)-1]
+	old[len(old)-1] = fieldDeadline{}
+	*d = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,95 @@
+package internal
+
+import "sync"
+
+const (
+	// Values that take more work than this to free are freed in the
+	// background by UNLINK, like LAZYFREE_THRESHOLD in Redis
+	lazyFreeThreshold = 64
+	// Values waiting to be freed in the background before UNLINK falls back
+	// to freeing them on the command path
+	lazyFreeQueueSize = 1024
+)
+
+var (
+	lazyFreeCh   = make(chan Value, lazyFreeQueueSize)
+	lazyFreeOnce sync.Once
+)
+
+// freeEffort estimates the work needed to free the value, which is the
+// number of allocations it is made of.
+func freeEffort(value Value) int {
+	switch v := value.(type) {
+	case *List:
+		return v.ql.nodes
+	case *Hash:
+		if v.table != nil {
+			return v.table.len()
+		}
+		return 1
+	case *Set:
+		if v.table != nil {
+			return v.table.len()
+		}
+		return 1
+	case *ZSet:
+		return v.Len()
+	default:
+		return 1
+	}
+}
+
+// freeValue releases what the value holds, so that the garbage collector has
+// nothing left to trace from it.
+func freeValue(value Value) {
+	switch v := value.(type) {
+	case *List:
+		for node := v.ql.head; node != nil; {
+			next := node.next
+			*node = quicklistNode{}
+			node = next
+		}
+		v.ql = quicklist{}
+	case *Hash:
+		if v.table != nil {
+			v.table.release()
+		}
+		v.pairs = nil
+	case *Set:
+		if v.table != nil {
+			v.table.release()
+		}
+		v.ints, v.members = nil, nil
+	case *ZSet:
+		v.scores.release()
+		for node := v.zsl.header; node != nil; {
+			next := node.levels[0].forward
+			*node = skiplistNode{}
+			node = next
+		}
+		v.zsl = newSkiplist()
+	default:
+		// Strings are a single allocation, dropping the reference is enough
+	}
+}
+
+// freeValueLazily frees large values on a background goroutine, shared by
+// every store, and small ones right away.
+func freeValueLazily(value Value) {
+	if freeEffort(value) <= lazyFreeThreshold {
+		freeValue(value)
+		return
+	}
+	lazyFreeOnce.Do(func() {
+		go func() {
+			for value := range lazyFreeCh {
+				freeValue(value)
+			}
+		}()
+	})
+	select {
+	case lazyFreeCh <- value:
+	default:
+		freeValue(value)
+	}
+}
@@ -0,0 +1,218 @@
+package internal
+
+// ListEnd is one of the two ends of a list.
+type ListEnd int
+
+const (
+	ListHead ListEnd = iota
+	ListTail
+)
+
+// List is the value of a list key. Its entries are shared with replies like
+// the bytes of strings, so an entry is replaced rather than modified.
+type List struct {
+	ql quicklist
+	// Redis keeps a list in a single listpack until it outgrows one node, and
+	// converts it back once it shrinks to half of one
+	packed bool
+}
+
+func NewList() *List {
+	return &List{packed: true}
+}
+
+func (*List) Type() ValueType {
+	return TypeList
+}
+
+func (l *List) Encoding() Encoding {
+	if l.packed {
+		return EncodingListpack
+	}
+	return EncodingQuicklist
+}
+
+func (l *List) Len() int {
+	return l.ql.count
+}
+
+func (l *List) Push(entry []byte, end ListEnd) {
+	l.ql.push(entry, end == ListHead)
+	l.updateEncoding()
+}
+
+func (l *List) Pop(end ListEnd) ([]byte, bool) {
+	entry, ok := l.ql.pop(end == ListHead)
+	l.updateEncoding()
+	return entry, ok
+}
+
+// Index returns the entry at the index, where negative indexes count back
+// from the tail.
+func (l *List) Index(index int) ([]byte, bool) {
+	index, ok := l.normalizeIndex(index)
+	if !ok {
+		return nil, false
+	}
+	node, offset := l.ql.locate(index)
+	return node.entries[offset], true
+}
+
+// Set replaces the entry at the index, where negative indexes count back
+// from the tail. Returns false if the index is out of range.
+func (l *List) Set(index int, entry []byte) bool {
+	index, ok := l.normalizeIndex(index)
+	if !ok {
+		return false
+	}
+	l.ql.replace(index, entry)
+	l.updateEncoding()
+	return true
+}
+
+// Insert adds the entry right before or after the first occurrence of the
+// pivot. Returns false if the pivot is not in the list.
+func (l *List) Insert(pivot []byte, entry []byte, after bool) bool {
+	index := 0
+	for it := l.Iterator(0, false); ; index++ {
+		current, ok := it.Next()
+		if !ok {
+			return false
+		}
+		if string(current) == string(pivot) {
+			break
+		}
+	}
+	if after {
+		index++
+	}
+	l.ql.insert(index, entry)
+	l.updateEncoding()
+	return true
+}
+
+// Range returns the entries from start to stop, both inclusive, where
+// negative indexes count back from the tail.
+func (l *List) Range(start int, stop int) [][]byte {
+	start, stop, ok := l.normalizeRange(start, stop)
+	if !ok {
+		return nil
+	}
+	entries := make([][]byte, 0, stop-start+1)
+	it := l.Iterator(start, false)
+	for range stop - start + 1 {
+		entry, _ := it.Next()
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Trim removes the entries outside of start to stop, both inclusive, where
+// negative indexes count back from the tail.
+func (l *List) Trim(start int, stop int) {
+	start, stop, ok := l.normalizeRange(start, stop)
+	if !ok {
+		l.ql.deleteRange(0, l.ql.count)
+	} else {
+		l.ql.deleteRange(stop+1, l.ql.count-stop-1)
+		l.ql.deleteRange(0, start)
+	}
+	l.updateEncoding()
+}
+
+// Iterator returns an iterator starting at the entry at the index, which
+// must be in range, and moving towards the tail, or towards the head if
+// reverse is set.
+func (l *List) Iterator(index int, reverse bool) *ListIterator {
+	it := &ListIterator{list: l, reverse: reverse}
+	if index >= 0 && index < l.ql.count {
+		it.node, it.offset = l.ql.locate(index)
+	}
+	return it
+}
+
+func (l *List) normalizeIndex(index int) (int, bool) {
+	if index < 0 {
+		index += l.ql.count
+	}
+	return index, index >= 0 && index < l.ql.count
+}
+
+// normalizeRange converts a range like the one of LRANGE to indexes from the
+// head, clamped to the list. Returns false if the range is empty.
+func (l *List) normalizeRange(start int, stop int) (int, int, bool) {
+	length := l.ql.count
+	if start < 0 {
+		start = max(start+length, 0)
+	}
+	if stop < 0 {
+		stop += length
+	}
+	stop = min(stop, length-1)
+	return start, stop, start <= stop
+}
+
+func (l *List) updateEncoding() {
+	switch {
+	case l.ql.nodes > 1 || (l.ql.nodes == 1 && l.ql.head.size > quicklistMaxNodeSize):
+		l.packed = false
+	case !l.packed && (l.ql.nodes == 0 || l.ql.head.size <= quicklistMaxNodeSize/2):
+		l.packed = true
+	}
+}
+
+// ListIterator walks the entries of a list, and can remove them on the way.
+type ListIterator struct {
+	list    *List
+	reverse bool
+	node    *quicklistNode // node of the next entry, nil at the end
+	offset  int            // offset of the next entry in node
+	current *quicklistNode // node of the entry last returned
+	index   int            // offset of the entry last returned in current
+}
+
+// Next returns the next entry, or false once the iteration is over.
+func (it *ListIterator) Next() ([]byte, bool) {
+	if it.reverse {
+		for it.node != nil && it.offset < 0 {
+			it.node = it.node.prev
+			if it.node != nil {
+				it.offset = len(it.node.entries) - 1
+			}
+		}
+	} else {
+		for it.node != nil && it.offset >= len(it.node.entries) {
+			it.node, it.offset = it.node.next, 0
+		}
+	}
+	if it.node == nil {
+		return nil, false
+	}
+	it.current, it.index = it.node, it.offset
+	if it.reverse {
+		it.offset--
+	} else {
+		it.offset++
+	}
+	return it.node.entries[it.index], true
+}
+
+// Remove deletes the entry last returned by Next.
+func (it *ListIterator) Remove() {
+	it.list.ql.deleteFromNode(it.current, it.index)
+	// The entries after the removed one moved back by one. A removed node
+	// keeps its links, so the iteration goes on from its neighbour.
+	if !it.reverse && it.node == it.current {
+		it.offset = it.index
+	}
+	it.list.updateEncoding()
+}
+
+func cloneList(l *List) *List {
+	clone := &List{packed: l.packed}
+	for node := l.ql.head; node != nil; node = node.next {
+		clone.ql.linkNode(&quicklistNode{entries: append([][]byte(nil), node.entries...), size: node.size}, clone.ql.tail)
+	}
+	clone.ql.count = l.ql.count
+	return clone
+}
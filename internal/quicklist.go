@@ -0,0 +1,197 @@
+package internal
+
+const (
+	// Nodes are split once their entries take more than this many bytes,
+	// like the default list-max-listpack-size of -2 in Redis
+	quicklistMaxNodeSize = 8 * 1024
+	// Estimated bytes a listpack spends on each entry besides its content
+	quicklistEntryOverhead = 2
+)
+
+// quicklist is a doubly linked list of nodes, each holding a chunk of
+// entries, the way Redis lays out its lists. Pushes and pops at both ends
+// only touch the node at that end, and the chunks keep the per entry
+// overhead low.
+type quicklist struct {
+	head  *quicklistNode
+	tail  *quicklistNode
+	count int // number of entries
+	nodes int // number of nodes
+}
+
+type quicklistNode struct {
+	prev    *quicklistNode
+	next    *quicklistNode
+	entries [][]byte
+	size    int // estimated bytes of the entries
+}
+
+func entrySize(entry []byte) int {
+	return len(entry) + quicklistEntryOverhead
+}
+
+// push adds the entry at the head or the tail of the list.
+func (ql *quicklist) push(entry []byte, atHead bool) {
+	if atHead {
+		if ql.head == nil || ql.head.size+entrySize(entry) > quicklistMaxNodeSize {
+			ql.linkNode(&quicklistNode{}, nil)
+		}
+		ql.insertIntoNode(ql.head, 0, entry)
+	} else {
+		if ql.tail == nil || ql.tail.size+entrySize(entry) > quicklistMaxNodeSize {
+			ql.linkNode(&quicklistNode{}, ql.tail)
+		}
+		ql.insertIntoNode(ql.tail, len(ql.tail.entries), entry)
+	}
+}
+
+// pop removes the entry at the head or the tail of the list.
+func (ql *quicklist) pop(atHead bool) ([]byte, bool) {
+	if ql.count == 0 {
+		return nil, false
+	}
+	node, offset := ql.head, 0
+	if !atHead {
+		node, offset = ql.tail, len(ql.tail.entries)-1
+	}
+	entry := node.entries[offset]
+	ql.deleteFromNode(node, offset)
+	return entry, true
+}
+
+// locate returns the node holding the entry at the index, which must be in
+// range, walking from the closest end.
+func (ql *quicklist) locate(index int) (*quicklistNode, int) {
+	if index < ql.count/2 {
+		node := ql.head
+		for index >= len(node.entries) {
+			index -= len(node.entries)
+			node = node.next
+		}
+		return node, index
+	}
+	node := ql.tail
+	index = ql.count - 1 - index
+	for index >= len(node.entries) {
+		index -= len(node.entries)
+		node = node.prev
+	}
+	return node, len(node.entries) - 1 - index
+}
+
+// insert adds the entry at the index, which must be between 0 and count.
+func (ql *quicklist) insert(index int, entry []byte) {
+	if index == 0 || index == ql.count {
+		ql.push(entry, index == 0)
+		return
+	}
+	node, offset := ql.locate(index)
+	ql.insertIntoNode(node, offset, entry)
+}
+
+// replace overwrites the entry at the index, which must be in range.
+func (ql *quicklist) replace(index int, entry []byte) {
+	node, offset := ql.locate(index)
+	node.size += entrySize(entry) - entrySize(node.entries[offset])
+	node.entries[offset] = entry
+	ql.splitIfNeeded(node)
+}
+
+// deleteRange removes n entries starting at the index, dropping whole nodes
+// without visiting their entries.
+func (ql *quicklist) deleteRange(index int, n int) {
+	if n <= 0 {
+		return
+	}
+	node, offset := ql.locate(index)
+	for n > 0 {
+		next := node.next
+		if offset == 0 && n >= len(node.entries) {
+			n -= len(node.entries)
+			ql.count -= len(node.entries)
+			ql.unlinkNode(node)
+		} else {
+			end := min(offset+n, len(node.entries))
+			for _, entry := range node.entries[offset:end] {
+				node.size -= entrySize(entry)
+			}
+			n -= end - offset
+			ql.count -= end - offset
+			node.entries = append(node.entries[:offset], node.entries[end:]...)
+			clear(node.entries[len(node.entries) : len(node.entries)+end-offset])
+		}
+		node, offset = next, 0
+	}
+}
+
+func (ql *quicklist) insertIntoNode(node *quicklistNode, offset int, entry []byte) {
+	node.entries = append(node.entries, nil)
+	copy(node.entries[offset+1:], node.entries[offset:])
+	node.entries[offset] = entry
+	node.size += entrySize(entry)
+	ql.count++
+	ql.splitIfNeeded(node)
+}
+
+func (ql *quicklist) deleteFromNode(node *quicklistNode, offset int) {
+	node.size -= entrySize(node.entries[offset])
+	copy(node.entries[offset:], node.entries[offset+1:])
+	node.entries[len(node.entries)-1] = nil
+	node.entries = node.entries[:len(node.entries)-1]
+	ql.count--
+	if len(node.entries) == 0 {
+		ql.unlinkNode(node)
+	}
+}
+
+// splitIfNeeded moves the second half of the node to a new node after it
+// once the node is over the size limit. A node with a single large entry is
+// left as it is.
+func (ql *quicklist) splitIfNeeded(node *quicklistNode) {
+	if node.size <= quicklistMaxNodeSize || len(node.entries) < 2 {
+		return
+	}
+	half := len(node.entries) / 2
+	second := &quicklistNode{entries: append([][]byte(nil), node.entries[half:]...)}
+	for _, entry := range second.entries {
+		second.size += entrySize(entry)
+	}
+	clear(node.entries[half:])
+	node.entries = node.entries[:half]
+	node.size -= second.size
+	ql.linkNode(second, node)
+}
+
+// linkNode inserts the node after prev, or at the head if prev is nil.
+func (ql *quicklist) linkNode(node *quicklistNode, prev *quicklistNode) {
+	node.prev = prev
+	if prev == nil {
+		node.next = ql.head
+		ql.head = node
+	} else {
+		node.next = prev.next
+		prev.next = node
+	}
+	if node.next == nil {
+		ql.tail = node
+	} else {
+		node.next.prev = node
+	}
+	ql.nodes++
+}
+
+// unlinkNode removes the node from the list. The node keeps its own links,
+// so that an iterator standing on it can move on.
+func (ql *quicklist) unlinkNode(node *quicklistNode) {
+	if node.prev == nil {
+		ql.head = node.next
+	} else {
+		node.prev.next = node.next
+	}
+	if node.next == nil {
+		ql.tail = node.prev
+	} else {
+		node.next.prev = node.prev
+	}
+	ql.nodes--
+}
@@ -0,0 +1,390 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// Upper bounds on what is allocated up front for strings and aggregates,
+// before their content has actually been received
+const (
+	maxPreallocatedBytes    = 64 * 1024
+	maxPreallocatedElements = 1024
+)
+
+// Limits bound the sizes a Decoder accepts, so that a peer cannot make it
+// allocate arbitrary amounts of memory. A zero field means no limit.
+type Limits struct {
+	MaxBulkLen      int // length of a string
+	MaxMultibulkLen int // number of elements of an aggregate
+	MaxInlineLen    int // length of a line, including inline commands
+}
+
+// Decoder reads RESP2 and RESP3 values from a stream, such as replies read
+// by a client or commands read by a server. Data that does not follow RESP
+// is reported with a ProtocolError.
+type Decoder struct {
+	reader *bufio.Reader
+	limits Limits
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithLimits(r, Limits{})
+}
+
+func NewDecoderWithLimits(r io.Reader, limits Limits) *Decoder {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	return &Decoder{reader: reader, limits: limits}
+}
+
+// Decode reads the next value from the stream. An attribute is returned as
+// an *rtypes.Attribute wrapping the value that follows it. Returns an error
+// wrapping io.EOF if the stream ended before the value started.
+func (d *Decoder) Decode() (rtypes.RespDataType, error) {
+	dataType, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the type of the element: %w", err)
+	}
+	value, err := d.decodeValue(dataType)
+	if err != nil {
+		return nil, truncatedIfEOF(err)
+	}
+	return value, nil
+}
+
+func (d *Decoder) decodeValue(dataType byte) (rtypes.RespDataType, error) {
+	switch dataType {
+	case rtypes.SimpleStringTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.SimpleString{Value: line}, nil
+
+	case rtypes.SimpleErrorTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.SimpleError{Value: line}, nil
+
+	case rtypes.IntTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		integer, err := strconv.Atoi(string(line))
+		if err != nil {
+			return nil, &MalformedValueError{Kind: "integer", Value: line}
+		}
+		return &rtypes.Int{Value: integer}, nil
+
+	case rtypes.NullTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) != 0 {
+			return nil, &MalformedValueError{Kind: "null", Value: line}
+		}
+		return &rtypes.Null{}, nil
+
+	case rtypes.DoubleTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := parseDouble(string(line))
+		if !ok {
+			return nil, &MalformedValueError{Kind: "double", Value: line}
+		}
+		return &rtypes.Double{Value: value}, nil
+
+	case rtypes.BooleanTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		switch string(line) {
+		case "t":
+			return &rtypes.Boolean{Value: true}, nil
+		case "f":
+			return &rtypes.Boolean{Value: false}, nil
+		default:
+			return nil, &MalformedValueError{Kind: "boolean", Value: line}
+		}
+
+	case rtypes.BigNumberTypeId:
+		line, err := d.readLine(LimitLine)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Int).SetString(string(line), 10)
+		if !ok {
+			return nil, &MalformedValueError{Kind: "big number", Value: line}
+		}
+		return &rtypes.BigNumber{Value: value}, nil
+
+	case rtypes.BulkStringTypeId:
+		str, err := d.readBlob()
+		if err != nil {
+			return nil, err
+		}
+		if str == nil {
+			return &rtypes.NullBulkString{}, nil
+		}
+		return &rtypes.BulkString{Value: str}, nil
+
+	case rtypes.BlobErrorTypeId:
+		str, err := d.readBlob()
+		if err != nil {
+			return nil, err
+		}
+		if str == nil {
+			return nil, &BadLengthError{Kind: "bulk"}
+		}
+		return &rtypes.BlobError{Value: str}, nil
+
+	case rtypes.VerbatimStringTypeId:
+		str, err := d.readBlob()
+		if err != nil {
+			return nil, err
+		}
+		if len(str) < 4 || str[3] != ':' {
+			return nil, &MalformedValueError{Kind: "verbatim string", Value: str}
+		}
+		return &rtypes.VerbatimString{Format: string(str[:3]), Value: str[4:]}, nil
+
+	case rtypes.ArrayTypeId:
+		length, err := d.readLength("multibulk")
+		if err != nil {
+			return nil, err
+		}
+		if length == -1 {
+			return &rtypes.NullArray{}, nil
+		}
+		elements, err := d.decodeElements(length)
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.Array{Elements: elements}, nil
+
+	case rtypes.SetTypeId:
+		length, err := d.readLength("multibulk")
+		if err != nil {
+			return nil, err
+		}
+		elements, err := d.decodeElements(length)
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.Set{Elements: elements}, nil
+
+	case rtypes.PushTypeId:
+		length, err := d.readLength("multibulk")
+		if err != nil {
+			return nil, err
+		}
+		elements, err := d.decodeElements(length)
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.Push{Elements: elements}, nil
+
+	case rtypes.MapTypeId:
+		kvPairs, err := d.decodeKvPairs()
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.Map{KvPairs: kvPairs}, nil
+
+	case rtypes.AttributeTypeId:
+		kvPairs, err := d.decodeKvPairs()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decodeNested()
+		if err != nil {
+			return nil, err
+		}
+		return &rtypes.Attribute{KvPairs: kvPairs, Value: value}, nil
+
+	default:
+		return nil, &UnexpectedByteError{Got: dataType}
+	}
+}
+
+// Buffered returns the number of bytes already read from the stream but not
+// decoded yet, which is non-zero while the peer is pipelining.
+func (d *Decoder) Buffered() int {
+	return d.reader.Buffered()
+}
+
+// decodeNested reads a value inside an aggregate, where the end of the
+// stream means the aggregate was truncated.
+func (d *Decoder) decodeNested() (rtypes.RespDataType, error) {
+	dataType, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeValue(dataType)
+}
+
+func (d *Decoder) decodeElements(length int) ([]rtypes.RespDataType, error) {
+	if length < 0 {
+		return nil, &BadLengthError{Kind: "multibulk"}
+	}
+	if exceedsLimit(length, d.limits.MaxMultibulkLen) {
+		return nil, &LimitExceededError{Limit: LimitMultibulkLen}
+	}
+	// Grow the slice as elements arrive rather than trusting the length
+	elements := make([]rtypes.RespDataType, 0, min(length, maxPreallocatedElements))
+	for range length {
+		element, err := d.decodeNested()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+func (d *Decoder) decodeKvPairs() ([][2]rtypes.RespDataType, error) {
+	length, err := d.readLength("multibulk")
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || length > math.MaxInt/2 {
+		return nil, &BadLengthError{Kind: "multibulk"}
+	}
+	elements, err := d.decodeElements(2 * length)
+	if err != nil {
+		return nil, err
+	}
+	kvPairs := make([][2]rtypes.RespDataType, length)
+	for i := range kvPairs {
+		kvPairs[i] = [2]rtypes.RespDataType{elements[2*i], elements[2*i+1]}
+	}
+	return kvPairs, nil
+}
+
+// readBlob reads a length prefixed string. Returns nil for a length of -1.
+func (d *Decoder) readBlob() ([]byte, error) {
+	length, err := d.readLength("bulk")
+	if err != nil {
+		return nil, err
+	}
+	if length == -1 {
+		return nil, nil
+	}
+	return d.readBlobOfLength(length)
+}
+
+// readBlobOfLength reads a string of the given length followed by CRLF.
+func (d *Decoder) readBlobOfLength(length int) ([]byte, error) {
+	if length < 0 {
+		return nil, &BadLengthError{Kind: "bulk"}
+	}
+	if exceedsLimit(length, d.limits.MaxBulkLen) {
+		return nil, &LimitExceededError{Limit: LimitBulkLen}
+	}
+	// Grow the buffer as data arrives rather than trusting the length
+	buffer := bytes.NewBuffer(make([]byte, 0, min(length+2, maxPreallocatedBytes)))
+	if _, err := io.CopyN(buffer, d.reader, int64(length)+2); err != nil {
+		return nil, err
+	}
+	str := buffer.Bytes()
+	if str[length] != '\r' {
+		return nil, &UnexpectedByteError{Expected: '\r', Got: str[length]}
+	}
+	if str[length+1] != '\n' {
+		return nil, &UnexpectedByteError{Expected: '\n', Got: str[length+1]}
+	}
+	return str[:length], nil
+}
+
+// readLength reads the length of a string or an aggregate.
+func (d *Decoder) readLength(kind string) (int, error) {
+	line, err := d.readLine(LimitLine)
+	if err != nil {
+		return 0, err
+	}
+	length, err := strconv.Atoi(string(line))
+	if err != nil || length < -1 {
+		return 0, &BadLengthError{Kind: kind}
+	}
+	return length, nil
+}
+
+// readLine reads up to the next CRLF and returns the line without it.
+func (d *Decoder) readLine(limit Limit) ([]byte, error) {
+	line, err := d.readUntilNewline(limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, &UnexpectedByteError{Expected: '\r', Got: '\n'}
+	}
+	return line[:len(line)-2], nil
+}
+
+// readUntilNewline reads up to and including the next '\n', giving up as
+// soon as the line is longer than the inline limit.
+func (d *Decoder) readUntilNewline(limit Limit) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := d.reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if exceedsLimit(len(line), d.limits.MaxInlineLen) {
+			return nil, &LimitExceededError{Limit: limit}
+		}
+		if err == nil {
+			return line, nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+	}
+}
+
+// exceedsLimit reports whether value is over the limit, where a limit of 0
+// means there is no limit.
+func exceedsLimit(value int, limit int) bool {
+	return limit > 0 && value > limit
+}
+
+// truncatedIfEOF reports the end of the stream in the middle of a value as
+// a TruncatedFrameError.
+func truncatedIfEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &TruncatedFrameError{Err: err}
+	}
+	return err
+}
+
+func parseDouble(str string) (float64, bool) {
+	switch str {
+	case "inf", "+inf":
+		return math.Inf(1), true
+	case "-inf":
+		return math.Inf(-1), true
+	case "nan", "-nan":
+		return math.NaN(), true
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
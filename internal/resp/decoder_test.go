@@ -0,0 +1,107 @@
+package resp_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderDecodesEveryType(t *testing.T) {
+	bigNumber, _ := new(big.Int).SetString("3492890328409238509324850943850943825024385", 10)
+	tests := []struct {
+		name     string
+		input    string
+		expected rtypes.RespDataType
+	}{
+		{"simple string", "+OK\r\n", rtypes.NewSimpleString("OK")},
+		{"simple error", "-ERR oops\r\n", rtypes.NewSimpleError("ERR oops")},
+		{"integer", ":-42\r\n", &rtypes.Int{Value: -42}},
+		{"bulk string", "$5\r\nhe\r\no\r\n", rtypes.NewBulkString("he\r\no")},
+		{"empty bulk string", "$0\r\n\r\n", rtypes.NewBulkString("")},
+		{"null bulk string", "$-1\r\n", &rtypes.NullBulkString{}},
+		{"null array", "*-1\r\n", &rtypes.NullArray{}},
+		{"null", "_\r\n", &rtypes.Null{}},
+		{"double", ",-1.5\r\n", &rtypes.Double{Value: -1.5}},
+		{"inf", ",inf\r\n", &rtypes.Double{Value: math.Inf(1)}},
+		{"negative inf", ",-inf\r\n", &rtypes.Double{Value: math.Inf(-1)}},
+		{"true", "#t\r\n", &rtypes.Boolean{Value: true}},
+		{"false", "#f\r\n", &rtypes.Boolean{Value: false}},
+		{"big number", "(3492890328409238509324850943850943825024385\r\n", &rtypes.BigNumber{Value: bigNumber}},
+		{"blob error", "!21\r\nSYNTAX invalid syntax\r\n", rtypes.NewBlobError("SYNTAX invalid syntax")},
+		{"verbatim string", "=15\r\ntxt:Some string\r\n", rtypes.NewVerbatimString("txt", "Some string")},
+		{
+			"nested array", "*2\r\n:1\r\n*1\r\n+a\r\n",
+			&rtypes.Array{Elements: []rtypes.RespDataType{
+				&rtypes.Int{Value: 1},
+				&rtypes.Array{Elements: []rtypes.RespDataType{rtypes.NewSimpleString("a")}},
+			}},
+		},
+		{
+			"map", "%1\r\n+key\r\n:1\r\n",
+			&rtypes.Map{KvPairs: [][2]rtypes.RespDataType{{rtypes.NewSimpleString("key"), &rtypes.Int{Value: 1}}}},
+		},
+		{
+			"set", "~2\r\n+a\r\n+b\r\n",
+			&rtypes.Set{Elements: []rtypes.RespDataType{rtypes.NewSimpleString("a"), rtypes.NewSimpleString("b")}},
+		},
+		{
+			"push", ">2\r\n$7\r\nmessage\r\n$2\r\nhi\r\n",
+			&rtypes.Push{Elements: []rtypes.RespDataType{rtypes.NewBulkString("message"), rtypes.NewBulkString("hi")}},
+		},
+		{
+			"attribute", "|1\r\n+ttl\r\n:3600\r\n$3\r\nbar\r\n",
+			&rtypes.Attribute{
+				KvPairs: [][2]rtypes.RespDataType{{rtypes.NewSimpleString("ttl"), &rtypes.Int{Value: 3600}}},
+				Value:   rtypes.NewBulkString("bar"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := resp.NewDecoder(strings.NewReader(tt.input)).Decode()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, decoded)
+
+			// Encoding the decoded value gives back the input
+			var buffer bytes.Buffer
+			decoded.WriteAsBytes(&buffer)
+			assert.Equal(t, tt.input, buffer.String())
+		})
+	}
+
+	decoded, err := resp.NewDecoder(strings.NewReader(",nan\r\n")).Decode()
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(decoded.(*rtypes.Double).Value))
+}
+
+func TestDecoderRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown type", "@foo\r\n"},
+		{"missing CR", "+OK\n"},
+		{"bad integer", ":12a\r\n"},
+		{"bad boolean", "#x\r\n"},
+		{"bad double", ",one\r\n"},
+		{"truncated bulk string", "$10\r\nhello\r\n"},
+		{"bulk string without CRLF", "$2\r\nhello\r\n"},
+		{"negative aggregate length", "*-2\r\n"},
+		{"verbatim string without format", "=3\r\nabc\r\n"},
+		{"truncated map", "%2\r\n+a\r\n:1\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resp.NewDecoder(strings.NewReader(tt.input)).Decode()
+			var protocolErr resp.ProtocolError
+			assert.True(t, errors.As(err, &protocolErr), "expected a protocol error, got %v", err)
+		})
+	}
+}
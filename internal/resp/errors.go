@@ -0,0 +1,119 @@
+package resp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ProtocolError is implemented by the errors returned when the peer sends
+// data that does not follow RESP. The message of each of them is the one
+// Redis replies with before closing the connection.
+type ProtocolError interface {
+	error
+	isProtocolError()
+}
+
+// UnexpectedByteError is returned when a byte other than the expected one
+// was read, such as an argument of a command that is not a bulk string.
+type UnexpectedByteError struct {
+	Expected byte // 0 if there wasn't a single expected byte
+	Got      byte
+}
+
+func (e *UnexpectedByteError) Error() string {
+	if e.Expected == 0 {
+		return fmt.Sprintf("Protocol error: unexpected byte %s", quoteByte(e.Got))
+	}
+	return fmt.Sprintf("Protocol error: expected %s, got %s", quoteByte(e.Expected), quoteByte(e.Got))
+}
+
+// BadLengthError is returned when the length of a string or an aggregate is
+// not a valid integer or is negative.
+type BadLengthError struct {
+	Kind string // "bulk" or "multibulk"
+}
+
+func (e *BadLengthError) Error() string {
+	return fmt.Sprintf("Protocol error: invalid %s length", e.Kind)
+}
+
+// MalformedValueError is returned when a value cannot be parsed as its type,
+// such as a double that is not a number.
+type MalformedValueError struct {
+	Kind  string
+	Value []byte
+}
+
+func (e *MalformedValueError) Error() string {
+	return fmt.Sprintf("Protocol error: invalid %s %q", e.Kind, e.Value)
+}
+
+// UnbalancedQuotesError is returned for an inline command with a quote that
+// is not closed, or that is closed but not followed by a space.
+type UnbalancedQuotesError struct{}
+
+func (e *UnbalancedQuotesError) Error() string {
+	return "Protocol error: unbalanced quotes in request"
+}
+
+// TruncatedFrameError is returned when the stream ends in the middle of a
+// value.
+type TruncatedFrameError struct {
+	Err error
+}
+
+func (e *TruncatedFrameError) Error() string {
+	return "Protocol error: unexpected end of stream"
+}
+
+func (e *TruncatedFrameError) Unwrap() error {
+	return e.Err
+}
+
+type Limit int
+
+const (
+	LimitBulkLen        Limit = iota // length of a string
+	LimitMultibulkLen                // number of elements of an aggregate
+	LimitInlineRequest               // length of an inline command
+	LimitMultibulkCount              // length of the line holding the number of elements of a command
+	LimitBulkCount                   // length of the line holding the length of an argument of a command
+	LimitLine                        // length of any other line
+)
+
+// LimitExceededError is returned when the peer sends a value larger than the
+// configured limits.
+type LimitExceededError struct {
+	Limit Limit
+}
+
+func (e *LimitExceededError) Error() string {
+	switch e.Limit {
+	// Redis does not tell an oversized length apart from an invalid one
+	case LimitBulkLen:
+		return "Protocol error: invalid bulk length"
+	case LimitMultibulkLen:
+		return "Protocol error: invalid multibulk length"
+	case LimitInlineRequest:
+		return "Protocol error: too big inline request"
+	case LimitMultibulkCount:
+		return "Protocol error: too big mbulk count string"
+	case LimitBulkCount:
+		return "Protocol error: too big bulk count string"
+	default:
+		return "Protocol error: too big line"
+	}
+}
+
+func (*UnexpectedByteError) isProtocolError()   {}
+func (*BadLengthError) isProtocolError()        {}
+func (*MalformedValueError) isProtocolError()   {}
+func (*UnbalancedQuotesError) isProtocolError() {}
+func (*TruncatedFrameError) isProtocolError()   {}
+func (*LimitExceededError) isProtocolError()    {}
+
+// quoteByte quotes the byte like a Go character literal, so that control
+// characters stay readable.
+func quoteByte(b byte) string {
+	return strconv.QuoteRune(rune(b))
+}
@@ -0,0 +1,139 @@
+package resp
+
+import (
+	"strings"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+)
+
+// readInlineCommand reads a command sent as a plain line of space separated
+// arguments, which is what telnet and nc sessions send. Returns a nil
+// command for an empty line.
+func (d *Decoder) readInlineCommand() (*internal.Command, error) {
+	line, err := d.readUntilNewline(LimitInlineRequest)
+	if err != nil {
+		return nil, err
+	}
+	args, err := splitInlineArgs(strings.TrimSuffix(string(line[:len(line)-1]), "\r"))
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	arguments := make([]rtypes.RespDataType, len(args)-1)
+	for i, arg := range args[1:] {
+		arguments[i] = rtypes.NewBulkString(arg)
+	}
+	return &internal.Command{
+		Name:      strings.ToLower(args[0]),
+		Arguments: arguments,
+	}, nil
+}
+
+// splitInlineArgs splits the line into arguments following the rules of
+// sdssplitargs in Redis. Arguments are separated by whitespace and can be
+// quoted. Double quoted arguments support the escapes \n, \r, \t, \b, \a and
+// \xHH for a byte in hex. In single quoted arguments only \' is an escape.
+// A closing quote must be followed by whitespace or the end of the line.
+func splitInlineArgs(line string) ([]string, error) {
+	args := []string{}
+	i := 0
+	for {
+		for i < len(line) && isInlineSpace(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			return args, nil
+		}
+
+		var arg strings.Builder
+		inDoubleQuotes, inSingleQuotes, done := false, false, false
+		for !done {
+			switch {
+			case inDoubleQuotes:
+				if i == len(line) {
+					return nil, &UnbalancedQuotesError{}
+				}
+				if line[i] == '\\' && i+3 < len(line) && line[i+1] == 'x' &&
+					isHexDigit(line[i+2]) && isHexDigit(line[i+3]) {
+					arg.WriteByte(hexDigitValue(line[i+2])<<4 | hexDigitValue(line[i+3]))
+					i += 3
+				} else if line[i] == '\\' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						arg.WriteByte('\n')
+					case 'r':
+						arg.WriteByte('\r')
+					case 't':
+						arg.WriteByte('\t')
+					case 'b':
+						arg.WriteByte('\b')
+					case 'a':
+						arg.WriteByte('\a')
+					default:
+						arg.WriteByte(line[i])
+					}
+				} else if line[i] == '"' {
+					if i+1 < len(line) && !isInlineSpace(line[i+1]) {
+						return nil, &UnbalancedQuotesError{}
+					}
+					done = true
+				} else {
+					arg.WriteByte(line[i])
+				}
+			case inSingleQuotes:
+				if i == len(line) {
+					return nil, &UnbalancedQuotesError{}
+				}
+				if line[i] == '\\' && i+1 < len(line) && line[i+1] == '\'' {
+					arg.WriteByte('\'')
+					i++
+				} else if line[i] == '\'' {
+					if i+1 < len(line) && !isInlineSpace(line[i+1]) {
+						return nil, &UnbalancedQuotesError{}
+					}
+					done = true
+				} else {
+					arg.WriteByte(line[i])
+				}
+			default:
+				if i == len(line) || isInlineSpace(line[i]) {
+					done = true
+				} else if line[i] == '"' {
+					inDoubleQuotes = true
+				} else if line[i] == '\'' {
+					inSingleQuotes = true
+				} else {
+					arg.WriteByte(line[i])
+				}
+			}
+			if i < len(line) {
+				i++
+			}
+		}
+		args = append(args, arg.String())
+	}
+}
+
+func isInlineSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\v' || c == '\f'
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexDigitValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -1,98 +1,88 @@
 package resp
 
 import (
-	"bufio"
-	"errors"
 	"fmt"
-	"io"
 	"strconv"
 	"strings"
 
 	"github.com/ram-the-coder/redisgo/internal"
 	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
-	"github.com/rs/zerolog/log"
 )
 
-func ReadCommand(reader *bufio.Reader) (*internal.Command, error) {
-	element, err := readElement(reader)
+// ReadCommand reads the next command, either as an array of bulk strings or
+// as an inline command. Returns a nil command if there was nothing to run,
+// such as an empty inline command. Returns an error wrapping io.EOF if the
+// stream ended before the command started.
+func (d *Decoder) ReadCommand() (*internal.Command, error) {
+	firstByte, err := d.reader.Peek(1)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read first byte of the command: %w", err)
 	}
-	switch e := element.(type) {
-	case *rtypes.Array:
-		if len(e.Elements) == 0 {
-			return nil, fmt.Errorf("empty array is not part of a recognized command")
-		}
-		commandName, ok := e.Elements[0].(*rtypes.BulkString)
-		if !ok {
-			return nil, fmt.Errorf("the first element %s was not a string", element)
-		}
-		return &internal.Command{
-			Name:      strings.ToLower(string(commandName.Value)),
-			Arguments: e.Elements[1:],
-		}, nil
-	// Not yet handling non-array top level elements
-	default:
-		return nil, fmt.Errorf("failed to read command, unhandled element: %s", element)
+	var command *internal.Command
+	if firstByte[0] == rtypes.ArrayTypeId {
+		command, err = d.readMultibulkCommand()
+	} else {
+		command, err = d.readInlineCommand()
 	}
+	if err != nil {
+		return nil, truncatedIfEOF(err)
+	}
+	return command, nil
 }
 
-func readElement(reader *bufio.Reader) (rtypes.RespDataType, error) {
-	dataType, err := reader.ReadByte()
+// readMultibulkCommand reads a command sent as an array of bulk strings,
+// which is how every client library sends commands.
+func (d *Decoder) readMultibulkCommand() (*internal.Command, error) {
+	// Consume the '*'
+	if _, err := d.reader.ReadByte(); err != nil {
+		return nil, err
+	}
+	line, err := d.readLine(LimitMultibulkCount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read first byte of the command: %w", err)
+		return nil, err
+	}
+	length, err := strconv.Atoi(string(line))
+	if err != nil {
+		return nil, &BadLengthError{Kind: "multibulk"}
+	}
+	if exceedsLimit(length, d.limits.MaxMultibulkLen) {
+		return nil, &LimitExceededError{Limit: LimitMultibulkLen}
+	}
+	// Like Redis, an empty or null array is not an error, just not a command
+	if length <= 0 {
+		return nil, nil
 	}
 
-	if dataType == rtypes.ArrayTypeId {
-		arrayLength, err := readInteger(reader)
+	args := make([][]byte, 0, min(length, maxPreallocatedElements))
+	for range length {
+		dataType, err := d.reader.ReadByte()
 		if err != nil {
 			return nil, err
 		}
-		if arrayLength < 0 {
-			return nil, fmt.Errorf("invalid array length: %d", arrayLength)
-		}
-		values := make([]rtypes.RespDataType, arrayLength)
-		for i := range arrayLength {
-			values[i], err = readElement(reader)
-			if err != nil {
-				log.Error().Str("current_values", fmt.Sprintf("%s", values)).Msg("failed to read array element")
-				return nil, fmt.Errorf("failed to read element at index %d of array: %w", i, err)
-			}
+		if dataType != rtypes.BulkStringTypeId {
+			return nil, &UnexpectedByteError{Expected: rtypes.BulkStringTypeId, Got: dataType}
 		}
-		return &rtypes.Array{Elements: values}, nil
-	}
-
-	if dataType == rtypes.BulkStringTypeId {
-		strLength, err := readInteger(reader)
+		line, err := d.readLine(LimitBulkCount)
 		if err != nil {
 			return nil, err
 		}
-		str := make([]byte, strLength)
-		if _, err = io.ReadFull(reader, str); err != nil {
-			return nil, fmt.Errorf("failed to read string: %w", err)
+		strLength, err := strconv.Atoi(string(line))
+		if err != nil {
+			return nil, &BadLengthError{Kind: "bulk"}
 		}
-		// consume the next 2 bytes (\r\n)
-		if _, err = io.ReadFull(reader, make([]byte, 2)); err != nil {
-			return nil, fmt.Errorf("failed to read delimiter for string: %w", err)
+		arg, err := d.readBlobOfLength(strLength)
+		if err != nil {
+			return nil, err
 		}
-		return &rtypes.BulkString{Value: str}, nil
-	}
-	return nil, errors.New("failed to read unhandled element")
-}
-
-func readInteger(reader *bufio.Reader) (int, error) {
-	intString, err := reader.ReadString('\r')
-	if err != nil {
-		return 0, fmt.Errorf("failed to read integer: %w", err)
-	}
-	integer, err := strconv.Atoi(intString[:len(intString)-1])
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse integer \"%s\": %w", intString, err)
+		args = append(args, arg)
 	}
 
-	// read the '\n'
-	if _, err = reader.ReadByte(); err != nil {
-		return 0, fmt.Errorf("failed to integer terminator: %w", err)
+	arguments := make([]rtypes.RespDataType, len(args)-1)
+	for i, arg := range args[1:] {
+		arguments[i] = &rtypes.BulkString{Value: arg}
 	}
-	return integer, nil
+	return &internal.Command{
+		Name:      strings.ToLower(string(args[0])),
+		Arguments: arguments,
+	}, nil
 }
@@ -7,6 +7,9 @@ import (
 
 type Array struct {
 	Elements []RespDataType
+	// Pairs marks an array of two element arrays, like field-value pairs,
+	// which RESP2 clients get flattened the same way as a map
+	Pairs bool
 }
 
 func (ra *Array) WriteAsBytes(buffer *bytes.Buffer) {
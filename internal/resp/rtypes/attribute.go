@@ -0,0 +1,27 @@
+package rtypes
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Attribute carries auxiliary key-value pairs about a reply. On the wire the
+// attribute comes right before the reply it annotates, which is Value.
+type Attribute struct {
+	KvPairs [][2]RespDataType
+	Value   RespDataType
+}
+
+func (ra *Attribute) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(AttributeTypeId)
+	// Attribute Length
+	buffer.WriteString(strconv.Itoa(len(ra.KvPairs)))
+	buffer.WriteString("\r\n")
+	// Attribute Items
+	for _, kvPair := range ra.KvPairs {
+		kvPair[0].WriteAsBytes(buffer)
+		kvPair[1].WriteAsBytes(buffer)
+	}
+	// The annotated reply
+	ra.Value.WriteAsBytes(buffer)
+}
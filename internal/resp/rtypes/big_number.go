@@ -0,0 +1,17 @@
+package rtypes
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// BigNumber is an integer outside the range of a signed 64 bit integer.
+type BigNumber struct {
+	Value *big.Int
+}
+
+func (rbn *BigNumber) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(BigNumberTypeId)
+	buffer.WriteString(rbn.Value.String())
+	buffer.WriteString("\r\n")
+}
@@ -0,0 +1,28 @@
+package rtypes
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// BlobError is an error whose message can contain any bytes, including
+// newlines.
+type BlobError struct {
+	Value []byte
+}
+
+func NewBlobError(str string) *BlobError {
+	return &BlobError{Value: []byte(str)}
+}
+
+func (rbe *BlobError) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(BlobErrorTypeId)
+
+	// Error length
+	buffer.WriteString(strconv.Itoa(len(rbe.Value)))
+	buffer.WriteString("\r\n")
+
+	// Error message
+	buffer.Write(rbe.Value)
+	buffer.WriteString("\r\n")
+}
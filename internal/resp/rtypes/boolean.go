@@ -0,0 +1,17 @@
+package rtypes
+
+import "bytes"
+
+type Boolean struct {
+	Value bool
+}
+
+func (rb *Boolean) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(BooleanTypeId)
+	if rb.Value {
+		buffer.WriteByte('t')
+	} else {
+		buffer.WriteByte('f')
+	}
+	buffer.WriteString("\r\n")
+}
@@ -9,6 +9,14 @@ const MapTypeId byte = '%'
 const SimpleErrorTypeId byte = '-'
 const SimpleStringTypeId byte = '+'
 const NullTypeId byte = '_'
+const DoubleTypeId byte = ','
+const BooleanTypeId byte = '#'
+const BigNumberTypeId byte = '('
+const VerbatimStringTypeId byte = '='
+const SetTypeId byte = '~'
+const PushTypeId byte = '>'
+const AttributeTypeId byte = '|'
+const BlobErrorTypeId byte = '!'
 
 type RespDataType interface {
 	WriteAsBytes(*bytes.Buffer)
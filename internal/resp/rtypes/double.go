@@ -0,0 +1,55 @@
+package rtypes
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Double struct {
+	Value float64
+}
+
+func (rd *Double) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(DoubleTypeId)
+	buffer.WriteString(FormatDouble(rd.Value))
+	buffer.WriteString("\r\n")
+}
+
+// FormatDouble formats the value the way Redis does in replies: the shortest
+// digits that parse back to the same value, with "inf", "-inf" and "nan" for
+// the special values. Like fpconv_dtoa in Redis, an exponent is only used for
+// values too large or too small to be written with a few zeros.
+func FormatDouble(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	case math.IsNaN(value):
+		return "nan"
+	case value == 0:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	// The shortest digits, as d.ddde±xx
+	scientific := strconv.FormatFloat(value, 'e', -1, 64)
+	mantissa, exponent, _ := strings.Cut(scientific, "e")
+	exp, _ := strconv.Atoi(exponent)
+	digits := len(strings.TrimPrefix(strings.Replace(mantissa, ".", "", 1), "-"))
+	// The value is digits * 10^k
+	k := exp - digits + 1
+	absExp := exp
+	if absExp < 0 {
+		absExp = -absExp
+	}
+	if (k >= 0 && absExp < digits+7) || (k < 0 && (k > -7 || absExp < 4)) {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	// Unlike Go, Redis does not pad the exponent to two digits
+	sign := "+"
+	if exp < 0 {
+		sign = "-"
+	}
+	return mantissa + "e" + sign + strconv.Itoa(absExp)
+}
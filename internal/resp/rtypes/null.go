@@ -2,7 +2,11 @@ package rtypes
 
 import "bytes"
 
-type Null struct{}
+// Null is the RESP3 null. Array tells RESP2 clients apart, which expect a
+// null array rather than a null bulk string in place of a missing aggregate.
+type Null struct {
+	Array bool
+}
 
 func (rn *Null) WriteAsBytes(buffer *bytes.Buffer) {
 	buffer.WriteByte(NullTypeId)
@@ -0,0 +1,12 @@
+package rtypes
+
+import "bytes"
+
+// NullArray is how RESP2 represents a null array, such as the reply of a
+// blocking pop that timed out.
+type NullArray struct{}
+
+func (rn *NullArray) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(ArrayTypeId)
+	buffer.WriteString("-1\r\n")
+}
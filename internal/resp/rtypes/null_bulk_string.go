@@ -0,0 +1,11 @@
+package rtypes
+
+import "bytes"
+
+// NullBulkString is how RESP2 represents a null value.
+type NullBulkString struct{}
+
+func (rn *NullBulkString) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(BulkStringTypeId)
+	buffer.WriteString("-1\r\n")
+}
@@ -0,0 +1,26 @@
+package rtypes
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Push is out-of-band data sent to the client without being a reply to a
+// command, such as a pub/sub message or a client-side caching invalidation.
+// The first element is a string naming the kind of push.
+type Push struct {
+	Elements []RespDataType
+}
+
+func (rp *Push) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(PushTypeId)
+
+	// Push length
+	buffer.WriteString(strconv.Itoa(len(rp.Elements)))
+	buffer.WriteString("\r\n")
+
+	// Push elements
+	for _, element := range rp.Elements {
+		element.WriteAsBytes(buffer)
+	}
+}
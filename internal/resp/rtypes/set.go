@@ -0,0 +1,24 @@
+package rtypes
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Set is an unordered collection of unique elements.
+type Set struct {
+	Elements []RespDataType
+}
+
+func (rs *Set) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(SetTypeId)
+
+	// Set length
+	buffer.WriteString(strconv.Itoa(len(rs.Elements)))
+	buffer.WriteString("\r\n")
+
+	// Set elements
+	for _, element := range rs.Elements {
+		element.WriteAsBytes(buffer)
+	}
+}
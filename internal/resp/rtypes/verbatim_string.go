@@ -0,0 +1,34 @@
+package rtypes
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// VerbatimString is a string meant to be shown to the user as is. The
+// format is a three character hint such as "txt" or "mkd" (markdown).
+type VerbatimString struct {
+	Format string
+	Value  []byte
+}
+
+func NewVerbatimString(format string, str string) *VerbatimString {
+	return &VerbatimString{Format: format, Value: []byte(str)}
+}
+
+func (rvs *VerbatimString) WriteAsBytes(buffer *bytes.Buffer) {
+	buffer.WriteByte(VerbatimStringTypeId)
+
+	// Length of the format, the ':' separator and the value
+	buffer.WriteString(strconv.Itoa(len(rvs.Format) + 1 + len(rvs.Value)))
+	buffer.WriteString("\r\n")
+
+	buffer.WriteString(rvs.Format)
+	buffer.WriteByte(':')
+	buffer.Write(rvs.Value)
+	buffer.WriteString("\r\n")
+}
+
+func (rvs *VerbatimString) String() string {
+	return string(rvs.Value)
+}
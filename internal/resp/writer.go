@@ -3,21 +3,95 @@ package resp
 
 import (
 	"bytes"
-	"net"
+	"fmt"
+	"strings"
 
+	"github.com/ram-the-coder/redisgo/internal"
 	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
 	"github.com/rs/zerolog/log"
 )
 
-func WriteResponse(rdt rtypes.RespDataType, conn net.Conn) error {
-	var bytesBuffer bytes.Buffer
-	rdt.WriteAsBytes(&bytesBuffer)
-	response := bytesBuffer.Bytes()
-	// fmt.Print("Responding with: \n" + string(response))
-	log.Trace().Msgf("Responding with: %s", response)
-	_, err := conn.Write(response)
-	if err != nil {
-		log.Err(err).Msgf("error writing response (%q) to connection", response)
+// EncodeResponse appends the response to the buffer in the protocol the
+// client negotiated. Handlers always build RESP3 responses, which are
+// downgraded here for RESP2 clients. Nothing is appended if the response
+// can't be downgraded.
+func EncodeResponse(rdt rtypes.RespDataType, buffer *bytes.Buffer, protocol int) error {
+	if protocol < internal.Resp3 {
+		var err error
+		if rdt, err = ToResp2(rdt); err != nil {
+			return err
+		}
 	}
-	return err
+	start := buffer.Len()
+	rdt.WriteAsBytes(buffer)
+	log.Trace().Msgf("Responding with: %s", buffer.Bytes()[start:])
+	return nil
+}
+
+// ToResp2 replaces the RESP3 only types in rdt with their RESP2 equivalents,
+// the same way Redis replies to RESP2 clients: maps are flattened into
+// arrays of alternating keys and values, sets and pushes become arrays,
+// nulls become null bulk strings or null arrays, booleans become 0 or 1, and doubles, big
+// numbers and verbatim strings become bulk strings. Attributes are dropped.
+// Returns an error if an array of pairs holds anything but arrays.
+func ToResp2(rdt rtypes.RespDataType) (rtypes.RespDataType, error) {
+	switch v := rdt.(type) {
+	case *rtypes.Null:
+		if v.Array {
+			return &rtypes.NullArray{}, nil
+		}
+		return &rtypes.NullBulkString{}, nil
+	case *rtypes.Double:
+		return rtypes.NewBulkString(rtypes.FormatDouble(v.Value)), nil
+	case *rtypes.Boolean:
+		if v.Value {
+			return &rtypes.Int{Value: 1}, nil
+		}
+		return &rtypes.Int{Value: 0}, nil
+	case *rtypes.BigNumber:
+		return rtypes.NewBulkString(v.Value.String()), nil
+	case *rtypes.VerbatimString:
+		return &rtypes.BulkString{Value: v.Value}, nil
+	case *rtypes.BlobError:
+		return rtypes.NewSimpleError(strings.NewReplacer("\r", " ", "\n", " ").Replace(string(v.Value))), nil
+	case *rtypes.Attribute:
+		return ToResp2(v.Value)
+	case *rtypes.Map:
+		elements := make([]rtypes.RespDataType, 0, 2*len(v.KvPairs))
+		for _, kvPair := range v.KvPairs {
+			elements = append(elements, kvPair[0], kvPair[1])
+		}
+		return toResp2Array(elements)
+	case *rtypes.Array:
+		if v.Pairs {
+			elements := make([]rtypes.RespDataType, 0, 2*len(v.Elements))
+			for _, element := range v.Elements {
+				pair, ok := element.(*rtypes.Array)
+				if !ok {
+					return nil, fmt.Errorf("array of pairs holds a %T", element)
+				}
+				elements = append(elements, pair.Elements...)
+			}
+			return toResp2Array(elements)
+		}
+		return toResp2Array(v.Elements)
+	case *rtypes.Set:
+		return toResp2Array(v.Elements)
+	case *rtypes.Push:
+		return toResp2Array(v.Elements)
+	default:
+		return rdt, nil
+	}
+}
+
+// toResp2Array returns a RESP2 array of the elements downgraded by ToResp2.
+func toResp2Array(rdts []rtypes.RespDataType) (rtypes.RespDataType, error) {
+	elements := make([]rtypes.RespDataType, len(rdts))
+	for i, rdt := range rdts {
+		var err error
+		if elements[i], err = ToResp2(rdt); err != nil {
+			return nil, err
+		}
+	}
+	return &rtypes.Array{Elements: elements}, nil
 }
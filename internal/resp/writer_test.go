@@ -0,0 +1,37 @@
+package resp_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDoubles(t *testing.T) {
+	tests := []struct {
+		value float64
+		resp3 string
+		resp2 string
+	}{
+		{1.5, ",1.5\r\n", "$3\r\n1.5\r\n"},
+		{1000000, ",1000000\r\n", "$7\r\n1000000\r\n"},
+		{1234567.5, ",1234567.5\r\n", "$9\r\n1234567.5\r\n"},
+		{-123456789012345678, ",-123456789012345680\r\n", "$19\r\n-123456789012345680\r\n"},
+		{1e21, ",1e+21\r\n", "$5\r\n1e+21\r\n"},
+		{0.00001, ",0.00001\r\n", "$7\r\n0.00001\r\n"},
+		{1.5e-7, ",1.5e-7\r\n", "$6\r\n1.5e-7\r\n"},
+		{math.Inf(-1), ",-inf\r\n", "$4\r\n-inf\r\n"},
+	}
+	for _, tt := range tests {
+		var buffer bytes.Buffer
+		assert.Nil(t, resp.EncodeResponse(&rtypes.Double{Value: tt.value}, &buffer, internal.Resp3))
+		assert.Equal(t, tt.resp3, buffer.String())
+		buffer.Reset()
+		assert.Nil(t, resp.EncodeResponse(&rtypes.Double{Value: tt.value}, &buffer, internal.Resp2))
+		assert.Equal(t, tt.resp2, buffer.String())
+	}
+}
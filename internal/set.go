@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strconv"
+)
+
+// Set is the value of a set key. Redis keeps a small set of integers sorted
+// in an intset and other small sets in a listpack, and converts a set to a
+// hashtable once it outgrows those limits. A set is never converted back.
+type Set struct {
+	encoding Encoding
+	// Members while the set is an intset, sorted
+	ints []int64
+	// Members in insertion order while the set is a listpack
+	members []string
+	// nil until the set is converted to a hashtable
+	table *dict[struct{}]
+
+	maxIntsetEntries   int
+	maxListpackEntries int
+	maxListpackValue   int
+}
+
+func newSet(maxIntsetEntries int, maxListpackEntries int, maxListpackValue int) *Set {
+	return &Set{
+		encoding:           EncodingIntset,
+		maxIntsetEntries:   maxIntsetEntries,
+		maxListpackEntries: maxListpackEntries,
+		maxListpackValue:   maxListpackValue,
+	}
+}
+
+func (*Set) Type() ValueType {
+	return TypeSet
+}
+
+func (s *Set) Encoding() Encoding {
+	return s.encoding
+}
+
+func (s *Set) Len() int {
+	switch s.encoding {
+	case EncodingIntset:
+		return len(s.ints)
+	case EncodingListpack:
+		return len(s.members)
+	default:
+		return s.table.len()
+	}
+}
+
+// Contains reports whether the member is in the set.
+func (s *Set) Contains(member string) bool {
+	switch s.encoding {
+	case EncodingIntset:
+		n, ok := parseCanonicalInt(member)
+		if !ok {
+			return false
+		}
+		_, found := slices.BinarySearch(s.ints, n)
+		return found
+	case EncodingListpack:
+		return slices.Contains(s.members, member)
+	default:
+		_, ok := s.table.get(member)
+		return ok
+	}
+}
+
+// Add adds the member. Returns false if it was already in the set.
+func (s *Set) Add(member string) bool {
+	if s.encoding == EncodingIntset {
+		n, ok := parseCanonicalInt(member)
+		if !ok {
+			if len(s.ints) < s.maxListpackEntries && len(member) <= s.maxListpackValue {
+				s.convert(EncodingListpack)
+			} else {
+				s.convert(EncodingHashtable)
+			}
+			return s.Add(member)
+		}
+		i, found := slices.BinarySearch(s.ints, n)
+		if found {
+			return false
+		}
+		s.ints = slices.Insert(s.ints, i, n)
+		if len(s.ints) > s.maxIntsetEntries {
+			if len(s.ints) <= s.maxListpackEntries {
+				s.convert(EncodingListpack)
+			} else {
+				s.convert(EncodingHashtable)
+			}
+		}
+		return true
+	}
+	if s.encoding == EncodingListpack {
+		if slices.Contains(s.members, member) {
+			return false
+		}
+		if len(s.members) < s.maxListpackEntries && len(member) <= s.maxListpackValue {
+			s.members = append(s.members, member)
+			return true
+		}
+		s.convert(EncodingHashtable)
+	}
+	return s.table.set(member, struct{}{})
+}
+
+// Remove removes the member. Returns false if it was not in the set.
+func (s *Set) Remove(member string) bool {
+	switch s.encoding {
+	case EncodingIntset:
+		n, ok := parseCanonicalInt(member)
+		if !ok {
+			return false
+		}
+		i, found := slices.BinarySearch(s.ints, n)
+		if found {
+			s.ints = slices.Delete(s.ints, i, i+1)
+		}
+		return found
+	case EncodingListpack:
+		i := slices.Index(s.members, member)
+		if i >= 0 {
+			s.members = slices.Delete(s.members, i, i+1)
+		}
+		return i >= 0
+	default:
+		_, ok := s.table.delete(member)
+		return ok
+	}
+}
+
+// ForEach calls fn for every member. fn must not modify the set.
+func (s *Set) ForEach(fn func(member string)) {
+	switch s.encoding {
+	case EncodingIntset:
+		for _, n := range s.ints {
+			fn(strconv.FormatInt(n, 10))
+		}
+	case EncodingListpack:
+		for _, member := range s.members {
+			fn(member)
+		}
+	default:
+		s.table.forEach(func(member string, _ struct{}) {
+			fn(member)
+		})
+	}
+}
+
+// Members returns all the members.
+func (s *Set) Members() []string {
+	members := make([]string, 0, s.Len())
+	s.ForEach(func(member string) {
+		members = append(members, member)
+	})
+	return members
+}
+
+// Scan calls fn for some of the members, starting at the cursor, and returns
+// the cursor to continue from, with the guarantees of SCAN. Like a hash, a
+// set not yet in a hashtable is returned whole by the first call. fn must not
+// modify the set.
+func (s *Set) Scan(cursor uint64, count int, fn func(member string)) uint64 {
+	if s.encoding != EncodingHashtable {
+		s.ForEach(fn)
+		return 0
+	}
+	visited := 0
+	maxIterations := count * 10
+	for {
+		cursor = s.table.scan(cursor, func(member string, _ struct{}) {
+			fn(member)
+			visited++
+		})
+		maxIterations--
+		if cursor == 0 || maxIterations == 0 || visited >= count {
+			return cursor
+		}
+	}
+}
+
+// Random returns a member picked at random. The set must not be empty.
+func (s *Set) Random() string {
+	switch s.encoding {
+	case EncodingIntset:
+		return strconv.FormatInt(s.ints[rand.IntN(len(s.ints))], 10)
+	case EncodingListpack:
+		return s.members[rand.IntN(len(s.members))]
+	default:
+		member, _, _ := s.table.random()
+		return member
+	}
+}
+
+// Pop removes and returns a member picked at random. The set must not be
+// empty.
+func (s *Set) Pop() string {
+	member := s.Random()
+	s.Remove(member)
+	return member
+}
+
+func (s *Set) convert(encoding Encoding) {
+	members := s.Members()
+	s.ints, s.members = nil, nil
+	s.encoding = encoding
+	if encoding == EncodingListpack {
+		s.members = members
+		return
+	}
+	s.table = newDict[struct{}]()
+	for _, member := range members {
+		s.table.set(member, struct{}{})
+	}
+}
+
+func cloneSet(s *Set) *Set {
+	clone := newSet(s.maxIntsetEntries, s.maxListpackEntries, s.maxListpackValue)
+	clone.encoding = s.encoding
+	switch s.encoding {
+	case EncodingIntset:
+		clone.ints = slices.Clone(s.ints)
+	case EncodingListpack:
+		clone.members = slices.Clone(s.members)
+	default:
+		clone.table = newDict[struct{}]()
+		s.table.forEach(func(member string, _ struct{}) {
+			clone.table.set(member, struct{}{})
+		})
+	}
+	return clone
+}
@@ -1,22 +1,429 @@
 package internal
 
+import (
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal/glob"
+)
+
+const (
+	// Number of keys with a TTL sampled in each iteration of the active expire cycle
+	activeExpireKeysPerLoop = 20
+	// The cycle keeps sampling while more than this percentage of the sampled keys were expired
+	activeExpireAcceptableStalePercent = 25
+	// Upper bound on the time a single active expire cycle may take
+	activeExpireCycleTimeLimit = 25 * time.Millisecond
+)
+
+// Store is the keyspace. It is only ever accessed from the goroutine that
+// runs the store commands.
+//
+// Strings are shared with the replies that return them, which are encoded on
+// other goroutines, so the bytes of a stored string must never be overwritten.
+// A command modifying a string stores a new slice instead, or appends past the
+// end of the current one.
 type Store struct {
-	m map[string]string
+	keyspace *dict[Value]
+	expires  map[string]time.Time // deadline of every key that has a TTL
+	// Keys of the hashes that have fields with a TTL, for the active expire
+	// cycle. Entries may be stale, and are dropped once found to be.
+	hashFieldExpires map[string]struct{}
+	clock            Clock
+	config           Config
 }
 
 func NewStore() *Store {
-	return &Store{m: make(map[string]string)}
+	return NewStoreWithConfig(DefaultConfig())
+}
+
+func NewStoreWithConfig(config Config) *Store {
+	return &Store{
+		keyspace:         newDict[Value](),
+		expires:          make(map[string]time.Time),
+		hashFieldExpires: make(map[string]struct{}),
+		clock:            systemClock{},
+		config:           config,
+	}
+}
+
+func NewStoreWithClock(clock Clock) *Store {
+	s := NewStore()
+	s.clock = clock
+	return s
+}
+
+// Now returns the current time as seen by the store's clock.
+func (s *Store) Now() time.Time {
+	return s.clock.Now()
+}
+
+// MaxStringLength returns the longest a string value can grow to, which is
+// the proto-max-bulk-len of the store's config.
+func (s *Store) MaxStringLength() int {
+	return s.config.ProtoMaxBulkLen
 }
 
-func (s *Store) Set(key string, value string) error {
-	s.m[key] = value
+// Set stores the string against the key, whatever the key held before, and
+// clears any TTL the key had.
+func (s *Store) Set(key string, value []byte) error {
+	return s.SetValue(key, StringValue(value))
+}
+
+// SetKeepTTL stores the string against the key, retaining its current TTL.
+func (s *Store) SetKeepTTL(key string, value []byte) error {
+	s.keyspace.set(key, StringValue(value))
+	return nil
+}
+
+// SetValue stores the value against the key, whatever the key held before,
+// and clears any TTL the key had.
+func (s *Store) SetValue(key string, value Value) error {
+	s.keyspace.set(key, value)
+	delete(s.expires, key)
 	return nil
 }
 
-func (s *Store) Get(key string) (string, bool, error) {
-	if value, ok := s.m[key]; !ok {
-		return "", false, nil
+// Get returns the string held by the key. Returns ErrWrongType if the key
+// holds a value of another type.
+func (s *Store) Get(key string) ([]byte, bool, error) {
+	return lookupAs[StringValue](s, key)
+}
+
+// GetList returns the list held by the key. Returns ErrWrongType if the key
+// holds a value of another type.
+func (s *Store) GetList(key string) (*List, bool, error) {
+	return lookupAs[*List](s, key)
+}
+
+// GetHash returns the hash held by the key. Returns ErrWrongType if the key
+// holds a value of another type.
+func (s *Store) GetHash(key string) (*Hash, bool, error) {
+	return lookupAs[*Hash](s, key)
+}
+
+// NewHash returns an empty hash that follows the listpack limits of the
+// store's config.
+func (s *Store) NewHash() *Hash {
+	return newHash(s.config.HashMaxListpackEntries, s.config.HashMaxListpackValue)
+}
+
+// GetSet returns the set held by the key. Returns ErrWrongType if the key
+// holds a value of another type.
+func (s *Store) GetSet(key string) (*Set, bool, error) {
+	return lookupAs[*Set](s, key)
+}
+
+// NewSet returns an empty set that follows the intset and listpack limits
+// of the store's config.
+func (s *Store) NewSet() *Set {
+	return newSet(s.config.SetMaxIntsetEntries, s.config.SetMaxListpackEntries, s.config.SetMaxListpackValue)
+}
+
+// GetZSet returns the sorted set held by the key. Returns ErrWrongType if the
+// key holds a value of another type.
+func (s *Store) GetZSet(key string) (*ZSet, bool, error) {
+	return lookupAs[*ZSet](s, key)
+}
+
+// NewZSet returns an empty sorted set.
+func (s *Store) NewZSet() *ZSet {
+	return newZSet()
+}
+
+// lookupAs returns the value held by the key if it is a T, and ErrWrongType
+// if it is not.
+func lookupAs[T Value](s *Store, key string) (T, bool, error) {
+	var zero T
+	value, ok := s.Lookup(key)
+	if !ok {
+		return zero, false, nil
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, false, ErrWrongType
+	}
+	return typed, true, nil
+}
+
+// Lookup returns the value held by the key, whatever its type.
+func (s *Store) Lookup(key string) (Value, bool) {
+	s.expireIfNeeded(key)
+	return s.keyspace.get(key)
+}
+
+// Type returns the type of the value held by the key.
+func (s *Store) Type(key string) (ValueType, bool) {
+	value, ok := s.Lookup(key)
+	if !ok {
+		return 0, false
+	}
+	return value.Type(), true
+}
+
+func (s *Store) Exists(key string) bool {
+	s.expireIfNeeded(key)
+	_, ok := s.keyspace.get(key)
+	return ok
+}
+
+// Delete removes the key. Returns false if the key did not exist.
+func (s *Store) Delete(key string) bool {
+	s.expireIfNeeded(key)
+	if _, ok := s.keyspace.delete(key); !ok {
+		return false
+	}
+	delete(s.expires, key)
+	return true
+}
+
+// Unlink removes the key like Delete, but frees large values in the
+// background instead of on the command path.
+func (s *Store) Unlink(key string) bool {
+	s.expireIfNeeded(key)
+	value, ok := s.keyspace.delete(key)
+	if !ok {
+		return false
+	}
+	delete(s.expires, key)
+	freeValueLazily(value)
+	return true
+}
+
+// Rename moves the value and the TTL of src to dst, replacing dst if it
+// exists. Returns false if src does not exist.
+func (s *Store) Rename(src string, dst string) bool {
+	s.expireIfNeeded(src)
+	value, ok := s.keyspace.delete(src)
+	if !ok {
+		return false
+	}
+	deadline, hasTTL := s.expires[src]
+	delete(s.expires, src)
+	s.keyspace.set(dst, value)
+	if hasTTL {
+		s.expires[dst] = deadline
+	} else {
+		delete(s.expires, dst)
+	}
+	s.trackHashFieldExpiry(dst, value)
+	return true
+}
+
+// Copy stores a copy of the value and the TTL of src at dst, replacing dst
+// if it exists. Returns false if src does not exist.
+func (s *Store) Copy(src string, dst string) bool {
+	s.expireIfNeeded(src)
+	value, ok := s.keyspace.get(src)
+	if !ok {
+		return false
+	}
+	clone := cloneValue(value)
+	s.keyspace.set(dst, clone)
+	if deadline, hasTTL := s.expires[src]; hasTTL {
+		s.expires[dst] = deadline
 	} else {
-		return value, true, nil
+		delete(s.expires, dst)
+	}
+	s.trackHashFieldExpiry(dst, clone)
+	return true
+}
+
+// TrackHashFieldExpiry registers the key as holding a hash with fields that
+// have a TTL, so that the active expire cycle reclaims them. It is called
+// after setting the TTL of a field.
+func (s *Store) TrackHashFieldExpiry(key string) {
+	s.hashFieldExpires[key] = struct{}{}
+}
+
+func (s *Store) trackHashFieldExpiry(key string, value Value) {
+	if hash, ok := value.(*Hash); ok && hash.HasExpiringFields() {
+		s.TrackHashFieldExpiry(key)
+	}
+}
+
+// Keys returns the keys that match the glob-style pattern.
+func (s *Store) Keys(pattern string) []string {
+	now := s.clock.Now()
+	keys := []string{}
+	s.keyspace.forEach(func(key string, _ Value) {
+		if s.isExpired(key, now) || !matchesPattern(pattern, key) {
+			return
+		}
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Scan returns some of the keys that match the glob-style pattern, starting
+// at the cursor, along with the cursor to continue from. The scan is over
+// once the returned cursor is 0. Every key present during the whole scan is
+// returned at least once.
+func (s *Store) Scan(cursor uint64, count int, pattern string) (uint64, []string) {
+	var visited []string
+	// Like Redis, visit up to 10 buckets per key asked for, so that a sparse
+	// table does not make a single call walk all of it
+	maxIterations := count * 10
+	for {
+		cursor = s.keyspace.scan(cursor, func(key string, _ Value) {
+			visited = append(visited, key)
+		})
+		maxIterations--
+		if cursor == 0 || maxIterations == 0 || len(visited) >= count {
+			break
+		}
+	}
+
+	// Expired keys are only deleted once the scan of the dict is done
+	now := s.clock.Now()
+	keys := []string{}
+	for _, key := range visited {
+		if s.isExpired(key, now) {
+			s.expireIfNeeded(key)
+			continue
+		}
+		if matchesPattern(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	return cursor, keys
+}
+
+// matchesPattern reports whether the key matches the glob-style pattern,
+// where "*" matches every key including the empty one.
+func matchesPattern(pattern string, key string) bool {
+	return pattern == "*" || glob.Match(pattern, key)
+}
+
+// KeyCount returns the number of keys in the store, including keys that have
+// expired but have not been reclaimed yet.
+func (s *Store) KeyCount() int {
+	return s.keyspace.len()
+}
+
+// SetExpiry sets the deadline after which the key is deleted.
+// Returns false if the key does not exist.
+func (s *Store) SetExpiry(key string, deadline time.Time) bool {
+	s.expireIfNeeded(key)
+	if _, ok := s.keyspace.get(key); !ok {
+		return false
+	}
+	s.expires[key] = deadline
+	return true
+}
+
+// Expiry returns the deadline of the key. The second return value is false
+// if the key does not exist or has no TTL.
+func (s *Store) Expiry(key string) (time.Time, bool) {
+	s.expireIfNeeded(key)
+	deadline, ok := s.expires[key]
+	return deadline, ok
+}
+
+// Persist removes the TTL of the key. Returns false if the key does not exist
+// or has no TTL.
+func (s *Store) Persist(key string) bool {
+	s.expireIfNeeded(key)
+	if _, ok := s.expires[key]; !ok {
+		return false
+	}
+	delete(s.expires, key)
+	return true
+}
+
+// ActiveExpireCycle reclaims expired keys that are never accessed again.
+// Like Redis, it samples a few keys with a TTL at a time and keeps going
+// while a large share of the sample turns out to be expired, bounded by a
+// time limit so that the command path is not starved.
+func (s *Store) ActiveExpireCycle() {
+	// Half of the time goes to the hash fields with a TTL, so that many
+	// expiring keys can't keep their expired fields from being reclaimed
+	timeLimit := activeExpireCycleTimeLimit / 2
+	s.activeExpireLoop(timeLimit, func(now time.Time) (int, int) {
+		sampled, expired := 0, 0
+		// Map iteration order is randomized, which makes this a random sample
+		for key, deadline := range s.expires {
+			if sampled == activeExpireKeysPerLoop {
+				break
+			}
+			sampled++
+			if !now.Before(deadline) {
+				s.keyspace.delete(key)
+				delete(s.expires, key)
+				expired++
+			}
+		}
+		return sampled, expired
+	})
+	// Then the same for the hashes with fields that have a TTL, counting the
+	// hashes that had expired fields
+	s.activeExpireLoop(timeLimit, func(now time.Time) (int, int) {
+		sampled, expired := 0, 0
+		for key := range s.hashFieldExpires {
+			if sampled == activeExpireKeysPerLoop {
+				break
+			}
+			sampled++
+			if s.expireHashFields(key, now) > 0 {
+				expired++
+			}
+		}
+		return sampled, expired
+	})
+}
+
+// activeExpireLoop takes samples until few enough of a sample were expired,
+// or the time limit is over. sample returns how many it sampled and how many
+// of them were expired.
+func (s *Store) activeExpireLoop(timeLimit time.Duration, sample func(now time.Time) (int, int)) {
+	start := time.Now()
+	for {
+		sampled, expired := sample(s.clock.Now())
+		if sampled == 0 || expired*100/sampled <= activeExpireAcceptableStalePercent {
+			return
+		}
+		if time.Since(start) > timeLimit {
+			return
+		}
+	}
+}
+
+// isExpired reports whether the deadline of the key is not after now.
+func (s *Store) isExpired(key string, now time.Time) bool {
+	deadline, ok := s.expires[key]
+	return ok && !now.Before(deadline)
+}
+
+// expireIfNeeded deletes the key if its deadline has passed, and the fields
+// of the hash it holds whose deadline has passed.
+func (s *Store) expireIfNeeded(key string) {
+	now := s.clock.Now()
+	if s.isExpired(key, now) {
+		s.keyspace.delete(key)
+		delete(s.expires, key)
+		return
+	}
+	if _, ok := s.hashFieldExpires[key]; ok {
+		s.expireHashFields(key, now)
+	}
+}
+
+// expireHashFields deletes the fields of the hash held by the key whose
+// deadline has passed, and the key once no fields are left. Returns the
+// number of fields deleted.
+func (s *Store) expireHashFields(key string, now time.Time) int {
+	value, _ := s.keyspace.get(key)
+	hash, ok := value.(*Hash)
+	if !ok {
+		delete(s.hashFieldExpires, key)
+		return 0
+	}
+	expired := hash.ExpireFields(now)
+	if hash.Len() == 0 {
+		s.keyspace.delete(key)
+		delete(s.expires, key)
+	}
+	if !hash.HasExpiringFields() {
+		delete(s.hashFieldExpires, key)
 	}
+	return expired
 }
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// ErrWrongType is returned when a command expects a key to hold a value of
+// another type. Its message is the reply Redis sends.
+var ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// ValueType is the data type of a value, as reported by TYPE.
+type ValueType int
+
+const (
+	TypeString ValueType = iota
+	TypeList
+	TypeSet
+	TypeZSet
+	TypeHash
+	TypeStream
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeList:
+		return "list"
+	case TypeSet:
+		return "set"
+	case TypeZSet:
+		return "zset"
+	case TypeHash:
+		return "hash"
+	case TypeStream:
+		return "stream"
+	default:
+		return "unknown"
+	}
+}
+
+// Encoding is the internal representation of a value, as reported by
+// OBJECT ENCODING.
+type Encoding int
+
+const (
+	EncodingRaw Encoding = iota
+	EncodingInt
+	EncodingEmbStr
+	EncodingListpack
+	EncodingListpackEx
+	EncodingQuicklist
+	EncodingIntset
+	EncodingHashtable
+	EncodingSkiplist
+	EncodingStream
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRaw:
+		return "raw"
+	case EncodingInt:
+		return "int"
+	case EncodingEmbStr:
+		return "embstr"
+	case EncodingListpack:
+		return "listpack"
+	case EncodingListpackEx:
+		return "listpackex"
+	case EncodingQuicklist:
+		return "quicklist"
+	case EncodingIntset:
+		return "intset"
+	case EncodingHashtable:
+		return "hashtable"
+	case EncodingSkiplist:
+		return "skiplist"
+	case EncodingStream:
+		return "stream"
+	default:
+		return "unknown"
+	}
+}
+
+// Value is what a key of the store holds.
+type Value interface {
+	Type() ValueType
+	Encoding() Encoding
+}
+
+// Longest string Redis embeds in the same allocation as its object header
+const embStrMaxLength = 44
+
+// StringValue is the value of a string key. Like every value held by the
+// store, its bytes may be shared with replies and must not be overwritten.
+type StringValue []byte
+
+func (StringValue) Type() ValueType {
+	return TypeString
+}
+
+// Encoding is derived from the content, the way Redis encodes a string it
+// stores: as an integer if it is the canonical form of one, embedded if it
+// is short, raw otherwise.
+func (v StringValue) Encoding() Encoding {
+	if _, ok := parseCanonicalInt(string(v)); ok {
+		return EncodingInt
+	}
+	if len(v) <= embStrMaxLength {
+		return EncodingEmbStr
+	}
+	return EncodingRaw
+}
+
+// parseCanonicalInt parses the string as an integer if it is the canonical
+// form of one, which is when Redis stores a string as an integer.
+func parseCanonicalInt(str string) (int64, bool) {
+	if len(str) == 0 || len(str) > 20 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != str {
+		return 0, false
+	}
+	return n, true
+}
+
+// cloneValue returns a copy of the value that shares nothing with it that
+// can be modified.
+func cloneValue(value Value) Value {
+	switch v := value.(type) {
+	case StringValue:
+		return StringValue(bytes.Clone(v))
+	case *List:
+		return cloneList(v)
+	case *Hash:
+		return cloneHash(v)
+	case *Set:
+		return cloneSet(v)
+	case *ZSet:
+		return cloneZSet(v)
+	default:
+		panic("cloneValue: unsupported value type " + value.Type().String())
+	}
+}
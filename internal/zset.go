@@ -0,0 +1,473 @@
+package internal
+
+import (
+	"math/rand/v2"
+)
+
+const (
+	// Levels of the skiplist of a sorted set, as in Redis
+	skiplistMaxLevel = 32
+	// Probability of a node reaching the next level
+	skiplistP = 0.25
+)
+
+// ZSet is the value of a sorted set key. Like Redis, it keeps the members in
+// a skiplist ordered by score and then member, where each link also records
+// how many nodes it spans so that ranks are found in O(log n), and in a dict
+// from member to score.
+type ZSet struct {
+	scores *dict[float64]
+	zsl    *skiplist
+}
+
+// ZSetEntry is a member of a sorted set with its score.
+type ZSetEntry struct {
+	Member string
+	Score  float64
+}
+
+func newZSet() *ZSet {
+	return &ZSet{scores: newDict[float64](), zsl: newSkiplist()}
+}
+
+func (*ZSet) Type() ValueType {
+	return TypeZSet
+}
+
+func (*ZSet) Encoding() Encoding {
+	return EncodingSkiplist
+}
+
+func (z *ZSet) Len() int {
+	return z.zsl.length
+}
+
+// Score returns the score of the member. The second return value is false if
+// the member is not in the sorted set.
+func (z *ZSet) Score(member string) (float64, bool) {
+	return z.scores.get(member)
+}
+
+// Set adds the member with the score, or updates its score. Returns true if
+// the member was added. The score must not be NaN.
+func (z *ZSet) Set(member string, score float64) bool {
+	current, ok := z.scores.get(member)
+	if ok {
+		if current != score {
+			z.zsl.delete(current, member)
+			z.zsl.insert(score, member)
+			z.scores.set(member, score)
+		}
+		return false
+	}
+	z.zsl.insert(score, member)
+	z.scores.set(member, score)
+	return true
+}
+
+// Remove removes the member. Returns false if it was not in the sorted set.
+func (z *ZSet) Remove(member string) bool {
+	score, ok := z.scores.delete(member)
+	if ok {
+		z.zsl.delete(score, member)
+	}
+	return ok
+}
+
+// Rank returns the 0 based rank of the member, in ascending order of score.
+// The second return value is false if the member is not in the sorted set.
+func (z *ZSet) Rank(member string) (int, bool) {
+	score, ok := z.scores.get(member)
+	if !ok {
+		return 0, false
+	}
+	return z.zsl.rank(score, member) - 1, true
+}
+
+// RangeByRank returns the entries from the start to the stop rank, both
+// inclusive, where negative ranks count back from the last entry. Ranks count
+// from the highest score when reverse is set.
+func (z *ZSet) RangeByRank(start int, stop int, reverse bool) []ZSetEntry {
+	length := z.zsl.length
+	if start < 0 {
+		start = max(start+length, 0)
+	}
+	if stop < 0 {
+		stop += length
+	}
+	stop = min(stop, length-1)
+	if start > stop {
+		return nil
+	}
+	rank := start + 1
+	if reverse {
+		rank = length - start
+	}
+	entries := make([]ZSetEntry, 0, stop-start+1)
+	for x := z.zsl.byRank(rank); len(entries) < stop-start+1; x = x.next(reverse) {
+		entries = append(entries, ZSetEntry{Member: x.member, Score: x.score})
+	}
+	return entries
+}
+
+// RangeByScore returns the entries within the range, skipping offset of them
+// and returning at most count, or all of them if count is negative. The
+// entries are in descending order when reverse is set.
+func (z *ZSet) RangeByScore(r ScoreRange, reverse bool, offset int, count int) []ZSetEntry {
+	return z.zsl.inRange(r, reverse, offset, count)
+}
+
+// RangeByLex is RangeByScore for a range of members, which is meaningful when
+// all the members have the same score.
+func (z *ZSet) RangeByLex(r LexRange, reverse bool, offset int, count int) []ZSetEntry {
+	return z.zsl.inRange(r, reverse, offset, count)
+}
+
+// CountByScore returns the number of entries within the range.
+func (z *ZSet) CountByScore(r ScoreRange) int {
+	return z.zsl.countInRange(r)
+}
+
+// CountByLex returns the number of entries within the range of members.
+func (z *ZSet) CountByLex(r LexRange) int {
+	return z.zsl.countInRange(r)
+}
+
+// Pop removes and returns the entry with the lowest score, or the highest
+// score if highest is set. The sorted set must not be empty.
+func (z *ZSet) Pop(highest bool) ZSetEntry {
+	x := z.zsl.header.levels[0].forward
+	if highest {
+		x = z.zsl.tail
+	}
+	entry := ZSetEntry{Member: x.member, Score: x.score}
+	z.Remove(x.member)
+	return entry
+}
+
+// ForEach calls fn for every entry, in ascending order of score. fn must not
+// modify the sorted set.
+func (z *ZSet) ForEach(fn func(member string, score float64)) {
+	for x := z.zsl.header.levels[0].forward; x != nil; x = x.levels[0].forward {
+		fn(x.member, x.score)
+	}
+}
+
+// Random returns an entry picked at random. The sorted set must not be empty.
+func (z *ZSet) Random() ZSetEntry {
+	member, score, _ := z.scores.random()
+	return ZSetEntry{Member: member, Score: score}
+}
+
+// Scan calls fn for some of the entries, starting at the cursor, and returns
+// the cursor to continue from, with the guarantees of SCAN. fn must not
+// modify the sorted set.
+func (z *ZSet) Scan(cursor uint64, count int, fn func(member string, score float64)) uint64 {
+	visited := 0
+	maxIterations := count * 10
+	for {
+		cursor = z.scores.scan(cursor, func(member string, score float64) {
+			fn(member, score)
+			visited++
+		})
+		maxIterations--
+		if cursor == 0 || maxIterations == 0 || visited >= count {
+			return cursor
+		}
+	}
+}
+
+func cloneZSet(z *ZSet) *ZSet {
+	clone := newZSet()
+	z.ForEach(func(member string, score float64) {
+		clone.Set(member, score)
+	})
+	return clone
+}
+
+// ScoreRange is a range of scores, each end of which may be exclusive.
+type ScoreRange struct {
+	Min          float64
+	Max          float64
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+func (r ScoreRange) aboveMin(x *skiplistNode) bool {
+	if r.MinExclusive {
+		return x.score > r.Min
+	}
+	return x.score >= r.Min
+}
+
+func (r ScoreRange) belowMax(x *skiplistNode) bool {
+	if r.MaxExclusive {
+		return x.score < r.Max
+	}
+	return x.score <= r.Max
+}
+
+// LexRange is a range of members, compared byte by byte.
+type LexRange struct {
+	Min LexBound
+	Max LexBound
+}
+
+// LexBound is an end of a LexRange. Infinity is -1 for the "-" bound, which
+// is below every member, and 1 for the "+" bound, which is above every
+// member. Value and Exclusive only apply when Infinity is 0.
+type LexBound struct {
+	Value     string
+	Exclusive bool
+	Infinity  int
+}
+
+func (r LexRange) aboveMin(x *skiplistNode) bool {
+	switch {
+	case r.Min.Infinity != 0:
+		return r.Min.Infinity < 0
+	case r.Min.Exclusive:
+		return x.member > r.Min.Value
+	default:
+		return x.member >= r.Min.Value
+	}
+}
+
+func (r LexRange) belowMax(x *skiplistNode) bool {
+	switch {
+	case r.Max.Infinity != 0:
+		return r.Max.Infinity > 0
+	case r.Max.Exclusive:
+		return x.member < r.Max.Value
+	default:
+		return x.member <= r.Max.Value
+	}
+}
+
+// zsetRange is a range of scores or of members, which the nodes of a skiplist
+// are checked against.
+type zsetRange interface {
+	aboveMin(x *skiplistNode) bool
+	belowMax(x *skiplistNode) bool
+}
+
+type skiplist struct {
+	header *skiplistNode
+	tail   *skiplistNode
+	length int
+	level  int
+}
+
+type skiplistNode struct {
+	member   string
+	score    float64
+	backward *skiplistNode
+	levels   []skiplistLevel
+}
+
+type skiplistLevel struct {
+	forward *skiplistNode
+	// Number of nodes the link to forward skips over, counting forward
+	span int
+}
+
+func newSkiplist() *skiplist {
+	return &skiplist{
+		header: &skiplistNode{levels: make([]skiplistLevel, skiplistMaxLevel)},
+		level:  1,
+	}
+}
+
+// before reports whether the node orders before the score and member.
+func (x *skiplistNode) before(score float64, member string) bool {
+	return x.score < score || (x.score == score && x.member < member)
+}
+
+func (x *skiplistNode) next(reverse bool) *skiplistNode {
+	if reverse {
+		return x.backward
+	}
+	return x.levels[0].forward
+}
+
+func randomSkiplistLevel() int {
+	level := 1
+	for level < skiplistMaxLevel && rand.Float64() < skiplistP {
+		level++
+	}
+	return level
+}
+
+func (zsl *skiplist) insert(score float64, member string) {
+	var update [skiplistMaxLevel]*skiplistNode
+	var rank [skiplistMaxLevel]int
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		if i < zsl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && x.levels[i].forward.before(score, member) {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+	level := randomSkiplistLevel()
+	if level > zsl.level {
+		for i := zsl.level; i < level; i++ {
+			update[i] = zsl.header
+			update[i].levels[i].span = zsl.length
+		}
+		zsl.level = level
+	}
+	x = &skiplistNode{member: member, score: score, levels: make([]skiplistLevel, level)}
+	for i := range level {
+		x.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = x
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < zsl.level; i++ {
+		update[i].levels[i].span++
+	}
+	if update[0] != zsl.header {
+		x.backward = update[0]
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x
+	} else {
+		zsl.tail = x
+	}
+	zsl.length++
+}
+
+func (zsl *skiplist) delete(score float64, member string) {
+	var update [skiplistMaxLevel]*skiplistNode
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.before(score, member) {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+	x = x.levels[0].forward
+	if x == nil || x.score != score || x.member != member {
+		return
+	}
+	for i := range zsl.level {
+		if update[i].levels[i].forward == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].forward = x.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x.backward
+	} else {
+		zsl.tail = x.backward
+	}
+	for zsl.level > 1 && zsl.header.levels[zsl.level-1].forward == nil {
+		zsl.level--
+	}
+	zsl.length--
+}
+
+// rank returns the 1 based rank of the node with the score and member, which
+// must be in the skiplist.
+func (zsl *skiplist) rank(score float64, member string) int {
+	rank := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for next := x.levels[i].forward; next != nil && (next.before(score, member) ||
+			(next.score == score && next.member == member)); next = x.levels[i].forward {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if x != zsl.header && x.member == member {
+			return rank
+		}
+	}
+	return 0
+}
+
+// byRank returns the node with the 1 based rank, or nil if there is none.
+func (zsl *skiplist) byRank(rank int) *skiplistNode {
+	traversed := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= rank {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		if traversed == rank && x != zsl.header {
+			return x
+		}
+	}
+	return nil
+}
+
+// firstInRange returns the first node within the range and its 1 based rank,
+// or nil if no node is.
+func (zsl *skiplist) firstInRange(r zsetRange) (*skiplistNode, int) {
+	rank := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && !r.aboveMin(x.levels[i].forward) {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+	}
+	x = x.levels[0].forward
+	if x == nil || !r.belowMax(x) {
+		return nil, 0
+	}
+	return x, rank + 1
+}
+
+// lastInRange returns the last node within the range and its 1 based rank,
+// or nil if no node is.
+func (zsl *skiplist) lastInRange(r zsetRange) (*skiplistNode, int) {
+	rank := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && r.belowMax(x.levels[i].forward) {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+	}
+	if x == zsl.header || !r.aboveMin(x) {
+		return nil, 0
+	}
+	return x, rank
+}
+
+func (zsl *skiplist) inRange(r zsetRange, reverse bool, offset int, count int) []ZSetEntry {
+	var x *skiplistNode
+	if reverse {
+		x, _ = zsl.lastInRange(r)
+	} else {
+		x, _ = zsl.firstInRange(r)
+	}
+	for ; x != nil && offset > 0; offset-- {
+		x = x.next(reverse)
+	}
+	var entries []ZSetEntry
+	for ; x != nil && count != 0; x = x.next(reverse) {
+		if (reverse && !r.aboveMin(x)) || (!reverse && !r.belowMax(x)) {
+			break
+		}
+		entries = append(entries, ZSetEntry{Member: x.member, Score: x.score})
+		count--
+	}
+	return entries
+}
+
+func (zsl *skiplist) countInRange(r zsetRange) int {
+	_, first := zsl.firstInRange(r)
+	if first == 0 {
+		return 0
+	}
+	_, last := zsl.lastInRange(r)
+	return last - first + 1
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/rs/zerolog/log"
+)
+
+var errOutputBufferLimit = errors.New("output buffer limit reached")
+
+// outputBuffer holds the replies of a connection until they are written to
+// it. Replies are gathered while the client is pipelining and written with a
+// single syscall when flushed. Writes happen on their own goroutine, so that
+// the replies of a client that does not read them pile up here and count
+// towards the output buffer limits.
+type outputBuffer struct {
+	conn   net.Conn
+	config internal.Config
+
+	mu             sync.Mutex
+	buffer         *bytes.Buffer // replies not handed to the writer yet
+	spare          *bytes.Buffer // replies being written, reused afterwards
+	writing        int           // size of the replies being written
+	overSoftLimit  time.Time     // since when the soft limit is exceeded
+	err            error
+	flushCh        chan struct{}
+	writerFinished chan struct{}
+}
+
+func newOutputBuffer(conn net.Conn, config internal.Config) *outputBuffer {
+	o := &outputBuffer{
+		conn:           conn,
+		config:         config,
+		buffer:         &bytes.Buffer{},
+		spare:          &bytes.Buffer{},
+		flushCh:        make(chan struct{}, 1),
+		writerFinished: make(chan struct{}),
+	}
+	go o.writeLoop()
+	return o
+}
+
+// append adds the reply to the buffer, without writing it. Returns an error
+// if the reply can't be sent, because the connection failed or the client is
+// over the output buffer limits.
+func (o *outputBuffer) append(reply rtypes.RespDataType, protocol int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return o.err
+	}
+	if err := resp.EncodeResponse(reply, o.buffer, protocol); err != nil {
+		// Still reply, so that the replies stay in step with the commands
+		log.Err(err).Msgf("failed to encode reply")
+		resp.EncodeResponse(rtypes.NewSimpleError("ERR failed to encode the reply"), o.buffer, protocol)
+	}
+	o.err = o.checkLimits(time.Now())
+	return o.err
+}
+
+// checkLimits works like client-output-buffer-limit: the client is over the
+// limits if its pending replies are over the hard limit, or have been over
+// the soft limit for longer than the soft period.
+func (o *outputBuffer) checkLimits(now time.Time) error {
+	size := o.buffer.Len() + o.writing
+	if o.config.ClientOutputBufferHardLimit > 0 && size > o.config.ClientOutputBufferHardLimit {
+		return errOutputBufferLimit
+	}
+	if o.config.ClientOutputBufferSoftLimit <= 0 || size <= o.config.ClientOutputBufferSoftLimit {
+		o.overSoftLimit = time.Time{}
+		return nil
+	}
+	if o.overSoftLimit.IsZero() {
+		o.overSoftLimit = now
+	}
+	if now.Sub(o.overSoftLimit) >= o.config.ClientOutputBufferSoftPeriod {
+		return errOutputBufferLimit
+	}
+	return nil
+}
+
+// flush asks for the buffered replies to be written, without waiting for it.
+func (o *outputBuffer) flush() {
+	select {
+	case o.flushCh <- struct{}{}:
+	default:
+		// A flush is already pending and will write these replies as well
+	}
+}
+
+// close writes the buffered replies and waits for them to be written.
+func (o *outputBuffer) close() {
+	o.flush()
+	close(o.flushCh)
+	<-o.writerFinished
+}
+
+func (o *outputBuffer) writeLoop() {
+	defer close(o.writerFinished)
+	for range o.flushCh {
+		o.mu.Lock()
+		if o.err != nil {
+			o.mu.Unlock()
+			return
+		}
+		o.buffer, o.spare = o.spare, o.buffer
+		data := o.spare.Bytes()
+		o.writing = len(data)
+		o.mu.Unlock()
+
+		if len(data) == 0 {
+			continue
+		}
+		_, err := o.conn.Write(data)
+
+		o.mu.Lock()
+		o.spare.Reset()
+		o.writing = 0
+		if err != nil && o.err == nil {
+			o.err = err
+		}
+		o.mu.Unlock()
+		if err != nil {
+			log.Err(err).Msgf("failed to write replies")
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/rs/zerolog/log"
+)
+
+// How many replies a connection can wait for before it stops reading more
+// commands
+const maxPendingReplies = 1024
+
+// replyQueue sends the replies of a connection in the order its commands
+// were read, even though the commands run on different goroutines. Every
+// command reserves a slot that its handler fills in, and a single goroutine
+// waits on the slots one after the other and buffers the replies.
+type replyQueue struct {
+	client *internal.Client
+	output *outputBuffer
+	slots  chan replySlot
+	stopCh <-chan struct{}
+	done   chan struct{}
+}
+
+type replySlot struct {
+	reply chan internal.Reply
+	// Whether the client had nothing more pipelined after the command, in
+	// which case there is no reason to wait before sending the reply
+	flush bool
+}
+
+func newReplyQueue(client *internal.Client, config internal.Config, stopCh <-chan struct{}) *replyQueue {
+	q := &replyQueue{
+		client: client,
+		output: newOutputBuffer(client.Conn, config),
+		slots:  make(chan replySlot, maxPendingReplies),
+		stopCh: stopCh,
+		done:   make(chan struct{}),
+	}
+	go q.replyLoop()
+	return q
+}
+
+// reserve adds a slot for the reply of the next command at the end of the
+// queue. Blocks while too many replies are pending.
+func (q *replyQueue) reserve(flush bool) chan<- internal.Reply {
+	slot := replySlot{reply: make(chan internal.Reply, 1), flush: flush}
+	select {
+	case q.slots <- slot:
+	case <-q.stopCh:
+	}
+	return slot.reply
+}
+
+// close waits for the replies already reserved to be written. No slot can be
+// reserved after it.
+func (q *replyQueue) close() {
+	close(q.slots)
+	<-q.done
+}
+
+func (q *replyQueue) replyLoop() {
+	defer close(q.done)
+	defer q.output.close()
+	failed := false
+	for {
+		var slot replySlot
+		var ok bool
+		select {
+		case slot, ok = <-q.slots:
+		default:
+			// Caught up with the commands read so far, send what is buffered
+			q.output.flush()
+			select {
+			case slot, ok = <-q.slots:
+			case <-q.stopCh:
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+
+		var reply internal.Reply
+		select {
+		case reply = <-slot.reply:
+		default:
+			// Send the replies before it rather than hold them while the
+			// command runs, which may block for long
+			q.output.flush()
+			select {
+			case reply = <-slot.reply:
+			case <-q.stopCh:
+				return
+			}
+		}
+		// Nothing can be sent once a reply failed, but keep consuming the
+		// queue so that the connection is not blocked on it.
+		if reply.Value == nil || failed {
+			continue
+		}
+		if err := q.output.append(reply.Value, reply.Protocol); err != nil {
+			if errors.Is(err, errOutputBufferLimit) {
+				log.Warn().Msgf("closing client %d for overcoming of output buffer limits", q.client.ID)
+			}
+			// Also stops the connection from reading more commands
+			q.client.Conn.Close()
+			failed = true
+			continue
+		}
+		if slot.flush {
+			q.output.flush()
+		}
+	}
+}
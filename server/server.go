@@ -12,26 +12,40 @@ import (
 	"github.com/ram-the-coder/redisgo/internal"
 	"github.com/ram-the-coder/redisgo/internal/handlers"
 	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
 	"github.com/rs/zerolog/log"
 )
 
+// How often keys with a TTL are sampled and reclaimed in the background
+const activeExpireInterval = 100 * time.Millisecond
+
 type Server struct {
 	address                string
+	config                 internal.Config
 	listener               net.Listener
 	stopCh                 chan struct{}
 	store                  *internal.Store
 	handlingDelayMsForTest atomic.Int64
 	storeCommandCh         chan *internal.Command
 	generalCommandCh       chan *internal.Command
+	blocked                *handlers.BlockedClients
+	lastClientID           atomic.Int64
 }
 
 func NewServer(address string) *Server {
+	return NewServerWithConfig(address, internal.DefaultConfig())
+}
+
+func NewServerWithConfig(address string, config internal.Config) *Server {
+	stopCh := make(chan struct{})
 	return &Server{
 		address:          address,
-		stopCh:           make(chan struct{}),
-		store:            internal.NewStore(),
+		config:           config,
+		stopCh:           stopCh,
+		store:            internal.NewStoreWithConfig(config),
 		storeCommandCh:   make(chan *internal.Command, 50),
 		generalCommandCh: make(chan *internal.Command, 50),
+		blocked:          handlers.NewBlockedClients(stopCh),
 	}
 }
 
@@ -47,12 +61,16 @@ func (s *Server) Start() error {
 	go handlers.HandleCommands(
 		s.storeCommandCh,
 		s.stopCh,
-		handlers.GetResponseForStoreCommand(s.store),
+		handlers.GetResponseForStoreCommand(s.store, s.blocked),
+		&handlers.Cron{Interval: activeExpireInterval, Run: s.store.ActiveExpireCycle},
+		s.blocked.Tasks(),
 	)
 	go handlers.HandleCommands(
 		s.generalCommandCh,
 		s.stopCh,
 		handlers.GetResponseForGeneralCommand(),
+		nil,
+		nil,
 	)
 	go s.acceptConnectionLoop()
 	return nil
@@ -90,33 +108,125 @@ func (s *Server) acceptConnectionLoop() {
 
 func (s *Server) handleConnection(conn net.Conn) {
 	defer conn.Close()
-	reader := bufio.NewReader(conn)
-	for {
-		command, err := resp.ReadCommand(reader)
-		if err != nil {
-			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
-				log.Trace().Msgf("connection closed: %s", err)
-			} else {
-				log.Err(err).Msgf("failed to read command")
+	client := internal.NewClient(s.lastClientID.Add(1), conn)
+	replies := newReplyQueue(client, s.config, s.stopCh)
+	defer replies.close()
+	disconnect := func() {
+		client.MarkClosed()
+		s.blocked.Disconnect(client)
+	}
+	// Runs before waiting for the pending replies, which may include those
+	// of blocked commands
+	defer disconnect()
+	decoder := resp.NewDecoderWithLimits(bufio.NewReader(conn), resp.Limits{
+		MaxBulkLen:      s.config.ProtoMaxBulkLen,
+		MaxMultibulkLen: s.config.ProtoMaxMultibulkLen,
+		MaxInlineLen:    s.config.ProtoInlineMaxSize,
+	})
+	reads := make(chan commandRead, maxPendingReplies)
+	goneCh := make(chan struct{})
+	go s.readCommands(conn, decoder, reads, goneCh)
+	for read := range reads {
+		if read.err != nil {
+			var protocolErr resp.ProtocolError
+			var truncatedErr *resp.TruncatedFrameError
+			switch {
+			case errors.Is(read.err, io.EOF) || errors.Is(read.err, net.ErrClosed) || errors.As(read.err, &truncatedErr):
+				log.Trace().Msgf("connection closed: %s", read.err)
+			case errors.As(read.err, &protocolErr):
+				log.Debug().Msgf("closing connection after protocol error: %s", read.err)
+				// Like Redis, reply with the error once the commands before it
+				// have replied, then close the connection.
+				replies.reserve(true) <- internal.Reply{Value: rtypes.NewSimpleError("ERR " + protocolErr.Error()), Protocol: client.Protocol()}
+			default:
+				log.Err(read.err).Msgf("failed to read command")
 			}
 			return
 		}
-		if command != nil {
-			s.addDelayForTesting()
-			commandType, err := command.GetType()
-			if err != nil {
-				log.Err(err).Msgf("invalid command")
-			}
-			log.Trace().Msgf("Command: %s, Type: %s", command.Name, commandType)
-			command.Metadata = internal.CommandMeta{Conn: conn}
-			switch commandType {
-			case internal.CommandTypeStore:
-				s.storeCommandCh <- command
-			case internal.CommandTypeGeneral:
-				s.generalCommandCh <- command
-			}
-		} else {
+		command := read.command
+		if command == nil {
 			log.Trace().Msg("No command")
+			continue
+		}
+		s.addDelayForTesting()
+		replyCh := replies.reserve(read.flush)
+		command.Metadata = internal.CommandMeta{Client: client, Protocol: client.Protocol(), ReplyCh: replyCh}
+		spec, ok := handlers.LookupCommand(command.Name)
+		// Like Redis, the commands after a blocking command wait for it to
+		// be served, so that they can neither serve it nor change how its
+		// reply is encoded. The commands after a general command wait for
+		// it too, as it runs on another goroutine than the store commands
+		// and may change the client, like HELLO does.
+		var pendingCh chan internal.Reply
+		if ok && (spec.Flags&handlers.FlagBlocking != 0 || spec.Type == internal.CommandTypeGeneral) {
+			pendingCh = make(chan internal.Reply, 1)
+			command.Metadata.ReplyCh = pendingCh
+		}
+		if ok && spec.Type == internal.CommandTypeGeneral {
+			log.Trace().Msgf("Command: %s, Type: %s", command.Name, spec.Type)
+			s.generalCommandCh <- command
+		} else {
+			// Unknown commands are answered with an error by the store handler,
+			// which keeps the reply in order with the store commands around it.
+			log.Trace().Msgf("Command: %s, Type: %s", command.Name, internal.CommandTypeStore)
+			s.storeCommandCh <- command
+		}
+		if pendingCh != nil && !s.waitForReply(pendingCh, replyCh, goneCh, disconnect) {
+			return
+		}
+	}
+}
+
+// waitForReply passes on the reply of a command once it ran, or was served
+// if it blocked. Returns false if the connection must not run the commands
+// after it, because the client went away or the server is stopping.
+func (s *Server) waitForReply(pendingCh <-chan internal.Reply, replyCh chan<- internal.Reply,
+	goneCh <-chan struct{}, disconnect func()) bool {
+	gone := false
+	for {
+		select {
+		case reply := <-pendingCh:
+			replyCh <- reply
+			return !gone
+		case <-goneCh:
+			// Once the client is disconnected, the command is sure to get a
+			// reply, if only a nil one
+			disconnect()
+			gone, goneCh = true, nil
+		case <-s.stopCh:
+			return false
+		}
+	}
+}
+
+// commandRead is a command read from a connection, or the error that ended
+// the reading.
+type commandRead struct {
+	command *internal.Command
+	err     error
+	// Whether the client had nothing more pipelined after the command
+	flush bool
+}
+
+// readCommands reads the commands of the connection ahead of running them,
+// so that the client going away is noticed even while a blocked command
+// holds back the commands after it. Closes goneCh once nothing more can be
+// read from the connection.
+func (s *Server) readCommands(conn net.Conn, decoder *resp.Decoder, reads chan<- commandRead, goneCh chan<- struct{}) {
+	defer close(goneCh)
+	defer close(reads)
+	for {
+		command, err := decoder.ReadCommand()
+		select {
+		case reads <- commandRead{command: command, err: err, flush: decoder.Buffered() == 0}:
+		case <-s.stopCh:
+			return
+		}
+		if err != nil {
+			// Nothing is read after a protocol error, but the connection
+			// may still be waiting for a blocked command to reply first
+			io.Copy(io.Discard, conn)
+			return
 		}
 	}
 }
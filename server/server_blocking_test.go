@@ -0,0 +1,312 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// How long to give a blocking command sent by another goroutine to block
+const blockDelay = 100 * time.Millisecond
+
+// blockInBackground runs the command on a client of its own, and returns the
+// channel its result is sent to once it has had the time to block.
+func blockInBackground[T any](t *testing.T, hostPort string, run func(rdb *redis.Client) T) <-chan T {
+	rdb := getRedisClient(t, hostPort)
+	resultCh := make(chan T, 1)
+	go func() { resultCh <- run(rdb) }()
+	time.Sleep(blockDelay)
+	select {
+	case <-resultCh:
+		t.Fatal("the command did not block")
+	default:
+	}
+	return resultCh
+}
+
+func receive[T any](t *testing.T, resultCh <-chan T) T {
+	t.Helper()
+	select {
+	case result := <-resultCh:
+		return result
+	case <-time.After(3 * time.Second):
+		t.Fatal("the command is still blocked")
+		panic("unreachable")
+	}
+}
+
+func TestBlockingPopServesRightAway(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.RPush(ctx, "b", "1", "2").Err())
+	assert.Nil(t, rdb.RPush(ctx, "c", "3").Err())
+
+	assert.Equal(t, []string{"b", "1"}, rdb.BLPop(ctx, 0, "a", "b", "c").Val())
+	assert.Equal(t, []string{"c", "3"}, rdb.BRPop(ctx, 0, "a", "c", "b").Val())
+	assert.Equal(t, "2", rdb.BLMove(ctx, "b", "d", "LEFT", "RIGHT", 0).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "b", "c").Val())
+
+	assert.Nil(t, rdb.RPush(ctx, "b", "1", "2", "3").Err())
+	key, elements, err := rdb.BLMPop(ctx, 0, "right", 2, "a", "b").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "b", key)
+	assert.Equal(t, []string{"3", "2"}, elements)
+}
+
+func TestBlockingPopIsServedByPush(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	resultCh := blockInBackground(t, hostPort, func(rdb *redis.Client) []string {
+		return rdb.BRPop(ctx, 0, "a", "b").Val()
+	})
+	// Other clients are not held up by the blocked one
+	assert.Equal(t, "PONG", rdb.Ping(ctx).Val())
+	assert.Equal(t, int64(2), rdb.RPush(ctx, "b", "x", "y").Val())
+	assert.Equal(t, []string{"b", "y"}, receive(t, resultCh))
+	assert.Equal(t, []string{"x"}, rdb.LRange(ctx, "b", 0, -1).Val())
+}
+
+func TestBlockedClientsAreServedInOrder(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	var resultChs []<-chan []string
+	for range 3 {
+		resultChs = append(resultChs, blockInBackground(t, hostPort, func(rdb *redis.Client) []string {
+			return rdb.BLPop(ctx, 0, "q").Val()
+		}))
+	}
+	assert.Equal(t, int64(4), rdb.RPush(ctx, "q", "1", "2", "3", "4").Val())
+	for i, resultCh := range resultChs {
+		assert.Equal(t, []string{"q", []string{"1", "2", "3"}[i]}, receive(t, resultCh))
+	}
+	assert.Equal(t, []string{"4"}, rdb.LRange(ctx, "q", 0, -1).Val())
+}
+
+func TestBlockingPopTimeout(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	// The client library rounds timeouts up to seconds
+	start := time.Now()
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "blpop", "q", "0.2").Err())
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "blmove", "q", "d", "left", "left", "0.05").Err())
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "blmpop", "0.05", "1", "q", "left").Err())
+
+	// The command does not consume what is pushed after it timed out
+	assert.Nil(t, rdb.RPush(ctx, "q", "x").Err())
+	assert.Equal(t, int64(1), rdb.LLen(ctx, "q").Val())
+
+	conn, reader := dialTestServer(t, hostPort)
+	sendAndExpect(t, conn, reader, "BRPOP missing 0.05\r\n", "*-1\r\n")
+
+	assert.EqualError(t, rdb.Do(ctx, "blpop", "q", "-1").Err(), "ERR timeout is negative")
+	assert.EqualError(t, rdb.Do(ctx, "blpop", "q", "abc").Err(), "ERR timeout is not a float or out of range")
+	assert.EqualError(t, rdb.Do(ctx, "blmpop", "0", "1", "q", "up").Err(), "ERR syntax error")
+}
+
+func TestRepliesBeforeABlockedCommandAreSentRightAway(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	conn.Write([]byte("SET k v\r\nGET k\r\nBLPOP q 0\r\n"))
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	sendAndExpect(t, conn, reader, "", "+OK\r\n$1\r\nv\r\n")
+}
+
+func TestDisconnectedClientIsUnblocked(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	conn, _ := dialTestServer(t, hostPort)
+	conn.Write([]byte("BLPOP q 0\r\n"))
+	time.Sleep(blockDelay)
+	conn.Close()
+	time.Sleep(blockDelay)
+
+	// The element is left for the clients still connected
+	resultCh := blockInBackground(t, hostPort, func(rdb *redis.Client) []string {
+		return rdb.BLPop(ctx, 0, "q").Val()
+	})
+	assert.Equal(t, int64(1), rdb.RPush(ctx, "q", "x").Val())
+	assert.Equal(t, []string{"q", "x"}, receive(t, resultCh))
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "q").Val())
+}
+
+func TestCommandsWithTooFewKeysWhileClientsAreBlocked(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	resultCh := blockInBackground(t, hostPort, func(rdb *redis.Client) []string {
+		return rdb.BLPop(ctx, 0, "q").Val()
+	})
+	for _, args := range [][]any{{"zdiff"}, {"zunionstore", "d"}, {"lmpop"}, {"blmpop", "0"}, {"lmpop", "5", "q", "left"}} {
+		assert.Error(t, rdb.Do(ctx, args...).Err(), "%v", args)
+	}
+	assert.Equal(t, int64(1), rdb.RPush(ctx, "q", "x").Val())
+	assert.Equal(t, []string{"q", "x"}, receive(t, resultCh))
+}
+
+func TestBlockingMoveChainsToClientsBlockedOnTheDestination(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	moveCh := blockInBackground(t, hostPort, func(rdb *redis.Client) string {
+		return rdb.BLMove(ctx, "src", "dst", "RIGHT", "LEFT", 0).Val()
+	})
+	popCh := blockInBackground(t, hostPort, func(rdb *redis.Client) []string {
+		return rdb.BLPop(ctx, 0, "dst").Val()
+	})
+	assert.Equal(t, int64(1), rdb.LPush(ctx, "src", "x").Val())
+	assert.Equal(t, "x", receive(t, moveCh))
+	assert.Equal(t, []string{"dst", "x"}, receive(t, popCh))
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "src", "dst").Val())
+}
+
+func TestBlockedClientWaitsForAList(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	const wrongType = "WRONGTYPE Operation against a key holding the wrong kind of value"
+
+	assert.Nil(t, rdb.Set(ctx, "str", "x", 0).Err())
+	assert.EqualError(t, rdb.BLPop(ctx, 0, "str").Err(), wrongType)
+
+	resultCh := blockInBackground(t, hostPort, func(rdb *redis.Client) error {
+		return rdb.BLPop(ctx, 0, "q").Err()
+	})
+	// A string at the key does not unblock the client
+	assert.Nil(t, rdb.Set(ctx, "q", "x", 0).Err())
+	time.Sleep(blockDelay)
+	assert.Nil(t, rdb.Del(ctx, "q").Err())
+	assert.Nil(t, rdb.RPush(ctx, "q", "x").Err())
+	assert.Nil(t, receive(t, resultCh))
+
+	// The destination is checked when the client is served
+	moveCh := blockInBackground(t, hostPort, func(rdb *redis.Client) error {
+		return rdb.BLMove(ctx, "src", "str", "LEFT", "LEFT", 0).Err()
+	})
+	assert.Nil(t, rdb.RPush(ctx, "src", "x").Err())
+	assert.EqualError(t, receive(t, moveCh), wrongType)
+	assert.Equal(t, int64(1), rdb.LLen(ctx, "src").Val())
+}
+
+func TestBlockingSortedSetPops(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.ZAdd(ctx, "b", redis.Z{Score: 1, Member: "x"}, redis.Z{Score: 2, Member: "y"}, redis.Z{Score: 3, Member: "z"}).Err())
+
+	assert.Equal(t, &redis.ZWithKey{Key: "b", Z: redis.Z{Score: 1, Member: "x"}}, rdb.BZPopMin(ctx, 0, "a", "b").Val())
+	assert.Equal(t, &redis.ZWithKey{Key: "b", Z: redis.Z{Score: 3, Member: "z"}}, rdb.BZPopMax(ctx, 0, "a", "b").Val())
+	key, entries, err := rdb.BZMPop(ctx, 0, "min", 5, "a", "b").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "b", key)
+	assert.Equal(t, []redis.Z{{Score: 2, Member: "y"}}, entries)
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "b").Val())
+
+	// Blocked clients are served in the order they blocked, by any command
+	// storing a sorted set at one of their keys
+	minCh := blockInBackground(t, hostPort, func(rdb *redis.Client) *redis.ZWithKey {
+		return rdb.BZPopMin(ctx, 0, "q", "r").Val()
+	})
+	maxCh := blockInBackground(t, hostPort, func(rdb *redis.Client) *redis.ZWithKey {
+		return rdb.BZPopMax(ctx, 0, "r").Val()
+	})
+	mpopCh := blockInBackground(t, hostPort, func(rdb *redis.Client) []redis.Z {
+		_, entries := rdb.BZMPop(ctx, 0, "max", 2, "r").Val()
+		return entries
+	})
+	assert.Equal(t, int64(4), rdb.ZAdd(ctx, "r",
+		redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"},
+		redis.Z{Score: 3, Member: "c"}, redis.Z{Score: 4, Member: "d"}).Val())
+	assert.Equal(t, &redis.ZWithKey{Key: "r", Z: redis.Z{Score: 1, Member: "a"}}, receive(t, minCh))
+	assert.Equal(t, &redis.ZWithKey{Key: "r", Z: redis.Z{Score: 4, Member: "d"}}, receive(t, maxCh))
+	assert.Equal(t, []redis.Z{{Score: 3, Member: "c"}, {Score: 2, Member: "b"}}, receive(t, mpopCh))
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "r").Val())
+
+	storeCh := blockInBackground(t, hostPort, func(rdb *redis.Client) *redis.ZWithKey {
+		return rdb.BZPopMin(ctx, 0, "dst").Val()
+	})
+	assert.Nil(t, rdb.SAdd(ctx, "set", "m").Err())
+	assert.Equal(t, int64(1), rdb.ZUnionStore(ctx, "dst", &redis.ZStore{Keys: []string{"set"}}).Val())
+	assert.Equal(t, &redis.ZWithKey{Key: "dst", Z: redis.Z{Score: 1, Member: "m"}}, receive(t, storeCh))
+}
+
+func TestBlockingSortedSetPopTimeoutAndErrors(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	const wrongType = "WRONGTYPE Operation against a key holding the wrong kind of value"
+
+	start := time.Now()
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "bzpopmin", "q", "0.2").Err())
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "bzmpop", "0.05", "1", "q", "min").Err())
+
+	conn, reader := dialTestServer(t, hostPort)
+	sendAndExpect(t, conn, reader, "BZPOPMAX missing 0.05\r\n", "*-1\r\n")
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 1.5, Member: "a"}).Err())
+	sendAndExpect(t, conn, reader, "BZPOPMAX z 0\r\n", "*3\r\n$1\r\nz\r\n$1\r\na\r\n$3\r\n1.5\r\n")
+
+	// A list at the key does not unblock the client
+	resultCh := blockInBackground(t, hostPort, func(rdb *redis.Client) *redis.ZWithKey {
+		return rdb.BZPopMin(ctx, 0, "k").Val()
+	})
+	assert.Nil(t, rdb.RPush(ctx, "k", "x").Err())
+	assert.EqualError(t, rdb.BZPopMin(ctx, 0, "k").Err(), wrongType)
+	assert.Nil(t, rdb.Del(ctx, "k").Err())
+	assert.Nil(t, rdb.ZAdd(ctx, "k", redis.Z{Score: 2, Member: "b"}).Err())
+	assert.Equal(t, &redis.ZWithKey{Key: "k", Z: redis.Z{Score: 2, Member: "b"}}, receive(t, resultCh))
+
+	assert.EqualError(t, rdb.Do(ctx, "bzpopmin", "q", "-1").Err(), "ERR timeout is negative")
+	assert.EqualError(t, rdb.Do(ctx, "bzmpop", "0", "1", "q", "up").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "bzmpop", "0", "0", "q", "min").Err(), "ERR numkeys should be greater than 0")
+}
+
+func TestBlockedCommandHoldsBackTheCommandsAfterIt(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// The RPUSH of the same client runs once the BLPOP timed out, so it
+	// cannot serve it
+	sendAndExpect(t, conn, reader,
+		"DEL q\r\nBLPOP q 0.2\r\nRPUSH q a\r\n",
+		":0\r\n*-1\r\n:1\r\n")
+
+	// The HELLO runs after the blocked command replied, which is encoded
+	// with the protocol the command was sent with
+	start := time.Now()
+	conn.Write([]byte("BLPOP nolist 0.2\r\nHELLO 3\r\n"))
+	line, err := reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "*-1\r\n", line)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	line, err = reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "%7\r\n", line)
+}
+
+func TestBlockingSortedSetPopReplyBehindPipelinedHello(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	conn, reader := dialTestServer(t, hostPort)
+
+	conn.Write([]byte("BZPOPMIN z 0\r\nHELLO 3\r\n"))
+	time.Sleep(blockDelay)
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 1, Member: "a"}).Err())
+	sendAndExpect(t, conn, reader, "", "*3\r\n$1\r\nz\r\n$1\r\na\r\n$1\r\n1\r\n%7\r\n")
+}
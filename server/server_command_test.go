@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrongArityIsRejected(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		args     []any
+		expected string
+	}{
+		{"set without value", []any{"set", "k"}, "ERR wrong number of arguments for 'set' command"},
+		{"get without key", []any{"get"}, "ERR wrong number of arguments for 'get' command"},
+		{"get with extra argument", []any{"get", "k", "extra"}, "ERR wrong number of arguments for 'get' command"},
+		{"ttl without key", []any{"ttl"}, "ERR wrong number of arguments for 'ttl' command"},
+		{"ping with two messages", []any{"ping", "a", "b"}, "ERR wrong number of arguments for 'ping' command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rdb.Do(ctx, tt.args...).Err()
+			assert.NotNil(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+	verifyServerIsAlive(t, hostPort)
+}
+
+func TestCommand(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	commands, err := rdb.Command(ctx).Result()
+	assert.Nil(t, err)
+	count, err := rdb.Do(ctx, "command", "count").Int64()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(commands)), count)
+
+	set, ok := commands["set"]
+	assert.True(t, ok, "COMMAND should describe SET")
+	assert.Equal(t, int8(-3), set.Arity)
+	assert.Contains(t, set.Flags, "write")
+	assert.Equal(t, int8(1), set.FirstKeyPos)
+	assert.Equal(t, int8(1), set.LastKeyPos)
+	assert.Equal(t, int8(1), set.StepCount)
+	assert.Contains(t, set.ACLFlags, "@write")
+
+	get := commands["get"]
+	assert.True(t, get.ReadOnly)
+	assert.Contains(t, get.Flags, "fast")
+
+	ping := commands["ping"]
+	assert.Equal(t, int8(0), ping.FirstKeyPos)
+}
+
+func TestCommandInfo(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	info, err := rdb.Do(ctx, "command", "info", "GET", "nosuchcommand").Slice()
+	assert.Nil(t, err)
+	assert.Len(t, info, 2)
+	get, ok := info[0].([]any)
+	assert.True(t, ok)
+	assert.Len(t, get, 10)
+	assert.Equal(t, "get", get[0])
+	assert.Equal(t, int64(2), get[1])
+	assert.Nil(t, info[1], "unknown commands should be nil")
+}
+
+func TestCommandDocs(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	docs, err := rdb.Do(ctx, "command", "docs", "get").Result()
+	assert.Nil(t, err)
+	getDocs := docs.(map[any]any)["get"].(map[any]any)
+	assert.Equal(t, "Returns the string value of a key.", getDocs["summary"])
+	assert.Equal(t, "string", getDocs["group"])
+	assert.Equal(t, "1.0.0", getDocs["since"])
+}
+
+func TestCommandGetKeys(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	keys, err := rdb.CommandGetKeys(ctx, "set", "mykey", "v", "ex", "10").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"mykey"}, keys)
+
+	err = rdb.CommandGetKeys(ctx, "ping").Err()
+	assert.Equal(t, "ERR The command has no key arguments", err.Error())
+	err = rdb.CommandGetKeys(ctx, "nosuchcommand", "a").Err()
+	assert.Equal(t, "ERR Invalid command specified", err.Error())
+	err = rdb.CommandGetKeys(ctx, "get").Err()
+	assert.Equal(t, "ERR Invalid number of arguments specified for command", err.Error())
+	err = rdb.Do(ctx, "command", "foo").Err()
+	assert.Equal(t, "ERR unknown subcommand 'foo'. Try COMMAND HELP.", err.Error())
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrAndDecr(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(1), rdb.Incr(ctx, "counter").Val(), "a missing key counts from 0")
+	assert.Equal(t, int64(11), rdb.IncrBy(ctx, "counter", 10).Val())
+	assert.Equal(t, int64(10), rdb.Decr(ctx, "counter").Val())
+	assert.Equal(t, int64(-5), rdb.DecrBy(ctx, "counter", 15).Val())
+	assert.Equal(t, "-5", rdb.Get(ctx, "counter").Val())
+
+	// The TTL of the counter is kept
+	assert.Nil(t, rdb.Set(ctx, "limited", "5", time.Hour).Err())
+	assert.Equal(t, int64(6), rdb.Incr(ctx, "limited").Val())
+	assert.Greater(t, rdb.TTL(ctx, "limited").Val(), time.Duration(0))
+}
+
+func TestIncrErrors(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	for _, value := range []string{"abc", "1.5", " 1", "+1", "01", "-0", "", "99999999999999999999"} {
+		assert.Nil(t, rdb.Set(ctx, "k", value, 0).Err())
+		assert.EqualError(t, rdb.Incr(ctx, "k").Err(), "ERR value is not an integer or out of range", "value %q", value)
+		assert.Equal(t, value, rdb.Get(ctx, "k").Val(), "the value is left untouched")
+	}
+	assert.EqualError(t, rdb.Do(ctx, "incrby", "k2", "x").Err(), "ERR value is not an integer or out of range")
+
+	assert.Nil(t, rdb.Set(ctx, "max", strconv.FormatInt(math.MaxInt64, 10), 0).Err())
+	assert.EqualError(t, rdb.Incr(ctx, "max").Err(), "ERR increment or decrement would overflow")
+	assert.Nil(t, rdb.Set(ctx, "min", strconv.FormatInt(math.MinInt64, 10), 0).Err())
+	assert.EqualError(t, rdb.DecrBy(ctx, "min", 1).Err(), "ERR increment or decrement would overflow")
+	assert.EqualError(t, rdb.DecrBy(ctx, "zero", math.MinInt64).Err(), "ERR decrement would overflow")
+	assert.Equal(t, int64(math.MinInt64), rdb.IncrBy(ctx, "zero", math.MinInt64).Val())
+}
+
+func TestIncrByFloat(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.Set(ctx, "k", "10.50", 0).Err())
+	assert.Equal(t, "10.6", rdb.Do(ctx, "incrbyfloat", "k", "0.1").Val())
+	assert.Equal(t, "5.6", rdb.Do(ctx, "incrbyfloat", "k", "-5").Val())
+	assert.Nil(t, rdb.Set(ctx, "k", "5.0e3", 0).Err())
+	assert.Equal(t, "5200", rdb.Do(ctx, "incrbyfloat", "k", "2.0e2").Val(), "no exponent in the result")
+	assert.Equal(t, "3", rdb.Do(ctx, "incrbyfloat", "new", "3").Val())
+
+	// Computed with the precision of a long double like Redis
+	assert.Equal(t, "0.1", rdb.Do(ctx, "incrbyfloat", "ld", "0.1").Val())
+	assert.Equal(t, "0.3", rdb.Do(ctx, "incrbyfloat", "ld", "0.2").Val())
+	assert.Equal(t, "0", rdb.Do(ctx, "incrbyfloat", "ld", "-0.3").Val())
+
+	// Values beyond the range of a double but within that of a long double
+	// can be incremented again, and those beyond a long double are rejected
+	assert.Nil(t, rdb.Do(ctx, "incrbyfloat", "big", "1e4000").Err())
+	assert.Nil(t, rdb.Do(ctx, "incrbyfloat", "big", "1").Err())
+	assert.Nil(t, rdb.Set(ctx, "max", "1e4932", 0).Err())
+	assert.EqualError(t, rdb.Do(ctx, "incrbyfloat", "max", "1e4932").Err(), "ERR increment would produce NaN or Infinity")
+	assert.EqualError(t, rdb.Do(ctx, "incrbyfloat", "max", "1e5000").Err(), "ERR increment would produce NaN or Infinity")
+	assert.Equal(t, "1e4932", rdb.Get(ctx, "max").Val())
+
+	assert.Nil(t, rdb.Set(ctx, "s", "abc", 0).Err())
+	assert.EqualError(t, rdb.Do(ctx, "incrbyfloat", "s", "1").Err(), "ERR value is not a valid float")
+	assert.EqualError(t, rdb.Do(ctx, "incrbyfloat", "k", "x").Err(), "ERR value is not a valid float")
+	assert.EqualError(t, rdb.Do(ctx, "incrbyfloat", "k", "nan").Err(), "ERR value is not a valid float")
+	assert.EqualError(t, rdb.Do(ctx, "incrbyfloat", "k", "inf").Err(), "ERR increment would produce NaN or Infinity")
+	assert.Equal(t, "5200", rdb.Get(ctx, "k").Val())
+}
+
+func TestConcurrentIncrIsAtomic(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rdb := getRedisClient(t, hostPort)
+			for range 100 {
+				assert.Nil(t, rdb.Incr(ctx, "counter").Err())
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, "1000", getRedisClient(t, hostPort).Get(ctx, "counter").Val())
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderReadsServerReplies(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+	decoder := resp.NewDecoder(reader)
+
+	conn.Write([]byte(concatCommands("*2", "$5", "HELLO", "$1", "3")))
+	hello, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.IsType(t, &rtypes.Map{}, hello)
+
+	conn.Write([]byte(concatCommands("*3", "$7", "COMMAND", "$4", "INFO", "$3", "get")))
+	info, err := decoder.Decode()
+	assert.Nil(t, err)
+	flags := info.(*rtypes.Array).Elements[0].(*rtypes.Array).Elements[2]
+	assert.Equal(t, &rtypes.Set{Elements: []rtypes.RespDataType{
+		rtypes.NewSimpleString("readonly"), rtypes.NewSimpleString("fast"),
+	}}, flags)
+
+	conn.Write([]byte(concatCommands("*2", "$3", "GET", "$7", "missing") +
+		concatCommands("*2", "$3", "TTL", "$7", "missing")))
+	null, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, &rtypes.Null{}, null)
+
+	ttl, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, &rtypes.Int{Value: -2}, ttl)
+}
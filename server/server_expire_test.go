@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func startTestServerWithClock(t *testing.T) (*fakeClock, string) {
+	clock := &fakeClock{now: time.UnixMilli(1_700_000_000_000)}
+	s := NewServer(":0")
+	s.store = internal.NewStoreWithClock(clock)
+	s.Start()
+	t.Cleanup(func() { s.Stop() })
+
+	hostPort, err := s.getAddressListeningOn()
+	assert.Nil(t, err, "error in getting address listening on")
+	return clock, hostPort
+}
+
+func TestExpireAndTTL(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(-2), rdb.Do(ctx, "ttl", "k").Val(), "TTL of a missing key")
+	assert.Nil(t, rdb.Set(ctx, "k", "v", 0).Err())
+	assert.Equal(t, int64(-1), rdb.Do(ctx, "ttl", "k").Val(), "TTL of a key without expiry")
+
+	ok, err := rdb.Expire(ctx, "k", 10*time.Second).Result()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), rdb.Do(ctx, "ttl", "k").Val())
+	assert.Equal(t, int64(10_000), rdb.Do(ctx, "pttl", "k").Val())
+
+	clock.Advance(2500 * time.Millisecond)
+	assert.Equal(t, int64(8), rdb.Do(ctx, "ttl", "k").Val(), "TTL should be rounded")
+	assert.Equal(t, int64(7500), rdb.Do(ctx, "pttl", "k").Val())
+
+	deadline := clock.Now().Add(7500 * time.Millisecond)
+	assert.Equal(t, (deadline.UnixMilli()+500)/1000, rdb.Do(ctx, "expiretime", "k").Val())
+	assert.Equal(t, deadline.UnixMilli(), rdb.Do(ctx, "pexpiretime", "k").Val())
+
+	// Like TTL, EXPIRETIME rounds to the nearest second
+	second := clock.Now().Unix() + 100
+	assert.Nil(t, rdb.Set(ctx, "rounded", "v", 0).Err())
+	assert.Nil(t, rdb.Do(ctx, "pexpireat", "rounded", second*1000+1700).Err())
+	assert.Equal(t, second+2, rdb.Do(ctx, "expiretime", "rounded").Val())
+	assert.Nil(t, rdb.Do(ctx, "pexpireat", "rounded", second*1000+1300).Err())
+	assert.Equal(t, second+1, rdb.Do(ctx, "expiretime", "rounded").Val())
+
+	clock.Advance(7500 * time.Millisecond)
+	_, err = rdb.Get(ctx, "k").Result()
+	assert.Equal(t, redis.Nil, err, "key should have expired")
+	assert.Equal(t, int64(-2), rdb.Do(ctx, "ttl", "k").Val())
+
+	ok, err = rdb.Expire(ctx, "k", 10*time.Second).Result()
+	assert.Nil(t, err)
+	assert.False(t, ok, "EXPIRE on a missing key")
+}
+
+func TestExpireAtAndPExpire(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.Set(ctx, "a", "v", 0).Err())
+	assert.Nil(t, rdb.Set(ctx, "b", "v", 0).Err())
+	assert.Nil(t, rdb.Set(ctx, "c", "v", 0).Err())
+
+	assert.True(t, rdb.ExpireAt(ctx, "a", clock.Now().Add(5*time.Second)).Val())
+	assert.True(t, rdb.PExpire(ctx, "b", 1500*time.Millisecond).Val())
+	assert.True(t, rdb.PExpireAt(ctx, "c", clock.Now().Add(3*time.Second)).Val())
+	assert.Equal(t, int64(5000), rdb.Do(ctx, "pttl", "a").Val())
+	assert.Equal(t, int64(1500), rdb.Do(ctx, "pttl", "b").Val())
+	assert.Equal(t, int64(3000), rdb.Do(ctx, "pttl", "c").Val())
+
+	// A deadline in the past deletes the key right away
+	assert.True(t, rdb.ExpireAt(ctx, "a", clock.Now().Add(-time.Second)).Val())
+	_, err := rdb.Get(ctx, "a").Result()
+	assert.Equal(t, redis.Nil, err)
+}
+
+func TestExpireConditions(t *testing.T) {
+	_, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.Set(ctx, "k", "v", 0).Err())
+
+	assert.False(t, rdb.ExpireXX(ctx, "k", 10*time.Second).Val(), "XX on a key without TTL")
+	assert.False(t, rdb.ExpireGT(ctx, "k", 10*time.Second).Val(), "GT on a key without TTL")
+	assert.True(t, rdb.ExpireNX(ctx, "k", 10*time.Second).Val(), "NX on a key without TTL")
+	assert.False(t, rdb.ExpireNX(ctx, "k", 20*time.Second).Val(), "NX on a key with TTL")
+	assert.False(t, rdb.ExpireGT(ctx, "k", 5*time.Second).Val(), "GT with a smaller TTL")
+	assert.True(t, rdb.ExpireGT(ctx, "k", 20*time.Second).Val(), "GT with a larger TTL")
+	assert.False(t, rdb.ExpireLT(ctx, "k", 30*time.Second).Val(), "LT with a larger TTL")
+	assert.True(t, rdb.ExpireLT(ctx, "k", 5*time.Second).Val(), "LT with a smaller TTL")
+	assert.True(t, rdb.ExpireXX(ctx, "k", 7*time.Second).Val(), "XX on a key with TTL")
+	assert.Equal(t, int64(7), rdb.Do(ctx, "ttl", "k").Val())
+
+	err := rdb.Do(ctx, "expire", "k", "10", "nx", "xx").Err()
+	assert.Equal(t, "ERR NX and XX, GT or LT options at the same time are not compatible", err.Error())
+	err = rdb.Do(ctx, "expire", "k", "10", "gt", "lt").Err()
+	assert.Equal(t, "ERR GT and LT options at the same time are not compatible", err.Error())
+	err = rdb.Do(ctx, "expire", "k", "10", "foo").Err()
+	assert.Equal(t, "ERR Unsupported option foo", err.Error())
+	err = rdb.Do(ctx, "expire", "k", "ten").Err()
+	assert.Equal(t, "ERR value is not an integer or out of range", err.Error())
+	err = rdb.Do(ctx, "expire", "k", "9223372036854775807").Err()
+	assert.Equal(t, "ERR invalid expire time in 'expire' command", err.Error())
+}
+
+func TestPersist(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.False(t, rdb.Persist(ctx, "k").Val(), "PERSIST on a missing key")
+	assert.Nil(t, rdb.Set(ctx, "k", "v", 0).Err())
+	assert.False(t, rdb.Persist(ctx, "k").Val(), "PERSIST on a key without TTL")
+	assert.True(t, rdb.Expire(ctx, "k", 10*time.Second).Val())
+	assert.True(t, rdb.Persist(ctx, "k").Val(), "PERSIST on a key with TTL")
+	assert.Equal(t, int64(-1), rdb.Do(ctx, "ttl", "k").Val())
+
+	clock.Advance(time.Minute)
+	val, err := rdb.Get(ctx, "k").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v", val)
+}
+
+func TestActiveExpiry(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	for i := range 100 {
+		assert.Nil(t, rdb.Set(ctx, fmt.Sprintf("volatile-%d", i), "v", time.Second).Err())
+	}
+	assert.Nil(t, rdb.Set(ctx, "persistent", "v", 0).Err())
+	assert.Equal(t, int64(101), rdb.DBSize(ctx).Val())
+
+	clock.Advance(2 * time.Second)
+	// The expired keys are never read, so only the active expire cycle can reclaim them
+	assert.Eventually(t, func() bool {
+		return rdb.DBSize(ctx).Val() == 1
+	}, 2*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHExpireAndHTTL(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "1", "b", "2", "c", "3").Err())
+
+	assert.Equal(t, []int64{1, 1, -2}, rdb.HExpire(ctx, "h", 10*time.Second, "a", "b", "missing").Val())
+	assert.Equal(t, []int64{10, 10, -1, -2}, rdb.HTTL(ctx, "h", "a", "b", "c", "missing").Val())
+	assert.Equal(t, []int64{10000}, rdb.HPTTL(ctx, "h", "a").Val())
+	nowMillis := clock.Now().UnixMilli()
+	assert.Equal(t, []int64{nowMillis/1000 + 10}, rdb.HExpireTime(ctx, "h", "a").Val())
+	assert.Equal(t, []int64{nowMillis + 10000}, rdb.HPExpireTime(ctx, "h", "a").Val())
+	assert.Equal(t, "listpackex", rdb.ObjectEncoding(ctx, "h").Val())
+
+	// Seconds are rounded up
+	clock.Advance(9500 * time.Millisecond)
+	assert.Equal(t, []int64{1}, rdb.HTTL(ctx, "h", "a").Val())
+	assert.Equal(t, []int64{500}, rdb.HPTTL(ctx, "h", "a").Val())
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, redis.Nil, rdb.HGet(ctx, "h", "a").Err())
+	assert.Equal(t, map[string]string{"c": "3"}, rdb.HGetAll(ctx, "h").Val())
+	assert.Equal(t, int64(1), rdb.HLen(ctx, "h").Val())
+	assert.Equal(t, []int64{-2}, rdb.HTTL(ctx, "h", "a").Val())
+
+	// A time in the past deletes the fields, and the hash with the last one
+	assert.Equal(t, []int64{2}, rdb.HPExpireAt(ctx, "h", clock.Now().Add(-time.Second), "c").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "h").Val())
+	assert.Equal(t, []int64{-2, -2}, rdb.HExpire(ctx, "h", time.Second, "a", "b").Val())
+	assert.Equal(t, []int64{-2}, rdb.HTTL(ctx, "h", "a").Val())
+}
+
+func TestHExpireConditions(t *testing.T) {
+	_, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.HSet(ctx, "h", "ttl", "1", "nottl", "2").Err())
+	assert.Nil(t, rdb.HExpire(ctx, "h", 100*time.Second, "ttl").Err())
+
+	tests := []struct {
+		condition string
+		seconds   int
+		expected  []any
+	}{
+		{"NX", 50, []any{int64(0), int64(1)}},
+		{"XX", 200, []any{int64(1), int64(1)}},
+		{"GT", 100, []any{int64(0), int64(0)}},
+		{"GT", 300, []any{int64(1), int64(1)}},
+		{"LT", 400, []any{int64(0), int64(0)}},
+		{"LT", 10, []any{int64(1), int64(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.condition, tt.seconds), func(t *testing.T) {
+			reply, err := rdb.Do(ctx, "hexpire", "h", tt.seconds, tt.condition, "fields", 2, "ttl", "nottl").Result()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, reply)
+		})
+	}
+	// Fields without a TTL count as an infinite one for LT
+	assert.Nil(t, rdb.HSet(ctx, "h", "fresh", "3").Err())
+	assert.Equal(t, []int64{1}, rdb.HExpireWithArgs(ctx, "h", time.Second, redis.HExpireArgs{LT: true}, "fresh").Val())
+}
+
+func TestHashFieldTTLArguments(t *testing.T) {
+	_, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "1").Err())
+	assert.Nil(t, rdb.Set(ctx, "s", "v", 0).Err())
+
+	tests := []struct {
+		args     []any
+		expected string
+	}{
+		{[]any{"hexpire", "h", "10", "fields", "2", "a"}, "ERR The `numfields` parameter must match the number of arguments"},
+		{[]any{"hexpire", "h", "10", "fields", "0", "a"}, "ERR Parameter `numFields` should be greater than 0"},
+		{[]any{"hexpire", "h", "10", "field", "1", "a"}, "ERR Mandatory argument FIELDS is missing or not at the right position"},
+		{[]any{"hexpire", "h", "10", "nx", "xx", "fields", "1", "a"}, "ERR Mandatory argument FIELDS is missing or not at the right position"},
+		{[]any{"hexpire", "h", "x", "fields", "1", "a"}, "ERR value is not an integer or out of range"},
+		{[]any{"hexpire", "h", "-1", "fields", "1", "a"}, "ERR invalid expire time, must be >= 0"},
+		{[]any{"hexpire", "h", "9000000000000000", "fields", "1", "a"}, "ERR invalid expire time in 'hexpire' command"},
+		{[]any{"httl", "h", "fields", "1"}, "ERR wrong number of arguments for 'httl' command"},
+		{[]any{"hgetex", "h", "ex", "10", "persist", "fields", "1", "a"}, "ERR syntax error"},
+		{[]any{"hgetex", "h", "ex", "0", "fields", "1", "a"}, "ERR invalid expire time in 'hgetex' command"},
+		{[]any{"hsetex", "h", "fields", "1", "a"}, "ERR wrong number of arguments for 'hsetex' command"},
+		{[]any{"hsetex", "h", "fields", "2", "a", "1"}, "ERR The `numfields` parameter must match the number of arguments"},
+		{[]any{"hsetex", "h", "ex", "10", "keepttl", "fields", "1", "a", "1"}, "ERR syntax error"},
+		{[]any{"hexpire", "s", "10", "fields", "1", "a"}, "WRONGTYPE Operation against a key holding the wrong kind of value"},
+		{[]any{"httl", "s", "fields", "1", "a"}, "WRONGTYPE Operation against a key holding the wrong kind of value"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.args...), func(t *testing.T) {
+			assert.EqualError(t, rdb.Do(ctx, tt.args...).Err(), tt.expected)
+		})
+	}
+}
+
+func TestHPersist(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "1", "b", "2").Err())
+	assert.Nil(t, rdb.HExpire(ctx, "h", time.Second, "a").Err())
+
+	assert.Equal(t, []int64{1, -1, -2}, rdb.HPersist(ctx, "h", "a", "b", "missing").Val())
+	assert.Equal(t, []int64{-1}, rdb.HTTL(ctx, "h", "a").Val())
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, "1", rdb.HGet(ctx, "h", "a").Val())
+
+	// Setting a field clears its TTL, incrementing it does not
+	assert.Nil(t, rdb.HExpire(ctx, "h", time.Second, "a", "b").Err())
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "10").Err())
+	assert.Equal(t, int64(3), rdb.HIncrBy(ctx, "h", "b", 1).Val())
+	assert.Equal(t, []int64{-1, 1}, rdb.HTTL(ctx, "h", "a", "b").Val())
+}
+
+func TestHGetEx(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "1", "b", "2").Err())
+
+	reply, err := rdb.Do(ctx, "hgetex", "h", "px", "1500", "fields", "2", "a", "missing").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, []any{"1", nil}, reply)
+	assert.Equal(t, []int64{1500, -1}, rdb.HPTTL(ctx, "h", "a", "b").Val())
+
+	assert.Equal(t, []string{"1"}, rdb.HGetEXWithArgs(ctx, "h", &redis.HGetEXOptions{
+		ExpirationType: redis.HGetEXExpirationPERSIST,
+	}, "a").Val())
+	assert.Equal(t, []int64{-1}, rdb.HTTL(ctx, "h", "a").Val())
+
+	// Values are still returned when their fields are deleted by a past time
+	assert.Equal(t, []string{"1", "2"}, rdb.HGetEXWithArgs(ctx, "h", &redis.HGetEXOptions{
+		ExpirationType: redis.HGetEXExpirationPXAT,
+		ExpirationVal:  clock.Now().Add(-time.Second).UnixMilli(),
+	}, "a", "b").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "h").Val())
+
+	reply, err = rdb.Do(ctx, "hgetex", "h", "fields", "1", "a").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, []any{nil}, reply)
+}
+
+func TestHSetEx(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(1), rdb.HSetEXWithArgs(ctx, "h", &redis.HSetEXOptions{
+		ExpirationType: redis.HSetEXExpirationEX,
+		ExpirationVal:  10,
+	}, "a", "1", "b", "2").Val())
+	assert.Equal(t, []int64{10, 10}, rdb.HTTL(ctx, "h", "a", "b").Val())
+
+	// FNX needs none of the fields to exist, FXX all of them
+	assert.Equal(t, int64(0), rdb.HSetEXWithArgs(ctx, "h", &redis.HSetEXOptions{Condition: redis.HSetEXFNX}, "a", "x", "c", "3").Val())
+	assert.Equal(t, int64(0), rdb.HSetEXWithArgs(ctx, "h", &redis.HSetEXOptions{Condition: redis.HSetEXFXX}, "a", "x", "c", "3").Val())
+	assert.Equal(t, int64(0), rdb.HSetEXWithArgs(ctx, "nokey", &redis.HSetEXOptions{Condition: redis.HSetEXFXX}, "a", "x").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "nokey").Val())
+	assert.Equal(t, "1", rdb.HGet(ctx, "h", "a").Val())
+
+	// KEEPTTL keeps the TTL of the fields, which is cleared otherwise
+	assert.Equal(t, int64(1), rdb.HSetEXWithArgs(ctx, "h", &redis.HSetEXOptions{
+		Condition:      redis.HSetEXFXX,
+		ExpirationType: redis.HSetEXExpirationKEEPTTL,
+	}, "a", "10").Val())
+	assert.Equal(t, int64(1), rdb.HSetEX(ctx, "h", "b", "20").Val())
+	assert.Equal(t, []int64{10, -1}, rdb.HTTL(ctx, "h", "a", "b").Val())
+	assert.Equal(t, map[string]string{"a": "10", "b": "20"}, rdb.HGetAll(ctx, "h").Val())
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, map[string]string{"b": "20"}, rdb.HGetAll(ctx, "h").Val())
+}
+
+func TestHashFieldsOnLargeHashes(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	fields := make([]string, 0, 500)
+	for i := range 500 {
+		fields = append(fields, fmt.Sprintf("f%d", i))
+		assert.Nil(t, rdb.HSet(ctx, "h", fields[i], i).Err())
+	}
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "h").Val())
+	// Every other field expires, at one of two deadlines
+	for i := 0; i < len(fields); i += 2 {
+		assert.Nil(t, rdb.HPExpire(ctx, "h", time.Duration(1000+i%4*500)*time.Millisecond, fields[i]).Err())
+	}
+	// Giving the same fields a new TTL many times does not change when they
+	// expire
+	for range 5 {
+		assert.Nil(t, rdb.HPExpire(ctx, "h", 1000*time.Millisecond, fields[0]).Err())
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.Equal(t, int64(375), rdb.HLen(ctx, "h").Val())
+	clock.Advance(time.Second)
+	assert.Equal(t, int64(250), rdb.HLen(ctx, "h").Val())
+	assert.Equal(t, redis.Nil, rdb.HGet(ctx, "h", "f2").Err())
+	assert.Equal(t, "3", rdb.HGet(ctx, "h", "f3").Val())
+
+	// Copies keep the TTLs of the fields
+	assert.Nil(t, rdb.HExpire(ctx, "h", time.Second, "f1").Err())
+	assert.Equal(t, int64(1), rdb.Copy(ctx, "h", "copy", 0, false).Val())
+	assert.Equal(t, []int64{1}, rdb.HTTL(ctx, "copy", "f1").Val())
+	clock.Advance(time.Second)
+	assert.Equal(t, int64(249), rdb.HLen(ctx, "copy").Val())
+}
+
+func TestActiveHashFieldExpiry(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	for i := range 50 {
+		key := fmt.Sprintf("volatile-%d", i)
+		assert.Nil(t, rdb.HSet(ctx, key, "a", "1", "b", "2").Err())
+		assert.Nil(t, rdb.HExpire(ctx, key, time.Second, "a", "b").Err())
+	}
+	assert.Nil(t, rdb.HSet(ctx, "persistent", "a", "1").Err())
+	assert.Nil(t, rdb.Rename(ctx, "volatile-0", "renamed").Err())
+	assert.Equal(t, int64(51), rdb.DBSize(ctx).Val())
+
+	clock.Advance(2 * time.Second)
+	// The hashes are never read, so only the active expire cycle can delete
+	// them once all their fields expired
+	assert.Eventually(t, func() bool {
+		return rdb.DBSize(ctx).Val() == 1
+	}, 2*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashCommands(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(2), rdb.HSet(ctx, "h", "a", "1", "b", "22").Val())
+	assert.Equal(t, int64(1), rdb.HSet(ctx, "h", "a", "10", "c", "333").Val(), "only new fields are counted")
+	assert.True(t, rdb.HMSet(ctx, "h", "d", "4").Val())
+	assert.Equal(t, "10", rdb.HGet(ctx, "h", "a").Val())
+	assert.Equal(t, redis.Nil, rdb.HGet(ctx, "h", "missing").Err())
+	assert.Equal(t, redis.Nil, rdb.HGet(ctx, "nokey", "a").Err())
+	assert.Equal(t, []any{"10", nil, "333"}, rdb.HMGet(ctx, "h", "a", "missing", "c").Val())
+	assert.Equal(t, []any{nil}, rdb.HMGet(ctx, "nokey", "a").Val())
+	assert.EqualError(t, rdb.Do(ctx, "hset", "h", "a", "1", "b").Err(), "ERR wrong number of arguments for 'hset' command")
+
+	assert.Equal(t, int64(4), rdb.HLen(ctx, "h").Val())
+	assert.True(t, rdb.HExists(ctx, "h", "b").Val())
+	assert.False(t, rdb.HExists(ctx, "h", "missing").Val())
+	assert.Equal(t, int64(3), rdb.HStrLen(ctx, "h", "c").Val())
+	assert.Equal(t, int64(0), rdb.HStrLen(ctx, "h", "missing").Val())
+
+	// Small hashes keep their fields in insertion order
+	assert.Equal(t, []string{"a", "b", "c", "d"}, rdb.HKeys(ctx, "h").Val())
+	assert.Equal(t, []string{"10", "22", "333", "4"}, rdb.HVals(ctx, "h").Val())
+	assert.Equal(t, map[string]string{"a": "10", "b": "22", "c": "333", "d": "4"}, rdb.HGetAll(ctx, "h").Val())
+	assert.Equal(t, map[string]string{}, rdb.HGetAll(ctx, "nokey").Val())
+	assert.Equal(t, []string{}, rdb.HKeys(ctx, "nokey").Val())
+
+	assert.False(t, rdb.HSetNX(ctx, "h", "a", "new").Val())
+	assert.True(t, rdb.HSetNX(ctx, "h", "e", "5").Val())
+	assert.Equal(t, "10", rdb.HGet(ctx, "h", "a").Val())
+
+	assert.Equal(t, int64(2), rdb.HDel(ctx, "h", "a", "b", "missing").Val())
+	assert.Equal(t, int64(3), rdb.HDel(ctx, "h", "c", "d", "e").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "h").Val(), "the key is deleted with its last field")
+	assert.Equal(t, int64(0), rdb.HDel(ctx, "h", "a").Val())
+}
+
+func TestHGetAllReplies(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	sendAndExpect(t, conn, reader, "HSET h a 1 b 2\r\n", ":2\r\n")
+	// RESP2 clients get the fields and values in a flat array
+	sendAndExpect(t, conn, reader, "HGETALL h\r\n", "*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\nb\r\n$1\r\n2\r\n")
+	sendAndExpect(t, conn, reader, "HGETALL nokey\r\n", "*0\r\n")
+
+	// and RESP3 clients a map
+	decoder := resp.NewDecoder(reader)
+	conn.Write([]byte("HELLO 3\r\n"))
+	_, err := decoder.Decode()
+	assert.Nil(t, err)
+	conn.Write([]byte("HGETALL h\r\n"))
+	reply, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, &rtypes.Map{KvPairs: [][2]rtypes.RespDataType{
+		{rtypes.NewBulkString("a"), rtypes.NewBulkString("1")},
+		{rtypes.NewBulkString("b"), rtypes.NewBulkString("2")},
+	}}, reply)
+}
+
+func TestHIncrBy(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(5), rdb.HIncrBy(ctx, "h", "n", 5).Val())
+	assert.Equal(t, int64(2), rdb.HIncrBy(ctx, "h", "n", -3).Val())
+	assert.Equal(t, 2.5, rdb.HIncrByFloat(ctx, "h", "n", 0.5).Val())
+	assert.Equal(t, "2.5", rdb.HGet(ctx, "h", "n").Val())
+	assert.Equal(t, 1e20, rdb.HIncrByFloat(ctx, "h", "f", 1e20).Val())
+	assert.Equal(t, "100000000000000000000", rdb.HGet(ctx, "h", "f").Val(), "floats are written without an exponent")
+	assert.Equal(t, "0.1", rdb.Do(ctx, "hincrbyfloat", "h", "ld", "0.1").Val())
+	assert.Equal(t, "0.3", rdb.Do(ctx, "hincrbyfloat", "h", "ld", "0.2").Val())
+	assert.Nil(t, rdb.Do(ctx, "hincrbyfloat", "h", "big", "1e4932").Err())
+	assert.EqualError(t, rdb.Do(ctx, "hincrbyfloat", "h", "big", "1e4932").Err(), "ERR increment would produce NaN or Infinity")
+
+	assert.EqualError(t, rdb.HIncrBy(ctx, "h", "n", 1).Err(), "ERR hash value is not an integer")
+	assert.Nil(t, rdb.HSet(ctx, "h", "s", "abc").Err())
+	assert.EqualError(t, rdb.HIncrByFloat(ctx, "h", "s", 1).Err(), "ERR hash value is not a float")
+	assert.Nil(t, rdb.HSet(ctx, "h", "max", strconv.FormatInt(1<<63-1, 10)).Err())
+	assert.EqualError(t, rdb.HIncrBy(ctx, "h", "max", 1).Err(), "ERR increment or decrement would overflow")
+	assert.EqualError(t, rdb.Do(ctx, "hincrby", "h", "n", "x").Err(), "ERR value is not an integer or out of range")
+	assert.EqualError(t, rdb.Do(ctx, "hincrbyfloat", "h", "n", "x").Err(), "ERR value is not a valid float")
+
+	// A failed increment does not leave an empty hash behind
+	assert.EqualError(t, rdb.Do(ctx, "hincrbyfloat", "other", "n", "inf").Err(), "ERR increment would produce NaN or Infinity")
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "other").Val())
+}
+
+func TestHRandField(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	fields := map[string]string{}
+	for i := range 10 {
+		fields["f"+strconv.Itoa(i)] = "v" + strconv.Itoa(i)
+	}
+	assert.Nil(t, rdb.HSet(ctx, "h", fields).Err())
+
+	assert.Contains(t, fields, rdb.HRandField(ctx, "h", 1).Val()[0])
+	for _, count := range []int{3, 8, 10, 20} {
+		picked := rdb.HRandField(ctx, "h", count).Val()
+		assert.Len(t, picked, min(count, 10))
+		unique := map[string]bool{}
+		for _, field := range picked {
+			assert.Contains(t, fields, field)
+			unique[field] = true
+		}
+		assert.Len(t, unique, len(picked), "a positive count picks distinct fields")
+	}
+	// A negative count may pick the same field several times
+	assert.Len(t, rdb.HRandField(ctx, "h", -25).Val(), 25)
+	for _, kv := range rdb.HRandFieldWithValues(ctx, "h", -5).Val() {
+		assert.Equal(t, fields[kv.Key], kv.Value)
+	}
+
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "hrandfield", "nokey").Err())
+	assert.Equal(t, []string{}, rdb.HRandField(ctx, "nokey", 3).Val())
+	assert.Equal(t, []string{}, rdb.HRandField(ctx, "h", 0).Val())
+	assert.EqualError(t, rdb.Do(ctx, "hrandfield", "h", "1", "values").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "hrandfield", "h", "x").Err(), "ERR value is not an integer or out of range")
+
+	// Fields and values come in pairs for RESP3 clients and flat otherwise
+	conn, reader := dialTestServer(t, hostPort)
+	sendAndExpect(t, conn, reader, "HSET one a 1\r\n", ":1\r\n")
+	sendAndExpect(t, conn, reader, "HRANDFIELD one -2 WITHVALUES\r\n", "*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\na\r\n$1\r\n1\r\n")
+	decoder := resp.NewDecoder(reader)
+	conn.Write([]byte("HELLO 3\r\n"))
+	_, err := decoder.Decode()
+	assert.Nil(t, err)
+	conn.Write([]byte("HRANDFIELD one 1 WITHVALUES\r\n"))
+	reply, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, &rtypes.Array{Elements: []rtypes.RespDataType{
+		&rtypes.Array{Elements: []rtypes.RespDataType{rtypes.NewBulkString("a"), rtypes.NewBulkString("1")}},
+	}}, reply)
+}
+
+func TestHScan(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	// A small hash is returned whole by the first call
+	assert.Nil(t, rdb.HSet(ctx, "small", "a", "1", "b", "2", "ab", "3").Err())
+	fields, cursor := rdb.HScan(ctx, "small", 0, "", 1).Val()
+	assert.Equal(t, uint64(0), cursor)
+	assert.Equal(t, []string{"a", "1", "b", "2", "ab", "3"}, fields)
+	fields, _ = rdb.HScan(ctx, "small", 0, "a*", 0).Val()
+	assert.Equal(t, []string{"a", "1", "ab", "3"}, fields)
+	fields, _ = rdb.HScanNoValues(ctx, "small", 0, "", 0).Val()
+	assert.Equal(t, []string{"a", "b", "ab"}, fields)
+
+	// A large one is walked with the cursor
+	expected := make([]string, 0, 1000)
+	for i := range 1000 {
+		field := "f" + strconv.Itoa(i)
+		expected = append(expected, field)
+		assert.Nil(t, rdb.HSet(ctx, "large", field, i).Err())
+	}
+	seen := map[string]bool{}
+	calls := 0
+	for cursor = 0; ; calls++ {
+		fields, cursor = rdb.HScanNoValues(ctx, "large", cursor, "", 20).Val()
+		for _, field := range fields {
+			seen[field] = true
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Greater(t, calls, 1)
+	actual := make([]string, 0, len(seen))
+	for field := range seen {
+		actual = append(actual, field)
+	}
+	sort.Strings(actual)
+	sort.Strings(expected)
+	assert.Equal(t, expected, actual)
+
+	fields, cursor = rdb.HScan(ctx, "nokey", 0, "", 0).Val()
+	assert.Equal(t, uint64(0), cursor)
+	assert.Empty(t, fields)
+	assert.EqualError(t, rdb.Do(ctx, "hscan", "small", "x").Err(), "ERR invalid cursor")
+	assert.EqualError(t, rdb.Do(ctx, "hscan", "small", "0", "count", "0").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "hscan", "small", "0", "match").Err(), "ERR syntax error")
+}
+
+func TestHashEncoding(t *testing.T) {
+	config := internal.DefaultConfig()
+	config.HashMaxListpackEntries = 4
+	config.HashMaxListpackValue = 8
+	hostPort := startTestServerWithConfig(t, config)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "1", "b", "2", "c", "3", "d", "4").Err())
+	assert.Equal(t, "listpack", rdb.ObjectEncoding(ctx, "h").Val())
+	assert.Nil(t, rdb.HSet(ctx, "h", "e", "5").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "h").Val(), "converted past the entries limit")
+	// and not converted back once it shrinks
+	assert.Nil(t, rdb.HDel(ctx, "h", "a", "b", "c").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "h").Val())
+	assert.Equal(t, map[string]string{"d": "4", "e": "5"}, rdb.HGetAll(ctx, "h").Val())
+	for _, field := range rdb.HRandField(ctx, "h", -10).Val() {
+		assert.Contains(t, []string{"d", "e"}, field)
+	}
+
+	assert.Nil(t, rdb.HSet(ctx, "v", "a", "12345678").Err())
+	assert.Equal(t, "listpack", rdb.ObjectEncoding(ctx, "v").Val())
+	assert.Nil(t, rdb.HSet(ctx, "v", "b", "123456789").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "v").Val(), "converted past the value limit")
+	assert.Nil(t, rdb.HSet(ctx, "f", strings.Repeat("x", 9), "1").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "f").Val(), "fields count towards the value limit")
+
+	// Copies keep the encoding and the fields
+	assert.Nil(t, rdb.Copy(ctx, "h", "h2", 0, false).Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "h2").Val())
+	assert.Equal(t, map[string]string{"d": "4", "e": "5"}, rdb.HGetAll(ctx, "h2").Val())
+}
+
+func TestHashesAndWrongType(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.Set(ctx, "s", "v", 0).Err())
+	assert.Nil(t, rdb.HSet(ctx, "h", "a", "1").Err())
+
+	wrongType := "WRONGTYPE Operation against a key holding the wrong kind of value"
+	assert.EqualError(t, rdb.HSet(ctx, "s", "a", "1").Err(), wrongType)
+	assert.EqualError(t, rdb.HGet(ctx, "s", "a").Err(), wrongType)
+	assert.EqualError(t, rdb.HGetAll(ctx, "s").Err(), wrongType)
+	assert.EqualError(t, rdb.HIncrBy(ctx, "s", "a", 1).Err(), wrongType)
+	assert.EqualError(t, rdb.HScan(ctx, "s", 0, "", 0).Err(), wrongType)
+	assert.EqualError(t, rdb.Get(ctx, "h").Err(), wrongType)
+	assert.EqualError(t, rdb.LPush(ctx, "h", "a").Err(), wrongType)
+	assert.Equal(t, "hash", rdb.Type(ctx, "h").Val())
+}
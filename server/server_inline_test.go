@@ -0,0 +1,62 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestInlineCommands(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	sendAndExpect(t, conn, reader, "PING\r\n", "+PONG\r\n")
+	sendAndExpect(t, conn, reader, "ping\n", "+PONG\r\n")
+	sendAndExpect(t, conn, reader, "  ping   hello  \r\n", "$5\r\nhello\r\n")
+	// Empty lines are ignored
+	sendAndExpect(t, conn, reader, "\r\n\r\nPING\r\n", "+PONG\r\n")
+
+	sendAndExpect(t, conn, reader, "SET a \"hello world\"\r\n", "+OK\r\n")
+	sendAndExpect(t, conn, reader, "GET a\r\n", "$11\r\nhello world\r\n")
+
+	// Inline and multibulk commands can be mixed
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$3", "GET", "$1", "a")+"GET a\r\n",
+		"$11\r\nhello world\r\n$11\r\nhello world\r\n")
+}
+
+func TestInlineCommandQuoting(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"double quote escapes", `"a\tb\nc\\d\"e"`, "a\tb\nc\\d\"e"},
+		{"hex escape", `"\x41\x7a\x00"`, "Az\x00"},
+		{"invalid hex escape", `"\xZZ"`, "xZZ"},
+		{"single quotes", `'it\'s \n raw'`, `it's \n raw`},
+		{"quotes inside an unquoted argument", `foo"bar baz"`, "foobar baz"},
+		{"empty quotes", `""`, ""},
+	}
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sendAndExpect(t, conn, reader, "SET key "+tt.value+"\r\n", "+OK\r\n")
+			sendAndExpect(t, conn, reader, concatCommands("*2", "$3", "GET", "$3", "key"),
+				concatCommands("$"+strconv.Itoa(len(tt.expected)), tt.expected))
+		})
+	}
+}
+
+func TestInlineCommandWithUnbalancedQuotes(t *testing.T) {
+	for _, input := range []string{
+		"SET a \"hello\r\n",
+		"SET a 'hello\r\n",
+		"SET a \"hello\"world\r\n",
+	} {
+		_, hostPort := startTestServer(t)
+		conn, reader := dialTestServer(t, hostPort)
+		conn.Write([]byte(input))
+		expectProtocolError(t, reader, "unbalanced quotes in request")
+		verifyServerIsAlive(t, hostPort)
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelAndUnlink(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		assert.Nil(t, rdb.Set(ctx, key, "v", 0).Err())
+	}
+	assert.Equal(t, int64(2), rdb.Del(ctx, "a", "b", "missing", "a").Val(), "keys are deleted once")
+	assert.Equal(t, int64(1), rdb.Unlink(ctx, "c", "a").Val())
+	assert.Equal(t, int64(0), rdb.DBSize(ctx).Val())
+
+	_, err := rdb.Do(ctx, "del").Result()
+	assert.EqualError(t, err, "ERR wrong number of arguments for 'del' command")
+}
+
+func TestExistsTouchAndType(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.Set(ctx, "a", "1", 0).Err())
+	assert.Nil(t, rdb.Set(ctx, "b", "2", time.Second).Err())
+	assert.Equal(t, int64(3), rdb.Exists(ctx, "a", "a", "b", "missing").Val(), "repeated keys are counted every time")
+	assert.Equal(t, int64(2), rdb.Touch(ctx, "a", "b", "missing").Val())
+	assert.Equal(t, "string", rdb.Type(ctx, "a").Val())
+	assert.Equal(t, "none", rdb.Type(ctx, "missing").Val())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, int64(1), rdb.Exists(ctx, "a", "b").Val(), "expired keys do not exist")
+	assert.Equal(t, "none", rdb.Type(ctx, "b").Val())
+}
+
+func TestRename(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.EqualError(t, rdb.Rename(ctx, "missing", "b").Err(), "ERR no such key")
+	assert.Nil(t, rdb.Set(ctx, "a", "1", time.Hour).Err())
+	assert.Nil(t, rdb.Set(ctx, "b", "2", 0).Err())
+
+	// The TTL moves along with the value and the destination is overwritten
+	assert.Equal(t, "OK", rdb.Rename(ctx, "a", "b").Val())
+	assert.Equal(t, "1", rdb.Get(ctx, "b").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "a").Val())
+	assert.Greater(t, rdb.TTL(ctx, "b").Val(), time.Duration(0))
+	assert.Equal(t, "OK", rdb.Rename(ctx, "b", "b").Val())
+
+	assert.Nil(t, rdb.Set(ctx, "c", "3", 0).Err())
+	assert.False(t, rdb.RenameNX(ctx, "b", "c").Val(), "the destination exists")
+	assert.False(t, rdb.RenameNX(ctx, "b", "b").Val())
+	assert.True(t, rdb.RenameNX(ctx, "b", "d").Val())
+	assert.Equal(t, "1", rdb.Get(ctx, "d").Val())
+	assert.EqualError(t, rdb.RenameNX(ctx, "missing", "e").Err(), "ERR no such key")
+}
+
+func TestCopy(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(0), rdb.Copy(ctx, "missing", "b", 0, false).Val())
+	assert.Nil(t, rdb.Set(ctx, "a", "1", time.Hour).Err())
+	assert.Equal(t, int64(1), rdb.Copy(ctx, "a", "b", 0, false).Val())
+	assert.Equal(t, "1", rdb.Get(ctx, "a").Val())
+	assert.Equal(t, "1", rdb.Get(ctx, "b").Val())
+	assert.Greater(t, rdb.TTL(ctx, "b").Val(), time.Duration(0), "the TTL is copied")
+
+	assert.Nil(t, rdb.Set(ctx, "c", "3", 0).Err())
+	assert.Equal(t, int64(0), rdb.Copy(ctx, "a", "c", 0, false).Val(), "the destination exists")
+	assert.Equal(t, int64(1), rdb.Copy(ctx, "a", "c", 0, true).Val())
+	assert.Equal(t, "1", rdb.Get(ctx, "c").Val())
+
+	assert.EqualError(t, rdb.Copy(ctx, "a", "a", 0, true).Err(), "ERR source and destination objects are the same")
+	assert.EqualError(t, rdb.Copy(ctx, "a", "d", 1, false).Err(), "ERR DB index is out of range")
+	assert.EqualError(t, rdb.Do(ctx, "copy", "a", "d", "foo").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "copy", "a", "d", "db", "x").Err(), "ERR value is not an integer or out of range")
+
+	_, err := rdb.Get(ctx, "d").Result()
+	assert.Equal(t, redis.Nil, err)
+}
+
+func TestKeysPatterns(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	for _, key := range []string{"hello", "hallo", "hxllo", "hllo", "heeeello", "h*llo", "h[llo", "a-b", ""} {
+		assert.Nil(t, rdb.Set(ctx, key, "v", 0).Err())
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"*", []string{"hello", "hallo", "hxllo", "hllo", "heeeello", "h*llo", "h[llo", "a-b", ""}},
+		{"h?llo", []string{"hello", "hallo", "hxllo", "h*llo", "h[llo"}},
+		{"h*llo", []string{"hello", "hallo", "hxllo", "hllo", "heeeello", "h*llo", "h[llo"}},
+		{"h**e*llo", []string{"hello", "heeeello"}},
+		{"h[ae]llo", []string{"hello", "hallo"}},
+		{"h[^e]llo", []string{"hallo", "hxllo", "h*llo", "h[llo"}},
+		{"h[e-a]llo", []string{"hello", "hallo"}},
+		{"h\\*llo", []string{"h*llo"}},
+		{"h[\\[]llo", []string{"h[llo"}},
+		{"a[-]b", []string{"a-b"}},
+		{"h[ae", nil},
+		{"nomatch*", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			keys, err := rdb.Keys(ctx, tt.pattern).Result()
+			assert.Nil(t, err)
+			assert.ElementsMatch(t, tt.expected, keys)
+		})
+	}
+
+	// Expired keys are not listed
+	assert.Nil(t, rdb.Set(ctx, "expiring", "v", time.Millisecond).Err())
+	time.Sleep(5 * time.Millisecond)
+	assert.Empty(t, rdb.Keys(ctx, "expiring").Val())
+}
+
+func TestScan(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	expected := map[string]bool{}
+	for i := range 1000 {
+		key := fmt.Sprintf("key:%d", i)
+		assert.Nil(t, rdb.Set(ctx, key, "v", 0).Err())
+		expected[key] = true
+	}
+	assert.Nil(t, rdb.Set(ctx, "other", "v", 0).Err())
+
+	seen := scanAll(t, rdb, "key:*", 50)
+	assert.Equal(t, expected, seen)
+
+	_, cursor, err := rdb.ScanType(ctx, 0, "*", 2000, "string").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), cursor, "a large COUNT scans everything at once")
+	keys, _, err := rdb.ScanType(ctx, 0, "*", 2000, "hash").Result()
+	assert.Nil(t, err)
+	assert.Empty(t, keys)
+
+	_, err = rdb.Do(ctx, "scan", "abc").Result()
+	assert.EqualError(t, err, "ERR invalid cursor")
+	_, err = rdb.Do(ctx, "scan", "0", "count", "0").Result()
+	assert.EqualError(t, err, "ERR syntax error")
+	_, err = rdb.Do(ctx, "scan", "0", "count", "x").Result()
+	assert.EqualError(t, err, "ERR value is not an integer or out of range")
+	_, err = rdb.Do(ctx, "scan", "0", "match").Result()
+	assert.EqualError(t, err, "ERR syntax error")
+	_, err = rdb.Do(ctx, "scan", "0", "type", "foo").Result()
+	assert.EqualError(t, err, "ERR unknown type name 'foo'")
+}
+
+func TestScanWhileTheKeyspaceIsResized(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	stable := map[string]bool{}
+	for i := range 200 {
+		key := fmt.Sprintf("stable:%d", i)
+		assert.Nil(t, rdb.Set(ctx, key, "v", 0).Err())
+		stable[key] = true
+	}
+
+	// Grow the keyspace between calls, then shrink it back, so that the scan
+	// runs across several rehashes in both directions
+	seen := map[string]bool{}
+	var cursor uint64
+	for step := 0; ; step++ {
+		keys, next, err := rdb.Scan(ctx, cursor, "stable:*", 10).Result()
+		assert.Nil(t, err)
+		for _, key := range keys {
+			seen[key] = true
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+		for i := range 100 {
+			key := fmt.Sprintf("temp:%d:%d", step, i)
+			if step < 10 {
+				assert.Nil(t, rdb.Set(ctx, key, "v", 0).Err())
+			} else {
+				assert.Nil(t, rdb.Del(ctx, fmt.Sprintf("temp:%d:%d", step-10, i)).Err())
+			}
+		}
+	}
+	assert.Equal(t, stable, seen, "every key present for the whole scan is returned")
+}
+
+// scanAll runs a full SCAN and returns the keys it returned.
+func scanAll(t *testing.T, rdb *redis.Client, pattern string, count int64) map[string]bool {
+	t.Helper()
+	seen := map[string]bool{}
+	var cursor uint64
+	for {
+		keys, next, err := rdb.Scan(context.Background(), cursor, pattern, count).Result()
+		assert.Nil(t, err)
+		for _, key := range keys {
+			seen[key] = true
+		}
+		if next == 0 {
+			return seen
+		}
+		cursor = next
+	}
+}
+
+func TestObjectEncoding(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"12345", "int"},
+		{"-9223372036854775808", "int"},
+		{"012", "embstr"},
+		{"9223372036854775808", "embstr"},
+		{"hello", "embstr"},
+		{strings.Repeat("a", 44), "embstr"},
+		{strings.Repeat("a", 45), "raw"},
+	}
+	for _, tt := range tests {
+		assert.Nil(t, rdb.Set(ctx, "k", tt.value, 0).Err())
+		assert.Equal(t, tt.expected, rdb.ObjectEncoding(ctx, "k").Val(), "encoding of %q", tt.value)
+	}
+	assert.Equal(t, redis.Nil, rdb.ObjectEncoding(ctx, "missing").Err())
+	assert.EqualError(t, rdb.Do(ctx, "object", "encoding").Err(), "ERR wrong number of arguments for 'object|encoding' command")
+	assert.EqualError(t, rdb.Do(ctx, "object", "foo", "k").Err(), "ERR unknown subcommand 'foo'. Try OBJECT HELP.")
+}
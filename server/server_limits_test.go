@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHugeLengthsAreRejectedBeforeAllocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"huge bulk length", concatCommands("*2", "$3", "GET", "$9999999999"), "invalid bulk length"},
+		{"bulk length beyond int64", concatCommands("*2", "$3", "GET", "$99999999999999999999"), "invalid bulk length"},
+		{"negative bulk length", concatCommands("*2", "$3", "GET", "$-5"), "invalid bulk length"},
+		{"huge multibulk length", concatCommands("*99999999999"), "invalid multibulk length"},
+		{"non-numeric multibulk length", concatCommands("*abc"), "invalid multibulk length"},
+		{"non-bulk argument", concatCommands("*1", ":123"), "expected '$', got ':'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, hostPort := startTestServer(t)
+			conn, reader := dialTestServer(t, hostPort)
+			conn.Write([]byte(tt.input))
+			expectProtocolError(t, reader, tt.expected)
+			verifyServerIsAlive(t, hostPort)
+		})
+	}
+}
+
+func TestConfiguredProtocolLimits(t *testing.T) {
+	config := internal.DefaultConfig()
+	config.ProtoMaxBulkLen = 16
+	config.ProtoMaxMultibulkLen = 4
+	config.ProtoInlineMaxSize = 32
+	hostPort := startTestServerWithConfig(t, config)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string // the protocol error, empty if the command is accepted
+	}{
+		{"bulk at the limit", concatCommands("*3", "$3", "SET", "$1", "k", "$16", strings.Repeat("a", 16)), ""},
+		{"bulk over the limit", concatCommands("*3", "$3", "SET", "$1", "k", "$17", strings.Repeat("a", 17)), "invalid bulk length"},
+		{"multibulk at the limit", concatCommands("*4", "$3", "SET", "$1", "k", "$1", "v", "$2", "NX"), ""},
+		{"multibulk over the limit", concatCommands("*5", "$3", "SET", "$1", "k", "$1", "v", "$2", "NX", "$3", "GET"), "invalid multibulk length"},
+		{"inline at the limit", "SET k " + strings.Repeat("a", 24) + "\r\n", ""},
+		{"inline over the limit", "SET k " + strings.Repeat("a", 25) + "\r\n", "too big inline request"},
+		{"length header over the inline limit", "*" + strings.Repeat("0", 40) + "1\r\n", "too big mbulk count string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, reader := dialTestServer(t, hostPort)
+			conn.Write([]byte(tt.input))
+			if tt.expected == "" {
+				reply, err := reader.ReadString('\n')
+				assert.Nil(t, err)
+				assert.NotEqual(t, '-', reply[0], "unexpected error: %s", reply)
+			} else {
+				expectProtocolError(t, reader, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfiguredMaxStringLength(t *testing.T) {
+	config := internal.DefaultConfig()
+	config.ProtoMaxBulkLen = 16
+	hostPort := startTestServerWithConfig(t, config)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	const tooLong = "ERR string exceeds maximum allowed size (proto-max-bulk-len)"
+
+	assert.Nil(t, rdb.Set(ctx, "k", strings.Repeat("a", 10), 0).Err())
+	assert.Equal(t, int64(16), rdb.Append(ctx, "k", strings.Repeat("b", 6)).Val())
+	assert.EqualError(t, rdb.Append(ctx, "k", "c").Err(), tooLong)
+	assert.Equal(t, int64(16), rdb.SetRange(ctx, "r", 15, "x").Val())
+	assert.EqualError(t, rdb.SetRange(ctx, "r", 16, "x").Err(), tooLong)
+	assert.EqualError(t, rdb.LCS(ctx, &redis.LCSQuery{Key1: "k", Key2: "r"}).Err(),
+		"ERR Insufficient memory, transient memory for LCS exceeds proto-max-bulk-len")
+}
+
+func startTestServerWithConfig(t *testing.T, config internal.Config) string {
+	s := NewServerWithConfig(":0", config)
+	s.Start()
+	t.Cleanup(func() { s.Stop() })
+
+	hostPort, err := s.getAddressListeningOn()
+	assert.Nil(t, err, "error in getting address listening on")
+	return hostPort
+}
+
+// expectProtocolError checks that the server replied with the protocol error
+// and then closed the connection.
+func expectProtocolError(t *testing.T, reader *bufio.Reader, expected string) {
+	t.Helper()
+	reply, err := reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "-ERR Protocol error: "+expected+"\r\n", reply)
+	_, err = reader.ReadString('\n')
+	assert.Equal(t, io.EOF, err, "the connection should be closed")
+}
+
+func TestRepliesAreSentWhileACommandIsIncomplete(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// The reply to PING is not held back waiting for the rest of the pipeline
+	sendAndExpect(t, conn, reader, "PING\r\n*1\r\n$4\r\nPI", "+PONG\r\n")
+	sendAndExpect(t, conn, reader, "NG\r\n", "+PONG\r\n")
+}
+
+func TestOutputBufferHardLimit(t *testing.T) {
+	config := internal.DefaultConfig()
+	config.ClientOutputBufferHardLimit = 4096
+	hostPort := startTestServerWithConfig(t, config)
+	conn, reader := dialTestServer(t, hostPort)
+	value := strings.Repeat("a", 1024)
+	sendAndExpect(t, conn, reader, concatCommands("*3", "$3", "SET", "$1", "k", "$1024", value), "+OK\r\n")
+
+	// Replies that fit in the limit are sent
+	getCmd := concatCommands("*2", "$3", "GET", "$1", "k")
+	sendAndExpect(t, conn, reader, strings.Repeat(getCmd, 2), strings.Repeat(concatCommands("$1024", value), 2))
+
+	// A pipeline whose replies go over the limit gets the client disconnected
+	conn.Write([]byte(strings.Repeat(getCmd, 10)))
+	received := expectDisconnected(t, reader)
+	assert.Less(t, received, int64(10*len(concatCommands("$1024", value))))
+	verifyServerIsAlive(t, hostPort)
+}
+
+func TestOutputBufferSoftLimit(t *testing.T) {
+	config := internal.DefaultConfig()
+	config.ClientOutputBufferSoftLimit = 1024 * 1024
+	config.ClientOutputBufferSoftPeriod = 100 * time.Millisecond
+	hostPort := startTestServerWithConfig(t, config)
+	conn, reader := dialTestServer(t, hostPort)
+	value := strings.Repeat("a", 1024*1024)
+	sendAndExpect(t, conn, reader,
+		concatCommands("*3", "$3", "SET", "$1", "k", "$"+strconv.Itoa(len(value)), value), "+OK\r\n")
+
+	// Keep asking for the value without reading the replies, until the
+	// server gives up on the client
+	getCmd := concatCommands("*2", "$3", "GET", "$1", "k")
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		conn.SetWriteDeadline(deadline)
+		if _, err := conn.Write([]byte(getCmd)); err != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	expectDisconnected(t, reader)
+	verifyServerIsAlive(t, hostPort)
+}
+
+// expectDisconnected reads until the server closes the connection, and
+// returns how many bytes were read.
+func expectDisconnected(t *testing.T, reader *bufio.Reader) int64 {
+	t.Helper()
+	received, err := io.Copy(io.Discard, reader)
+	var netErr net.Error
+	assert.False(t, errors.As(err, &netErr) && netErr.Timeout(), "the connection should be closed")
+	return received
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushAndPop(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(3), rdb.RPush(ctx, "l", "a", "b", "c").Val())
+	assert.Equal(t, int64(5), rdb.LPush(ctx, "l", "y", "z").Val())
+	assert.Equal(t, []string{"z", "y", "a", "b", "c"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, int64(5), rdb.LLen(ctx, "l").Val())
+
+	assert.Equal(t, "z", rdb.LPop(ctx, "l").Val())
+	assert.Equal(t, []string{"c", "b"}, rdb.RPopCount(ctx, "l", 2).Val())
+	assert.Equal(t, []string{}, rdb.LPopCount(ctx, "l", 0).Val())
+	assert.Equal(t, []string{"y", "a"}, rdb.LPopCount(ctx, "l", 10).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "l").Val(), "the key is deleted with its last element")
+
+	assert.Equal(t, redis.Nil, rdb.LPop(ctx, "l").Err())
+	assert.Equal(t, redis.Nil, rdb.RPopCount(ctx, "l", 2).Err())
+	assert.Equal(t, int64(0), rdb.LLen(ctx, "l").Val())
+	assert.EqualError(t, rdb.Do(ctx, "lpop", "l", "-1").Err(), "ERR value is out of range, must be positive")
+
+	// The X variants only push to lists that exist
+	assert.Equal(t, int64(0), rdb.LPushX(ctx, "l", "a").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "l").Val())
+	assert.Nil(t, rdb.RPush(ctx, "l", "a").Err())
+	assert.Equal(t, int64(3), rdb.RPushX(ctx, "l", "b", "c").Val())
+	assert.Equal(t, int64(4), rdb.LPushX(ctx, "l", "z").Val())
+	assert.Equal(t, []string{"z", "a", "b", "c"}, rdb.LRange(ctx, "l", 0, -1).Val())
+}
+
+func TestPopNullReplies(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// Pops with a count reply with a null array to RESP2 clients
+	sendAndExpect(t, conn, reader, "LPOP l\r\n", "$-1\r\n")
+	sendAndExpect(t, conn, reader, "LPOP l 1\r\n", "*-1\r\n")
+	sendAndExpect(t, conn, reader, "LMPOP 1 l LEFT\r\n", "*-1\r\n")
+
+	// and with the single null of RESP3 otherwise
+	decoder := resp.NewDecoder(reader)
+	conn.Write([]byte("HELLO 3\r\n"))
+	_, err := decoder.Decode()
+	assert.Nil(t, err)
+	conn.Write([]byte("LPOP l 1\r\nLMPOP 1 l LEFT\r\n"))
+	for range 2 {
+		null, err := decoder.Decode()
+		assert.Nil(t, err)
+		assert.Equal(t, &rtypes.Null{}, null)
+	}
+}
+
+func TestLRangeAndLIndex(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.RPush(ctx, "l", "0", "1", "2", "3", "4", "5", "6", "7", "8", "9").Err())
+
+	tests := []struct {
+		start, stop int64
+		expected    []string
+	}{
+		{0, 2, []string{"0", "1", "2"}},
+		{-3, -1, []string{"7", "8", "9"}},
+		{7, 100, []string{"7", "8", "9"}},
+		{-100, 1, []string{"0", "1"}},
+		{5, 3, []string{}},
+		{10, 12, []string{}},
+		{-1, -3, []string{}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, rdb.LRange(ctx, "l", tt.start, tt.stop).Val(), "LRANGE %d %d", tt.start, tt.stop)
+	}
+	assert.Equal(t, []string{}, rdb.LRange(ctx, "missing", 0, -1).Val())
+
+	assert.Equal(t, "0", rdb.LIndex(ctx, "l", 0).Val())
+	assert.Equal(t, "9", rdb.LIndex(ctx, "l", -1).Val())
+	assert.Equal(t, "4", rdb.LIndex(ctx, "l", -6).Val())
+	assert.Equal(t, redis.Nil, rdb.LIndex(ctx, "l", 10).Err())
+	assert.Equal(t, redis.Nil, rdb.LIndex(ctx, "l", -11).Err())
+	assert.Equal(t, redis.Nil, rdb.LIndex(ctx, "missing", 0).Err())
+	assert.EqualError(t, rdb.Do(ctx, "lindex", "l", "x").Err(), "ERR value is not an integer or out of range")
+}
+
+func TestLSetAndLInsert(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.EqualError(t, rdb.LSet(ctx, "l", 0, "x").Err(), "ERR no such key")
+	assert.Nil(t, rdb.RPush(ctx, "l", "a", "b", "c").Err())
+	assert.Equal(t, "OK", rdb.LSet(ctx, "l", 0, "A").Val())
+	assert.Equal(t, "OK", rdb.LSet(ctx, "l", -1, "C").Val())
+	assert.EqualError(t, rdb.LSet(ctx, "l", 3, "x").Err(), "ERR index out of range")
+	assert.Equal(t, []string{"A", "b", "C"}, rdb.LRange(ctx, "l", 0, -1).Val())
+
+	assert.Equal(t, int64(4), rdb.LInsertBefore(ctx, "l", "b", "before").Val())
+	assert.Equal(t, int64(5), rdb.LInsertAfter(ctx, "l", "C", "after").Val())
+	assert.Equal(t, []string{"A", "before", "b", "C", "after"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, int64(-1), rdb.LInsertBefore(ctx, "l", "nosuchpivot", "x").Val())
+	assert.Equal(t, int64(0), rdb.LInsertBefore(ctx, "missing", "a", "x").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "missing").Val())
+	assert.EqualError(t, rdb.Do(ctx, "linsert", "l", "middle", "b", "x").Err(), "ERR syntax error")
+}
+
+func TestLRemAndLTrim(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.RPush(ctx, "l", "a", "b", "a", "c", "a", "b", "a").Err())
+	assert.Equal(t, int64(1), rdb.LRem(ctx, "l", 1, "a").Val())
+	assert.Equal(t, []string{"b", "a", "c", "a", "b", "a"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, int64(2), rdb.LRem(ctx, "l", -2, "a").Val())
+	assert.Equal(t, []string{"b", "a", "c", "b"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, int64(2), rdb.LRem(ctx, "l", 0, "b").Val())
+	assert.Equal(t, []string{"a", "c"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, int64(0), rdb.LRem(ctx, "missing", 0, "a").Val())
+	assert.Equal(t, int64(2), rdb.LRem(ctx, "l", 0, "a").Val()+rdb.LRem(ctx, "l", 0, "c").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "l").Val())
+
+	assert.Nil(t, rdb.RPush(ctx, "l", "0", "1", "2", "3", "4", "5", "6", "7", "8", "9").Err())
+	assert.Equal(t, "OK", rdb.LTrim(ctx, "l", 2, -3).Val())
+	assert.Equal(t, []string{"2", "3", "4", "5", "6", "7"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, "OK", rdb.LTrim(ctx, "l", -2, 100).Val())
+	assert.Equal(t, []string{"6", "7"}, rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, "OK", rdb.LTrim(ctx, "l", 5, 1).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "l").Val())
+	assert.Equal(t, "OK", rdb.LTrim(ctx, "missing", 0, 1).Val())
+}
+
+func TestLPos(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.RPush(ctx, "l", "a", "b", "c", "d", "1", "2", "3", "4", "3", "3", "3").Err())
+
+	assert.Equal(t, int64(6), rdb.LPos(ctx, "l", "3", redis.LPosArgs{}).Val())
+	assert.Equal(t, int64(8), rdb.LPos(ctx, "l", "3", redis.LPosArgs{Rank: 2}).Val())
+	assert.Equal(t, int64(10), rdb.LPos(ctx, "l", "3", redis.LPosArgs{Rank: -1}).Val())
+	assert.Equal(t, []int64{8, 9, 10}, rdb.LPosCount(ctx, "l", "3", 0, redis.LPosArgs{Rank: 2}).Val())
+	assert.Equal(t, []int64{10, 9}, rdb.LPosCount(ctx, "l", "3", 2, redis.LPosArgs{Rank: -1}).Val())
+	assert.Equal(t, []int64{6}, rdb.LPosCount(ctx, "l", "3", 0, redis.LPosArgs{MaxLen: 8}).Val())
+	assert.Equal(t, []int64{10, 9}, rdb.LPosCount(ctx, "l", "3", 0, redis.LPosArgs{Rank: -1, MaxLen: 2}).Val())
+	assert.Equal(t, redis.Nil, rdb.LPos(ctx, "l", "c", redis.LPosArgs{MaxLen: 2}).Err())
+	assert.Equal(t, redis.Nil, rdb.LPos(ctx, "l", "x", redis.LPosArgs{}).Err())
+	assert.Equal(t, []int64{}, rdb.LPosCount(ctx, "l", "x", 1, redis.LPosArgs{}).Val())
+	assert.Equal(t, redis.Nil, rdb.LPos(ctx, "missing", "x", redis.LPosArgs{}).Err())
+	assert.Equal(t, []int64{}, rdb.LPosCount(ctx, "missing", "x", 1, redis.LPosArgs{}).Val())
+
+	assert.EqualError(t, rdb.Do(ctx, "lpos", "l", "3", "rank", "0").Err(), "ERR RANK can't be zero: "+
+		"use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+	assert.EqualError(t, rdb.Do(ctx, "lpos", "l", "3", "count", "-1").Err(), "ERR COUNT can't be negative")
+	assert.EqualError(t, rdb.Do(ctx, "lpos", "l", "3", "maxlen", "-1").Err(), "ERR MAXLEN can't be negative")
+	assert.EqualError(t, rdb.Do(ctx, "lpos", "l", "3", "rank").Err(), "ERR syntax error")
+}
+
+func TestLMoveAndRPopLPush(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.RPush(ctx, "src", "1", "2", "3").Err())
+
+	assert.Equal(t, "3", rdb.LMove(ctx, "src", "dst", "RIGHT", "LEFT").Val())
+	assert.Equal(t, "1", rdb.LMove(ctx, "src", "dst", "left", "right").Val())
+	assert.Equal(t, []string{"3", "1"}, rdb.LRange(ctx, "dst", 0, -1).Val())
+
+	// Moving within the same list rotates it
+	assert.Equal(t, "3", rdb.LMove(ctx, "dst", "dst", "LEFT", "RIGHT").Val())
+	assert.Equal(t, []string{"1", "3"}, rdb.LRange(ctx, "dst", 0, -1).Val())
+	assert.Equal(t, "2", rdb.RPopLPush(ctx, "src", "src").Val())
+	assert.Equal(t, []string{"2"}, rdb.LRange(ctx, "src", 0, -1).Val())
+
+	assert.Equal(t, "2", rdb.RPopLPush(ctx, "src", "dst").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "src").Val())
+	assert.Equal(t, []string{"2", "1", "3"}, rdb.LRange(ctx, "dst", 0, -1).Val())
+	assert.Equal(t, redis.Nil, rdb.LMove(ctx, "src", "dst", "LEFT", "LEFT").Err())
+
+	// Nothing is popped when the destination is not a list
+	assert.Nil(t, rdb.Set(ctx, "str", "x", 0).Err())
+	assert.EqualError(t, rdb.LMove(ctx, "dst", "str", "LEFT", "LEFT").Err(),
+		"WRONGTYPE Operation against a key holding the wrong kind of value")
+	assert.Equal(t, int64(3), rdb.LLen(ctx, "dst").Val())
+	assert.EqualError(t, rdb.LMove(ctx, "dst", "src", "UP", "LEFT").Err(), "ERR syntax error")
+}
+
+func TestLMPop(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.RPush(ctx, "b", "1", "2", "3").Err())
+
+	key, elements, err := rdb.LMPop(ctx, "left", 1, "a", "b").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "b", key)
+	assert.Equal(t, []string{"1"}, elements)
+	key, elements, err = rdb.LMPop(ctx, "right", 5, "a", "b").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "b", key)
+	assert.Equal(t, []string{"3", "2"}, elements)
+	assert.Equal(t, redis.Nil, rdb.LMPop(ctx, "left", 1, "a", "b").Err())
+
+	assert.EqualError(t, rdb.Do(ctx, "lmpop", "0", "a", "left").Err(), "ERR numkeys should be greater than 0")
+	assert.EqualError(t, rdb.Do(ctx, "lmpop", "2", "a", "left").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "lmpop", "1", "a", "up").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "lmpop", "1", "a", "left", "count", "0").Err(), "ERR count should be greater than 0")
+	assert.EqualError(t, rdb.Do(ctx, "lmpop", "1", "a", "left", "count", "1", "count", "1").Err(), "ERR syntax error")
+
+	keys, err := rdb.CommandGetKeys(ctx, "lmpop", "2", "a", "b", "left", "count", "1").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestListsAndWrongType(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	const wrongType = "WRONGTYPE Operation against a key holding the wrong kind of value"
+	assert.Nil(t, rdb.Set(ctx, "str", "x", 0).Err())
+	assert.Nil(t, rdb.RPush(ctx, "list", "a").Err())
+
+	assert.EqualError(t, rdb.LPush(ctx, "str", "a").Err(), wrongType)
+	assert.EqualError(t, rdb.LRange(ctx, "str", 0, -1).Err(), wrongType)
+	assert.EqualError(t, rdb.Get(ctx, "list").Err(), wrongType)
+	assert.EqualError(t, rdb.Append(ctx, "list", "x").Err(), wrongType)
+	assert.EqualError(t, rdb.Incr(ctx, "list").Err(), wrongType)
+	assert.EqualError(t, rdb.SetArgs(ctx, "list", "x", redis.SetArgs{Get: true}).Err(), wrongType)
+	assert.Equal(t, []interface{}{nil, "x"}, rdb.MGet(ctx, "list", "str").Val())
+
+	assert.Equal(t, "list", rdb.Type(ctx, "list").Val())
+	keys, _, err := rdb.ScanType(ctx, 0, "*", 100, "list").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"list"}, keys)
+
+	// SET replaces a value of any type
+	assert.Equal(t, "OK", rdb.Set(ctx, "list", "x", 0).Val())
+	assert.Equal(t, "string", rdb.Type(ctx, "list").Val())
+}
+
+func TestLargeLists(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	// Grow the list at both ends, over many nodes
+	const n = 5000
+	expected := make([]string, 0, n)
+	pipe := rdb.Pipeline()
+	for i := range n {
+		value := strconv.Itoa(i)
+		if i%2 == 0 {
+			pipe.RPush(ctx, "l", value)
+			expected = append(expected, value)
+		} else {
+			pipe.LPush(ctx, "l", value)
+			expected = append([]string{value}, expected...)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "quicklist", rdb.ObjectEncoding(ctx, "l").Val())
+	assert.Equal(t, expected, rdb.LRange(ctx, "l", 0, -1).Val())
+	for _, i := range []int64{0, 1, 777, n / 2, n - 2, n - 1} {
+		assert.Equal(t, expected[i], rdb.LIndex(ctx, "l", i).Val())
+	}
+	assert.Equal(t, expected[1000:1010], rdb.LRange(ctx, "l", 1000, 1009).Val())
+
+	// Modify the middle of the list
+	assert.Equal(t, int64(n+1), rdb.LInsertAfter(ctx, "l", expected[2500], "new").Val())
+	assert.Equal(t, "new", rdb.LIndex(ctx, "l", 2501).Val())
+	assert.Equal(t, "OK", rdb.LSet(ctx, "l", 2501, strings.Repeat("x", 1000)).Val())
+	assert.Equal(t, int64(1), rdb.LRem(ctx, "l", 0, strings.Repeat("x", 1000)).Val())
+	assert.Equal(t, expected, rdb.LRange(ctx, "l", 0, -1).Val())
+
+	// A copy is not affected by changes to the original
+	assert.Equal(t, int64(1), rdb.Copy(ctx, "l", "copy", 0, false).Val())
+	assert.Equal(t, "OK", rdb.LTrim(ctx, "l", 100, 109).Val())
+	assert.Equal(t, expected[100:110], rdb.LRange(ctx, "l", 0, -1).Val())
+	assert.Equal(t, "listpack", rdb.ObjectEncoding(ctx, "l").Val())
+	assert.Equal(t, int64(n), rdb.LLen(ctx, "copy").Val())
+	assert.Equal(t, expected, rdb.LRange(ctx, "copy", 0, -1).Val())
+	assert.Equal(t, int64(1), rdb.Unlink(ctx, "copy").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "copy").Val())
+
+	// A single large element is enough to outgrow a listpack
+	assert.Nil(t, rdb.RPush(ctx, "big", strings.Repeat("x", 10000)).Err())
+	assert.Equal(t, "quicklist", rdb.ObjectEncoding(ctx, "big").Val())
+}
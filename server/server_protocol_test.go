@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResp2IsTheDefaultProtocol(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// Nulls are sent as null bulk strings
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$3", "GET", "$7", "missing"), "$-1\r\n")
+
+	// Maps are flattened into arrays
+	sendAndExpect(t, conn, reader,
+		concatCommands("*3", "$7", "COMMAND", "$4", "DOCS", "$3", "get"),
+		concatCommands("*2", "$3", "get", "*6",
+			"$7", "summary", "$34", "Returns the string value of a key.",
+			"$5", "since", "$5", "1.0.0",
+			"$5", "group", "$6", "string"))
+}
+
+func TestHelloSwitchesProtocol(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// HELLO without a version replies in the current protocol and keeps it
+	conn.Write([]byte(concatCommands("*1", "$5", "HELLO")))
+	header, err := reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "*14\r\n", header)
+	for range 14 {
+		readElementForTest(t, reader)
+	}
+
+	conn.Write([]byte(concatCommands("*2", "$5", "HELLO", "$1", "3")))
+	header, err = reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "%7\r\n", header)
+	for range 14 {
+		readElementForTest(t, reader)
+	}
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$3", "GET", "$7", "missing"), "_\r\n")
+
+	conn.Write([]byte(concatCommands("*2", "$5", "HELLO", "$1", "2")))
+	header, err = reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "*14\r\n", header)
+	for range 14 {
+		readElementForTest(t, reader)
+	}
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$3", "GET", "$7", "missing"), "$-1\r\n")
+}
+
+func TestPipelinedHelloSwitchesProtocolInOrder(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// Other clients keep the goroutine running HELLO busy
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rdb := getRedisClient(t, hostPort)
+	go func() {
+		for ctx.Err() == nil {
+			rdb.Do(ctx, "command")
+		}
+	}()
+
+	expectHello := func(header string) {
+		t.Helper()
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err)
+		assert.Equal(t, header, line)
+		for range 14 {
+			readElementForTest(t, reader)
+		}
+	}
+	for range 50 {
+		conn.Write([]byte("GET missing\r\nHELLO 3\r\nGET missing\r\nHELLO 2\r\nGET missing\r\n"))
+		sendAndExpect(t, conn, reader, "", "$-1\r\n")
+		expectHello("%7\r\n")
+		sendAndExpect(t, conn, reader, "", "_\r\n")
+		expectHello("*14\r\n")
+		sendAndExpect(t, conn, reader, "", "$-1\r\n")
+	}
+}
+
+func TestHelloErrors(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$5", "HELLO", "$1", "4"),
+		"-NOPROTO unsupported protocol version\r\n")
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$5", "HELLO", "$3", "two"),
+		"-ERR Protocol version is not an integer or out of range\r\n")
+	sendAndExpect(t, conn, reader, concatCommands("*3", "$5", "HELLO", "$1", "3", "$3", "FOO"),
+		"-ERR Syntax error in HELLO option 'FOO'\r\n")
+	sendAndExpect(t, conn, reader, concatCommands("*4", "$5", "HELLO", "$1", "3", "$7", "SETNAME", "$5", "a b c"),
+		"-ERR Client names cannot contain spaces, newlines or special characters.\r\n")
+	sendAndExpect(t, conn, reader, concatCommands("*5", "$5", "HELLO", "$1", "3", "$4", "AUTH", "$5", "alice", "$4", "pass"),
+		"-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+
+	// A failed HELLO does not switch the protocol
+	sendAndExpect(t, conn, reader, concatCommands("*2", "$3", "GET", "$7", "missing"), "$-1\r\n")
+}
+
+func TestSetsAreDowngradedForResp2(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+	commandInfo := concatCommands("*3", "$7", "COMMAND", "$4", "INFO", "$3", "get")
+	flags := concatCommands("+readonly", "+fast")
+
+	sendAndExpect(t, conn, reader, commandInfo, concatCommands("*1", "*10", "$3", "get", ":2", "*2")+flags)
+	for range 7 {
+		readElementForTest(t, reader)
+	}
+
+	conn.Write([]byte(concatCommands("*2", "$5", "HELLO", "$1", "3")))
+	readElementForTest(t, reader)
+	sendAndExpect(t, conn, reader, commandInfo, concatCommands("*1", "*10", "$3", "get", ":2", "~2")+flags)
+}
+
+func TestResp2Client(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     hostPort,
+		Protocol: 2,
+		MaintNotificationsConfig: &maintnotifications.Config{
+			Mode: maintnotifications.ModeDisabled,
+		},
+		DisableIdentity: true,
+	})
+	t.Cleanup(func() { rdb.Close() })
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.Set(ctx, "k", "v", 0).Err())
+	_, err := rdb.Get(ctx, "missing").Result()
+	assert.Equal(t, redis.Nil, err)
+	docs, err := rdb.Do(ctx, "command", "docs", "get").Slice()
+	assert.Nil(t, err)
+	assert.Equal(t, "get", docs[0])
+}
+
+func dialTestServer(t *testing.T, hostPort string) (net.Conn, *bufio.Reader) {
+	conn, err := net.DialTimeout("tcp", hostPort, 1*time.Second)
+	assert.Nilf(t, err, "failed to connect to server: %f", err)
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn, bufio.NewReader(conn)
+}
+
+func sendAndExpect(t *testing.T, conn net.Conn, reader *bufio.Reader, command string, expected string) {
+	t.Helper()
+	_, err := conn.Write([]byte(command))
+	assert.Nilf(t, err, "failed to write: %f", err)
+	actual := make([]byte, len(expected))
+	_, err = io.ReadFull(reader, actual)
+	assert.Nilf(t, err, "failed to read response: %f", err)
+	assert.Equal(t, expected, string(actual))
+}
+
+// readElementForTest skips a single element of a reply made of simple
+// types and flat aggregates.
+func readElementForTest(t *testing.T, reader *bufio.Reader) {
+	t.Helper()
+	line, err := reader.ReadString('\n')
+	assert.Nil(t, err)
+	switch line[0] {
+	case '$':
+		_, err = reader.ReadString('\n')
+		assert.Nil(t, err)
+	case '*', '%', '~':
+		var n int
+		for _, c := range line[1 : len(line)-2] {
+			n = n*10 + int(c-'0')
+		}
+		if line[0] == '%' {
+			n *= 2
+		}
+		for range n {
+			readElementForTest(t, reader)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetWithExpiry(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	err := rdb.Set(ctx, "px", "v", 50*time.Millisecond).Err()
+	assert.Nil(t, err, "error in setting key with PX")
+	err = rdb.Do(ctx, "set", "pxat", "v", "pxat", time.Now().Add(50*time.Millisecond).UnixMilli()).Err()
+	assert.Nil(t, err, "error in setting key with PXAT")
+	err = rdb.Set(ctx, "ex", "v", 10*time.Second).Err()
+	assert.Nil(t, err, "error in setting key with EX")
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = rdb.Get(ctx, "px").Result()
+	assert.Equal(t, redis.Nil, err, "key set with PX should have expired")
+	_, err = rdb.Get(ctx, "pxat").Result()
+	assert.Equal(t, redis.Nil, err, "key set with PXAT should have expired")
+	val, err := rdb.Get(ctx, "ex").Result()
+	assert.Nil(t, err, "key set with EX should not have expired")
+	assert.Equal(t, "v", val)
+}
+
+func TestSetKeepTTL(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.Set(ctx, "k", "v1", 50*time.Millisecond).Err())
+	assert.Nil(t, rdb.Set(ctx, "k", "v2", redis.KeepTTL).Err())
+	val, err := rdb.Get(ctx, "k").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", val)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = rdb.Get(ctx, "k").Result()
+	assert.Equal(t, redis.Nil, err, "KEEPTTL should have retained the TTL")
+
+	// A plain SET clears the TTL
+	assert.Nil(t, rdb.Set(ctx, "k2", "v1", 50*time.Millisecond).Err())
+	assert.Nil(t, rdb.Set(ctx, "k2", "v2", 0).Err())
+	time.Sleep(100 * time.Millisecond)
+	val, err = rdb.Get(ctx, "k2").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", val)
+}
+
+func TestSetNXAndXX(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	ok, err := rdb.SetXX(ctx, "k", "v1", 0).Result()
+	assert.Nil(t, err)
+	assert.False(t, ok, "XX should not set a missing key")
+
+	val, err := rdb.SetArgs(ctx, "k", "v1", redis.SetArgs{Mode: "NX"}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", val, "NX should set a missing key")
+
+	_, err = rdb.SetArgs(ctx, "k", "v2", redis.SetArgs{Mode: "NX"}).Result()
+	assert.Equal(t, redis.Nil, err, "NX should not overwrite an existing key")
+
+	ok, err = rdb.SetXX(ctx, "k", "v3", 0).Result()
+	assert.Nil(t, err)
+	assert.True(t, ok, "XX should overwrite an existing key")
+
+	val, err = rdb.Get(ctx, "k").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v3", val)
+}
+
+func TestSetGet(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	_, err := rdb.SetArgs(ctx, "k", "v1", redis.SetArgs{Get: true}).Result()
+	assert.Equal(t, redis.Nil, err, "GET should return nil for a missing key")
+
+	val, err := rdb.SetArgs(ctx, "k", "v2", redis.SetArgs{Get: true}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", val)
+
+	// The old value is returned even when NX prevents the write
+	val, err = rdb.SetArgs(ctx, "k", "v3", redis.SetArgs{Get: true, Mode: "NX"}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", val)
+	val, err = rdb.Get(ctx, "k").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", val)
+}
+
+func TestSetInvalidOptions(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		args     []any
+		expected string
+	}{
+		{"nx and xx", []any{"set", "k", "v", "nx", "xx"}, "ERR syntax error"},
+		{"ex and px", []any{"set", "k", "v", "ex", "10", "px", "100"}, "ERR syntax error"},
+		{"ex and keepttl", []any{"set", "k", "v", "ex", "10", "keepttl"}, "ERR syntax error"},
+		{"ex without value", []any{"set", "k", "v", "ex"}, "ERR syntax error"},
+		{"unknown option", []any{"set", "k", "v", "foo"}, "ERR syntax error"},
+		{"non-integer ex", []any{"set", "k", "v", "ex", "ten"}, "ERR value is not an integer or out of range"},
+		{"zero ex", []any{"set", "k", "v", "ex", "0"}, "ERR invalid expire time in 'set' command"},
+		{"negative px", []any{"set", "k", "v", "px", "-5"}, "ERR invalid expire time in 'set' command"},
+		{"overflowing ex", []any{"set", "k", "v", "ex", "9223372036854775807"}, "ERR invalid expire time in 'set' command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rdb.Do(ctx, tt.args...).Err()
+			assert.NotNil(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+
+	_, err := rdb.Get(ctx, "k").Result()
+	assert.Equal(t, redis.Nil, err, "invalid SET should not have written the key")
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ram-the-coder/redisgo/internal"
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCommands(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(3), rdb.SAdd(ctx, "s", "a", "b", "c").Val())
+	assert.Equal(t, int64(1), rdb.SAdd(ctx, "s", "a", "d", "d").Val(), "only new members are counted")
+	assert.Equal(t, int64(4), rdb.SCard(ctx, "s").Val())
+	assert.Equal(t, int64(0), rdb.SCard(ctx, "nokey").Val())
+	assert.True(t, rdb.SIsMember(ctx, "s", "a").Val())
+	assert.False(t, rdb.SIsMember(ctx, "s", "x").Val())
+	assert.False(t, rdb.SIsMember(ctx, "nokey", "a").Val())
+	assert.Equal(t, []bool{true, false, true}, rdb.SMIsMember(ctx, "s", "a", "x", "d").Val())
+	assert.Equal(t, []bool{false}, rdb.SMIsMember(ctx, "nokey", "a").Val())
+
+	// Small sets keep their members in insertion order
+	assert.Equal(t, []string{"a", "b", "c", "d"}, rdb.SMembers(ctx, "s").Val())
+	assert.Equal(t, []string{}, rdb.SMembers(ctx, "nokey").Val())
+
+	assert.True(t, rdb.SMove(ctx, "s", "t", "a").Val())
+	assert.False(t, rdb.SMove(ctx, "s", "t", "a").Val())
+	assert.False(t, rdb.SMove(ctx, "nokey", "t", "a").Val())
+	assert.True(t, rdb.SMove(ctx, "s", "s", "b").Val(), "moving within the same set reports membership")
+	assert.Equal(t, []string{"a"}, rdb.SMembers(ctx, "t").Val())
+
+	assert.Equal(t, int64(2), rdb.SRem(ctx, "s", "b", "c", "missing").Val())
+	assert.Equal(t, int64(0), rdb.SRem(ctx, "nokey", "a").Val())
+	assert.True(t, rdb.SMove(ctx, "s", "t", "d").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "s").Val(), "the key is deleted with its last member")
+	assert.Equal(t, int64(1), rdb.SRem(ctx, "t", "a").Val())
+	assert.Equal(t, int64(1), rdb.SRem(ctx, "t", "d").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "t").Val())
+}
+
+func TestSMembersReplies(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	sendAndExpect(t, conn, reader, "SADD s a b\r\n", ":2\r\n")
+	// RESP2 clients get the members in an array
+	sendAndExpect(t, conn, reader, "SMEMBERS s\r\n", "*2\r\n$1\r\na\r\n$1\r\nb\r\n")
+	sendAndExpect(t, conn, reader, "SMEMBERS nokey\r\n", "*0\r\n")
+
+	// and RESP3 clients a set
+	decoder := resp.NewDecoder(reader)
+	conn.Write([]byte("HELLO 3\r\n"))
+	_, err := decoder.Decode()
+	assert.Nil(t, err)
+	for _, command := range []string{"SMEMBERS s\r\n", "SUNION s nokey\r\n"} {
+		conn.Write([]byte(command))
+		reply, err := decoder.Decode()
+		assert.Nil(t, err)
+		assert.Equal(t, &rtypes.Set{Elements: []rtypes.RespDataType{
+			rtypes.NewBulkString("a"), rtypes.NewBulkString("b"),
+		}}, reply, command)
+	}
+}
+
+func TestSPopAndSRandMember(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	members := []string{"a", "b", "c", "d", "e"}
+	assert.Nil(t, rdb.SAdd(ctx, "s", members).Err())
+
+	assert.Contains(t, members, rdb.SRandMember(ctx, "s").Val())
+	assert.Equal(t, redis.Nil, rdb.SRandMember(ctx, "nokey").Err())
+	for _, count := range []int64{1, 2, 4, 5, 10} {
+		picked := rdb.SRandMemberN(ctx, "s", count).Val()
+		assert.Len(t, picked, int(min(count, 5)))
+		assert.ElementsMatch(t, picked, uniqueStrings(picked), "positive counts pick distinct members")
+		assert.Subset(t, members, picked)
+	}
+	picked := rdb.SRandMemberN(ctx, "s", -20).Val()
+	assert.Len(t, picked, 20, "negative counts may repeat members")
+	assert.Subset(t, members, picked)
+	assert.Empty(t, rdb.SRandMemberN(ctx, "s", 0).Val())
+	assert.Empty(t, rdb.SRandMemberN(ctx, "nokey", 3).Val())
+	assert.Equal(t, int64(5), rdb.SCard(ctx, "s").Val(), "SRANDMEMBER does not remove members")
+
+	popped := rdb.SPop(ctx, "s").Val()
+	assert.Contains(t, members, popped)
+	assert.False(t, rdb.SIsMember(ctx, "s", popped).Val())
+	popped2 := rdb.SPopN(ctx, "s", 2).Val()
+	assert.Len(t, popped2, 2)
+	assert.Equal(t, int64(2), rdb.SCard(ctx, "s").Val())
+	assert.Empty(t, rdb.SPopN(ctx, "s", 0).Val())
+	remaining := rdb.SPopN(ctx, "s", 10).Val()
+	assert.ElementsMatch(t, members, append(append(remaining, popped2...), popped))
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "s").Val(), "the key is deleted with its last member")
+	assert.Equal(t, redis.Nil, rdb.SPop(ctx, "s").Err())
+	assert.Empty(t, rdb.SPopN(ctx, "s", 3).Val())
+
+	assert.EqualError(t, rdb.Do(ctx, "spop", "s", "-1").Err(), "ERR value is out of range, must be positive")
+	assert.EqualError(t, rdb.Do(ctx, "srandmember", "s", "x").Err(), "ERR value is not an integer or out of range")
+	assert.EqualError(t, rdb.Do(ctx, "srandmember", "s", "1", "2").Err(), "ERR syntax error")
+}
+
+func TestSetOperations(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.SAdd(ctx, "s1", "a", "b", "c", "d").Err())
+	assert.Nil(t, rdb.SAdd(ctx, "s2", "c", "d", "e").Err())
+	assert.Nil(t, rdb.SAdd(ctx, "s3", "d", "f").Err())
+
+	assert.ElementsMatch(t, []string{"d"}, rdb.SInter(ctx, "s1", "s2", "s3").Val())
+	assert.ElementsMatch(t, []string{"c", "d"}, rdb.SInter(ctx, "s1", "s2").Val())
+	assert.Empty(t, rdb.SInter(ctx, "s1", "nokey").Val())
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e", "f"}, rdb.SUnion(ctx, "s1", "s2", "s3", "nokey").Val())
+	assert.ElementsMatch(t, []string{"a", "b"}, rdb.SDiff(ctx, "s1", "s2", "s3").Val())
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, rdb.SDiff(ctx, "s1", "nokey").Val())
+	assert.Empty(t, rdb.SDiff(ctx, "nokey", "s1").Val())
+
+	assert.Equal(t, int64(2), rdb.SInterStore(ctx, "dst", "s1", "s2").Val())
+	assert.ElementsMatch(t, []string{"c", "d"}, rdb.SMembers(ctx, "dst").Val())
+	assert.Equal(t, int64(6), rdb.SUnionStore(ctx, "dst", "s1", "s2", "s3").Val())
+	assert.Equal(t, int64(6), rdb.SCard(ctx, "dst").Val())
+	assert.Equal(t, int64(2), rdb.SDiffStore(ctx, "dst", "s1", "s2").Val())
+	assert.ElementsMatch(t, []string{"a", "b"}, rdb.SMembers(ctx, "dst").Val())
+	assert.Equal(t, int64(1), rdb.SInterStore(ctx, "s1", "s1", "s3").Val(), "the destination may be a source")
+	assert.Equal(t, []string{"d"}, rdb.SMembers(ctx, "s1").Val())
+
+	// The destination is overwritten whatever it held, along with its TTL,
+	// and deleted when the result is empty
+	assert.Nil(t, rdb.Set(ctx, "str", "v", time.Hour).Err())
+	assert.Equal(t, int64(2), rdb.SUnionStore(ctx, "str", "s3").Val())
+	assert.Equal(t, "set", rdb.Type(ctx, "str").Val())
+	assert.Equal(t, time.Duration(-1), rdb.TTL(ctx, "str").Val())
+	assert.Equal(t, int64(0), rdb.SInterStore(ctx, "str", "s3", "nokey").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "str").Val())
+}
+
+func TestSInterCard(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.SAdd(ctx, "s1", "a", "b", "c", "d").Err())
+	assert.Nil(t, rdb.SAdd(ctx, "s2", "b", "c", "d", "e").Err())
+
+	assert.Equal(t, int64(3), rdb.SInterCard(ctx, 0, "s1", "s2").Val())
+	assert.Equal(t, int64(2), rdb.SInterCard(ctx, 2, "s1", "s2").Val())
+	assert.Equal(t, int64(3), rdb.SInterCard(ctx, 10, "s1", "s2").Val())
+	assert.Equal(t, int64(4), rdb.SInterCard(ctx, 0, "s1").Val())
+	assert.Equal(t, int64(0), rdb.SInterCard(ctx, 0, "s1", "nokey").Val())
+
+	assert.EqualError(t, rdb.Do(ctx, "sintercard", "0", "s1").Err(), "ERR numkeys should be greater than 0")
+	assert.EqualError(t, rdb.Do(ctx, "sintercard", "3", "s1", "s2").Err(),
+		"ERR Number of keys can't be greater than number of args")
+	assert.EqualError(t, rdb.Do(ctx, "sintercard", "1", "s1", "limit", "-1").Err(), "ERR LIMIT can't be negative")
+	assert.EqualError(t, rdb.Do(ctx, "sintercard", "1", "s1", "s2").Err(), "ERR syntax error")
+
+	keys := rdb.CommandGetKeys(ctx, "sintercard", "2", "s1", "s2", "limit", "1").Val()
+	assert.Equal(t, []string{"s1", "s2"}, keys)
+}
+
+func TestSScan(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	// A small set is returned whole by the first call
+	assert.Nil(t, rdb.SAdd(ctx, "small", "a", "b", "ab").Err())
+	members, cursor := rdb.SScan(ctx, "small", 0, "", 1).Val()
+	assert.Equal(t, uint64(0), cursor)
+	assert.Equal(t, []string{"a", "b", "ab"}, members)
+	members, _ = rdb.SScan(ctx, "small", 0, "a*", 0).Val()
+	assert.Equal(t, []string{"a", "ab"}, members)
+
+	// A large one is walked with the cursor
+	expected := make([]string, 0, 1000)
+	for i := range 1000 {
+		member := "m" + strconv.Itoa(i)
+		expected = append(expected, member)
+		assert.Nil(t, rdb.SAdd(ctx, "large", member).Err())
+	}
+	seen := map[string]bool{}
+	calls := 0
+	for cursor = 0; ; calls++ {
+		members, cursor = rdb.SScan(ctx, "large", cursor, "", 20).Val()
+		for _, member := range members {
+			seen[member] = true
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Greater(t, calls, 1)
+	actual := make([]string, 0, len(seen))
+	for member := range seen {
+		actual = append(actual, member)
+	}
+	sort.Strings(actual)
+	sort.Strings(expected)
+	assert.Equal(t, expected, actual)
+
+	members, cursor = rdb.SScan(ctx, "nokey", 0, "", 0).Val()
+	assert.Equal(t, uint64(0), cursor)
+	assert.Empty(t, members)
+	assert.EqualError(t, rdb.Do(ctx, "sscan", "small", "x").Err(), "ERR invalid cursor")
+	assert.EqualError(t, rdb.Do(ctx, "sscan", "small", "0", "count", "0").Err(), "ERR syntax error")
+}
+
+func TestSetEncoding(t *testing.T) {
+	config := internal.DefaultConfig()
+	config.SetMaxIntsetEntries = 6
+	config.SetMaxListpackEntries = 4
+	config.SetMaxListpackValue = 8
+	hostPort := startTestServerWithConfig(t, config)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	// Integers are kept sorted in an intset
+	assert.Nil(t, rdb.SAdd(ctx, "ints", "3", "-1", "2").Err())
+	assert.Equal(t, "intset", rdb.ObjectEncoding(ctx, "ints").Val())
+	assert.Equal(t, []string{"-1", "2", "3"}, rdb.SMembers(ctx, "ints").Val())
+	assert.True(t, rdb.SIsMember(ctx, "ints", "2").Val())
+	assert.False(t, rdb.SIsMember(ctx, "ints", "02").Val())
+	assert.Nil(t, rdb.SAdd(ctx, "ints", "a").Err())
+	assert.Equal(t, "listpack", rdb.ObjectEncoding(ctx, "ints").Val(), "converted by a member that is not an integer")
+	assert.ElementsMatch(t, []string{"-1", "2", "3", "a"}, rdb.SMembers(ctx, "ints").Val())
+	assert.Nil(t, rdb.SAdd(ctx, "padded", "1", "01").Err())
+	assert.Equal(t, "listpack", rdb.ObjectEncoding(ctx, "padded").Val(), "only canonical integers fit an intset")
+
+	assert.Nil(t, rdb.SAdd(ctx, "many", "1", "2", "3", "4", "5", "6").Err())
+	assert.Equal(t, "intset", rdb.ObjectEncoding(ctx, "many").Val())
+	assert.Nil(t, rdb.SAdd(ctx, "many", "7").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "many").Val(), "too large for an intset and for a listpack")
+	// and not converted back once it shrinks
+	assert.Nil(t, rdb.SRem(ctx, "many", "1", "2", "3", "4", "5").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "many").Val())
+	assert.ElementsMatch(t, []string{"6", "7"}, rdb.SMembers(ctx, "many").Val())
+	for _, member := range rdb.SRandMemberN(ctx, "many", -10).Val() {
+		assert.Contains(t, []string{"6", "7"}, member)
+	}
+
+	assert.Nil(t, rdb.SAdd(ctx, "mixed", "a", "b", "c", "d").Err())
+	assert.Equal(t, "listpack", rdb.ObjectEncoding(ctx, "mixed").Val())
+	assert.Nil(t, rdb.SAdd(ctx, "mixed", "e").Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "mixed").Val(), "converted past the entries limit")
+	assert.Nil(t, rdb.SAdd(ctx, "long", "a", strings.Repeat("x", 9)).Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "long").Val(), "converted past the value limit")
+
+	// Results stored by the STORE variants get the encoding of their members
+	assert.Nil(t, rdb.SUnionStore(ctx, "dst", "many").Err())
+	assert.Equal(t, "intset", rdb.ObjectEncoding(ctx, "dst").Val())
+	// and copies keep the encoding and the members
+	assert.Nil(t, rdb.Copy(ctx, "mixed", "mixed2", 0, false).Err())
+	assert.Equal(t, "hashtable", rdb.ObjectEncoding(ctx, "mixed2").Val())
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, rdb.SMembers(ctx, "mixed2").Val())
+}
+
+func TestSetsAndWrongType(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.Set(ctx, "str", "v", 0).Err())
+	assert.Nil(t, rdb.SAdd(ctx, "s", "a").Err())
+
+	wrongType := "WRONGTYPE Operation against a key holding the wrong kind of value"
+	assert.EqualError(t, rdb.SAdd(ctx, "str", "a").Err(), wrongType)
+	assert.EqualError(t, rdb.SMembers(ctx, "str").Err(), wrongType)
+	assert.EqualError(t, rdb.SPop(ctx, "str").Err(), wrongType)
+	assert.EqualError(t, rdb.SMove(ctx, "s", "str", "a").Err(), wrongType)
+	assert.EqualError(t, rdb.SInter(ctx, "nokey", "str").Err(), wrongType, "every key is checked")
+	assert.EqualError(t, rdb.SUnionStore(ctx, "dst", "s", "str").Err(), wrongType)
+	assert.EqualError(t, rdb.SScan(ctx, "str", 0, "", 0).Err(), wrongType)
+	assert.EqualError(t, rdb.Get(ctx, "s").Err(), wrongType)
+	assert.EqualError(t, rdb.HSet(ctx, "s", "a", "1").Err(), wrongType)
+	assert.Equal(t, "set", rdb.Type(ctx, "s").Val())
+	assert.True(t, rdb.SIsMember(ctx, "s", "a").Val(), "the failed SMOVE left the source alone")
+}
+
+// uniqueStrings returns the strings with the duplicates removed.
+func uniqueStrings(strs []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, str := range strs {
+		if !seen[str] {
+			seen[str] = true
+			unique = append(unique, str)
+		}
+	}
+	return unique
+}
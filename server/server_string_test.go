@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAndStrLen(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(0), rdb.StrLen(ctx, "k").Val())
+	assert.Equal(t, int64(5), rdb.Append(ctx, "k", "Hello").Val())
+	assert.Nil(t, rdb.Expire(ctx, "k", time.Hour).Err())
+	assert.Equal(t, int64(11), rdb.Append(ctx, "k", " World").Val())
+	assert.Equal(t, "Hello World", rdb.Get(ctx, "k").Val())
+	assert.Equal(t, int64(11), rdb.StrLen(ctx, "k").Val())
+	assert.Greater(t, rdb.TTL(ctx, "k").Val(), time.Duration(0), "APPEND keeps the TTL")
+
+	// Values are binary safe
+	assert.Equal(t, int64(14), rdb.Append(ctx, "k", "\x00\r\n").Val())
+	assert.Equal(t, "Hello World\x00\r\n", rdb.Get(ctx, "k").Val())
+}
+
+func TestGetRange(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.Set(ctx, "k", "This is a string", 0).Err())
+
+	tests := []struct {
+		start, end int64
+		expected   string
+	}{
+		{0, 3, "This"},
+		{-3, -1, "ing"},
+		{0, -1, "This is a string"},
+		{10, 100, "string"},
+		{-100, 3, "This"},
+		{-1, -5, ""},
+		{5, 3, ""},
+		{16, 20, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, rdb.GetRange(ctx, "k", tt.start, tt.end).Val(), "GETRANGE %d %d", tt.start, tt.end)
+	}
+	assert.Equal(t, "", rdb.GetRange(ctx, "missing", 0, -1).Val())
+	assert.EqualError(t, rdb.Do(ctx, "getrange", "k", "a", "1").Err(), "ERR value is not an integer or out of range")
+}
+
+func TestSetRange(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Nil(t, rdb.Set(ctx, "k", "Hello World", 0).Err())
+	// A reply holding the old value is not affected by the update
+	pipe := rdb.Pipeline()
+	get := pipe.Get(ctx, "k")
+	setRange := pipe.SetRange(ctx, "k", 6, "Redis")
+	_, err := pipe.Exec(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello World", get.Val())
+	assert.Equal(t, int64(11), setRange.Val())
+	assert.Equal(t, "Hello Redis", rdb.Get(ctx, "k").Val())
+
+	// Missing keys and gaps are zero padded
+	assert.Equal(t, int64(11), rdb.SetRange(ctx, "new", 6, "Redis").Val())
+	assert.Equal(t, "\x00\x00\x00\x00\x00\x00Redis", rdb.Get(ctx, "new").Val())
+	assert.Equal(t, int64(13), rdb.SetRange(ctx, "new", 11, "!!").Val())
+
+	// Setting nothing does not create the key
+	assert.Equal(t, int64(0), rdb.SetRange(ctx, "empty", 10, "").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "empty").Val())
+	assert.Equal(t, int64(11), rdb.SetRange(ctx, "k", 100, "").Val())
+
+	assert.EqualError(t, rdb.SetRange(ctx, "k", -1, "x").Err(), "ERR offset is out of range")
+	assert.EqualError(t, rdb.SetRange(ctx, "k", 512*1024*1024-1, "ab").Err(),
+		"ERR string exceeds maximum allowed size (proto-max-bulk-len)")
+}
+
+func TestGetDelGetSetAndSetNX(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, redis.Nil, rdb.GetDel(ctx, "k").Err())
+	assert.Equal(t, redis.Nil, rdb.GetSet(ctx, "k", "1").Err())
+	assert.Nil(t, rdb.Expire(ctx, "k", time.Hour).Err())
+	assert.Equal(t, "1", rdb.GetSet(ctx, "k", "2").Val())
+	assert.Equal(t, time.Duration(-1), rdb.TTL(ctx, "k").Val(), "GETSET clears the TTL")
+	assert.Equal(t, "2", rdb.GetDel(ctx, "k").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "k").Val())
+
+	assert.True(t, rdb.SetNX(ctx, "nx", "1", 0).Val())
+	assert.False(t, rdb.SetNX(ctx, "nx", "2", 0).Val())
+	assert.Equal(t, "1", rdb.Get(ctx, "nx").Val())
+}
+
+func TestGetEx(t *testing.T) {
+	clock, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, redis.Nil, rdb.GetEx(ctx, "k", time.Second).Err())
+	assert.Nil(t, rdb.Set(ctx, "k", "v", 0).Err())
+	assert.Equal(t, "v", rdb.GetEx(ctx, "k", 10*time.Second).Val())
+	assert.Equal(t, 10*time.Second, rdb.TTL(ctx, "k").Val())
+	assert.Equal(t, "v", rdb.Do(ctx, "getex", "k").Val(), "no option keeps the TTL")
+	assert.Equal(t, 10*time.Second, rdb.TTL(ctx, "k").Val())
+	assert.Equal(t, "v", rdb.Do(ctx, "getex", "k", "persist").Val())
+	assert.Equal(t, time.Duration(-1), rdb.TTL(ctx, "k").Val())
+	assert.Equal(t, "v", rdb.Do(ctx, "getex", "k", "pxat", clock.Now().Add(5*time.Second).UnixMilli()).Val())
+	assert.Equal(t, 5*time.Second, rdb.PTTL(ctx, "k").Val())
+
+	// A deadline in the past deletes the key after returning it
+	assert.Equal(t, "v", rdb.Do(ctx, "getex", "k", "exat", clock.Now().Unix()-1).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "k").Val())
+
+	assert.Nil(t, rdb.Set(ctx, "k", "v", 0).Err())
+	assert.EqualError(t, rdb.Do(ctx, "getex", "k", "ex", "0").Err(), "ERR invalid expire time in 'getex' command")
+	assert.EqualError(t, rdb.Do(ctx, "getex", "k", "ex", "1", "persist").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "getex", "k", "px").Err(), "ERR syntax error")
+}
+
+func TestSetExAndPSetEx(t *testing.T) {
+	_, hostPort := startTestServerWithClock(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, "OK", rdb.SetEx(ctx, "k", "v", 10*time.Second).Val())
+	assert.Equal(t, 10*time.Second, rdb.TTL(ctx, "k").Val())
+	assert.Equal(t, "OK", rdb.Do(ctx, "psetex", "k", "1500", "w").Val())
+	assert.Equal(t, 1500*time.Millisecond, rdb.PTTL(ctx, "k").Val())
+	assert.Equal(t, "w", rdb.Get(ctx, "k").Val())
+
+	assert.EqualError(t, rdb.Do(ctx, "setex", "k", "0", "v").Err(), "ERR invalid expire time in 'setex' command")
+	assert.EqualError(t, rdb.Do(ctx, "psetex", "k", "-5", "v").Err(), "ERR invalid expire time in 'psetex' command")
+	assert.EqualError(t, rdb.Do(ctx, "setex", "k", "x", "v").Err(), "ERR value is not an integer or out of range")
+}
+
+func TestLcs(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.Set(ctx, "key1", "ohmytext", 0).Err())
+	assert.Nil(t, rdb.Set(ctx, "key2", "mynewtext", 0).Err())
+
+	lcs, err := rdb.LCS(ctx, &redis.LCSQuery{Key1: "key1", Key2: "key2"}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "mytext", lcs.MatchString)
+
+	lcs, err = rdb.LCS(ctx, &redis.LCSQuery{Key1: "key1", Key2: "key2", Len: true}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), lcs.Len)
+
+	lcs, err = rdb.LCS(ctx, &redis.LCSQuery{Key1: "key1", Key2: "key2", Idx: true}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), lcs.Len)
+	assert.Equal(t, []redis.LCSMatchedPosition{
+		{Key1: redis.LCSPosition{Start: 4, End: 7}, Key2: redis.LCSPosition{Start: 5, End: 8}},
+		{Key1: redis.LCSPosition{Start: 2, End: 3}, Key2: redis.LCSPosition{Start: 0, End: 1}},
+	}, lcs.Matches)
+
+	lcs, err = rdb.LCS(ctx, &redis.LCSQuery{Key1: "key1", Key2: "key2", Idx: true, MinMatchLen: 4, WithMatchLen: true}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, []redis.LCSMatchedPosition{
+		{Key1: redis.LCSPosition{Start: 4, End: 7}, Key2: redis.LCSPosition{Start: 5, End: 8}, MatchLen: 4},
+	}, lcs.Matches)
+
+	lcs, err = rdb.LCS(ctx, &redis.LCSQuery{Key1: "key1", Key2: "missing"}).Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "", lcs.MatchString)
+
+	assert.EqualError(t, rdb.Do(ctx, "lcs", "key1", "key2", "len", "idx").Err(),
+		"ERR If you want both the length and indexes, please just use IDX.")
+	assert.EqualError(t, rdb.Do(ctx, "lcs", "key1", "key2", "foo").Err(), "ERR syntax error")
+
+	assert.Nil(t, rdb.Set(ctx, "big1", strings.Repeat("a", 20000), 0).Err())
+	assert.Nil(t, rdb.Set(ctx, "big2", strings.Repeat("a", 20000), 0).Err())
+	assert.EqualError(t, rdb.Do(ctx, "lcs", "big1", "big2").Err(),
+		"ERR Insufficient memory, transient memory for LCS exceeds proto-max-bulk-len")
+}
+
+func TestMGetMSetAndMSetNX(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, "OK", rdb.MSet(ctx, "a", "1", "b", "2", "a", "3").Val())
+	assert.Equal(t, []interface{}{"3", "2", nil}, rdb.MGet(ctx, "a", "b", "c").Val())
+	assert.EqualError(t, rdb.Do(ctx, "mset", "a", "1", "b").Err(), "ERR wrong number of arguments for 'mset' command")
+
+	// MSETNX sets nothing if any of the keys exists
+	assert.False(t, rdb.MSetNX(ctx, "c", "1", "b", "1").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "c").Val())
+	assert.Equal(t, "2", rdb.Get(ctx, "b").Val())
+	assert.True(t, rdb.MSetNX(ctx, "c", "1", "d", "2").Val())
+	assert.Equal(t, []interface{}{"1", "2"}, rdb.MGet(ctx, "c", "d").Val())
+}
+
+func TestMSetIsAtomic(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	writer := getRedisClient(t, hostPort)
+	reader := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, writer.MSet(ctx, "a", "0", "b", "0", "c", "0").Err())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 200; i++ {
+			n := strconv.Itoa(i)
+			writer.MSet(ctx, "a", n, "b", n, "c", n)
+		}
+	}()
+	for {
+		values := reader.MGet(ctx, "a", "b", "c").Val()
+		assert.Equal(t, values[0], values[1])
+		assert.Equal(t, values[0], values[2])
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
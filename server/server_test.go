@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"net"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -71,6 +72,62 @@ func TestPipelineSetAndGet(t *testing.T) {
 	assert.Equal(t, "bar\r\n", resp3)
 }
 
+func TestUnknownCommandInPipeline(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, err := net.DialTimeout("tcp", hostPort, 1*time.Second)
+	assert.Nilf(t, err, "failed to connect to server: %f", err)
+	t.Cleanup(func() { conn.Close() })
+
+	setCmd := concatCommands("*3", "$3", "SET", "$3", "foo", "$3", "bar")
+	unknownCmd := concatCommands("*3", "$6", "FOOBAR", "$1", "a", "$2", "bc")
+	getCmd := concatCommands("*2", "$3", "GET", "$3", "foo")
+	_, err = conn.Write([]byte(setCmd + unknownCmd + getCmd))
+	assert.Nilf(t, err, "failed to write: %f", err)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reader := bufio.NewReader(conn)
+	expected := []string{
+		"+OK\r\n",
+		"-ERR unknown command 'foobar', with args beginning with: 'a' 'bc' \r\n",
+		"$3\r\n",
+		"bar\r\n",
+	}
+	for _, line := range expected {
+		actual, err := reader.ReadString('\n')
+		assert.Nilf(t, err, "failed to read response: %f", err)
+		assert.Equal(t, line, actual)
+	}
+}
+
+func TestPipelineAcrossCommandTypesKeepsOrder(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	// PING runs on a different goroutine than SET and GET
+	var pipeline, expected strings.Builder
+	for i := range 200 {
+		value := strconv.Itoa(i)
+		pipeline.WriteString(concatCommands("*3", "$3", "SET", "$1", "k", "$"+strconv.Itoa(len(value)), value))
+		pipeline.WriteString(concatCommands("*2", "$4", "PING", "$"+strconv.Itoa(len(value)), value))
+		pipeline.WriteString(concatCommands("*2", "$3", "GET", "$1", "k"))
+		expected.WriteString("+OK\r\n")
+		expected.WriteString(concatCommands("$"+strconv.Itoa(len(value)), value))
+		expected.WriteString(concatCommands("$"+strconv.Itoa(len(value)), value))
+	}
+	sendAndExpect(t, conn, reader, pipeline.String(), expected.String())
+}
+
+func TestProtocolErrorIsSentAfterPendingReplies(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	conn.Write([]byte(concatCommands("*1", "$4", "PING") + concatCommands("*1", ":1")))
+	reply, err := reader.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "+PONG\r\n", reply)
+	expectProtocolError(t, reader, "expected '$', got ':'")
+}
+
 func TestMalformedInputDoesNotPanic(t *testing.T) {
 	tests := []struct {
 		name  string
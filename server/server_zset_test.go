@@ -0,0 +1,457 @@
+package server
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/ram-the-coder/redisgo/internal/resp"
+	"github.com/ram-the-coder/redisgo/internal/resp/rtypes"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZAdd(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	assert.Equal(t, int64(3), rdb.ZAdd(ctx, "z", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"},
+		redis.Z{Score: 3, Member: "c"}).Val())
+	assert.Equal(t, int64(1), rdb.ZAdd(ctx, "z", redis.Z{Score: 10, Member: "a"}, redis.Z{Score: 4, Member: "d"}).Val(),
+		"only new members are counted")
+	assert.Equal(t, float64(10), rdb.ZScore(ctx, "z", "a").Val())
+	assert.Equal(t, int64(4), rdb.ZCard(ctx, "z").Val())
+	assert.Equal(t, int64(0), rdb.ZCard(ctx, "nokey").Val())
+
+	// CH counts the updated members too
+	assert.Equal(t, int64(2), rdb.Do(ctx, "zadd", "z", "ch", "1", "a", "2", "b", "5", "e").Val())
+	// NX only adds and XX only updates
+	assert.Equal(t, int64(1), rdb.Do(ctx, "zadd", "z", "nx", "100", "a", "6", "f").Val())
+	assert.Equal(t, float64(1), rdb.ZScore(ctx, "z", "a").Val())
+	assert.Equal(t, int64(1), rdb.Do(ctx, "zadd", "z", "xx", "ch", "100", "a", "7", "g").Val())
+	assert.Equal(t, float64(100), rdb.ZScore(ctx, "z", "a").Val())
+	assert.Equal(t, redis.Nil, rdb.ZScore(ctx, "z", "g").Err())
+	assert.Equal(t, int64(0), rdb.Do(ctx, "zadd", "nokey", "xx", "1", "a").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "nokey").Val())
+	// GT and LT only move scores one way, but still add new members
+	assert.Equal(t, int64(2), rdb.Do(ctx, "zadd", "z", "gt", "ch", "50", "a", "200", "b", "8", "h").Val())
+	assert.Equal(t, float64(100), rdb.ZScore(ctx, "z", "a").Val())
+	assert.Equal(t, float64(200), rdb.ZScore(ctx, "z", "b").Val())
+	assert.Equal(t, int64(1), rdb.Do(ctx, "zadd", "z", "lt", "ch", "50", "a", "300", "b").Val())
+	assert.Equal(t, float64(50), rdb.ZScore(ctx, "z", "a").Val())
+
+	// INCR replies with the new score, or nil when the options prevent it
+	assert.Equal(t, float64(52.5), rdb.Do(ctx, "zadd", "z", "incr", "2.5", "a").Val())
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "zadd", "z", "nx", "incr", "1", "a").Err())
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "zadd", "z", "gt", "incr", "-1", "a").Err())
+	assert.Equal(t, float64(3), rdb.ZIncrBy(ctx, "z", 3, "new").Val())
+	assert.Equal(t, float64(1), rdb.ZIncrBy(ctx, "z", -2, "new").Val())
+	assert.Equal(t, math.Inf(1), rdb.ZIncrBy(ctx, "z", math.Inf(1), "new").Val())
+	assert.EqualError(t, rdb.Do(ctx, "zincrby", "z", "-inf", "new").Err(), "ERR resulting score is not a number (NaN)")
+
+	assert.EqualError(t, rdb.Do(ctx, "zadd", "z", "1", "a", "2").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zadd", "z", "nx", "xx", "1", "a").Err(),
+		"ERR XX and NX options at the same time are not compatible")
+	assert.EqualError(t, rdb.Do(ctx, "zadd", "z", "gt", "lt", "1", "a").Err(),
+		"ERR GT, LT, and/or NX options at the same time are not compatible")
+	assert.EqualError(t, rdb.Do(ctx, "zadd", "z", "incr", "1", "a", "2", "b").Err(),
+		"ERR INCR option supports a single increment-element pair")
+	assert.EqualError(t, rdb.Do(ctx, "zadd", "z", "x", "a").Err(), "ERR value is not a valid float")
+	assert.EqualError(t, rdb.Do(ctx, "zadd", "z", "nan", "a").Err(), "ERR value is not a valid float")
+	assert.EqualError(t, rdb.Do(ctx, "zincrby", "z", "x", "a").Err(), "ERR value is not a valid float")
+}
+
+func TestZRange(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"},
+		redis.Z{Score: 2, Member: "c"}, redis.Z{Score: 3, Member: "d"}, redis.Z{Score: math.Inf(1), Member: "e"}).Err())
+
+	// By rank, with ties ordered by member
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, rdb.ZRange(ctx, "z", 0, -1).Val())
+	assert.Equal(t, []string{"c", "d"}, rdb.ZRange(ctx, "z", 2, 3).Val())
+	assert.Equal(t, []string{"d", "e"}, rdb.ZRange(ctx, "z", -2, 100).Val())
+	assert.Empty(t, rdb.ZRange(ctx, "z", 3, 2).Val())
+	assert.Equal(t, []string{"e", "d"}, rdb.ZRevRange(ctx, "z", 0, 1).Val())
+	assert.Equal(t, []string{"e", "d"}, rdb.ZRangeArgs(ctx, redis.ZRangeArgs{Key: "z", Start: 0, Stop: 1, Rev: true}).Val())
+	assert.Empty(t, rdb.ZRange(ctx, "nokey", 0, -1).Val())
+
+	// By score, where "(" makes an end exclusive
+	byScore := func(start, stop string, rev bool, offset, count int64) []string {
+		return rdb.ZRangeArgs(ctx, redis.ZRangeArgs{Key: "z", Start: start, Stop: stop, ByScore: true, Rev: rev,
+			Offset: offset, Count: count}).Val()
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, byScore("2", "3", false, 0, 0))
+	assert.Equal(t, []string{"d"}, byScore("(2", "3", false, 0, 0))
+	assert.Equal(t, []string{"b", "c"}, byScore("2", "(3", false, 0, 0))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, byScore("-inf", "+inf", false, 0, 0))
+	assert.Equal(t, []string{"d", "e"}, byScore("(2", "inf", false, 0, 0))
+	assert.Empty(t, byScore("3", "2", false, 0, 0))
+	// go-redis swaps the ends of reversed ranges, which start at the highest
+	assert.Equal(t, []string{"d", "c", "b"}, byScore("2", "3", true, 0, 0))
+	assert.Equal(t, []string{"c", "d"}, byScore("-inf", "+inf", false, 2, 2))
+	assert.Equal(t, []string{"d", "e"}, byScore("-inf", "+inf", false, 3, -1))
+	assert.Equal(t, []string{"d", "c"}, byScore("-inf", "+inf", true, 1, 2))
+	assert.Equal(t, []any{"d", "c", "b"}, rdb.Do(ctx, "zrange", "z", "3", "2", "byscore", "rev").Val())
+	assert.Equal(t, []string{"b", "c", "d"}, rdb.ZRangeByScore(ctx, "z", &redis.ZRangeBy{Min: "2", Max: "3"}).Val())
+	assert.Equal(t, []string{"d", "c"}, rdb.ZRevRangeByScore(ctx, "z", &redis.ZRangeBy{Min: "2", Max: "3", Count: 2}).Val())
+	assert.Equal(t, []redis.Z{{Score: 3, Member: "d"}, {Score: math.Inf(1), Member: "e"}},
+		rdb.ZRangeByScoreWithScores(ctx, "z", &redis.ZRangeBy{Min: "(2", Max: "+inf"}).Val())
+	assert.Equal(t, int64(3), rdb.ZCount(ctx, "z", "(1", "3").Val())
+	assert.Equal(t, int64(0), rdb.ZCount(ctx, "nokey", "-inf", "+inf").Val())
+
+	// By member, which is meant for members with the same score
+	assert.Nil(t, rdb.ZAdd(ctx, "lex", redis.Z{Member: "a"}, redis.Z{Member: "b"}, redis.Z{Member: "c"},
+		redis.Z{Member: "d"}).Err())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, rdb.ZRangeByLex(ctx, "lex", &redis.ZRangeBy{Min: "-", Max: "+"}).Val())
+	assert.Equal(t, []string{"b", "c"}, rdb.ZRangeByLex(ctx, "lex", &redis.ZRangeBy{Min: "[b", Max: "(d"}).Val())
+	assert.Equal(t, []string{"c", "b"}, rdb.ZRevRangeByLex(ctx, "lex", &redis.ZRangeBy{Min: "(a", Max: "[c"}).Val())
+	assert.Equal(t, []string{"c"}, rdb.ZRangeArgs(ctx, redis.ZRangeArgs{Key: "lex", Start: "[b", Stop: "+", ByLex: true,
+		Offset: 1, Count: 1}).Val())
+	assert.Empty(t, rdb.ZRangeByLex(ctx, "lex", &redis.ZRangeBy{Min: "+", Max: "-"}).Val())
+	assert.Equal(t, int64(2), rdb.ZLexCount(ctx, "lex", "(a", "[c").Val())
+
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "a", "1").Err(), "ERR value is not an integer or out of range")
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "x", "1", "byscore").Err(), "ERR min or max is not a float")
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "(nan", "1", "byscore").Err(), "ERR min or max is not a float")
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "a", "+", "bylex").Err(), "ERR min or max not valid string range item")
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "0", "1", "limit", "0", "1").Err(),
+		"ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "-", "+", "bylex", "withscores").Err(),
+		"ERR syntax error, WITHSCORES not supported in combination with BYLEX")
+	assert.EqualError(t, rdb.Do(ctx, "zrange", "z", "0", "1", "byscore", "bylex").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zrangebyscore", "z", "0", "1", "rev").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zlexcount", "z", "a", "b").Err(), "ERR min or max not valid string range item")
+}
+
+func TestZSetReplies(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	sendAndExpect(t, conn, reader, "ZADD z 1.5 a inf b\r\n", ":2\r\n")
+	// RESP2 clients get scores as strings, and members with their scores in
+	// a flat array
+	sendAndExpect(t, conn, reader, "ZSCORE z b\r\n", "$3\r\ninf\r\n")
+	sendAndExpect(t, conn, reader, "ZRANGE z 0 -1 WITHSCORES\r\n",
+		"*4\r\n$1\r\na\r\n$3\r\n1.5\r\n$1\r\nb\r\n$3\r\ninf\r\n")
+	sendAndExpect(t, conn, reader, "ZINCRBY z 0.1 a\r\n", "$3\r\n1.6\r\n")
+	sendAndExpect(t, conn, reader, "ZRANK z b WITHSCORE\r\n", "*2\r\n:1\r\n$3\r\ninf\r\n")
+	sendAndExpect(t, conn, reader, "ZRANK z nomember WITHSCORE\r\n", "*-1\r\n")
+
+	// and RESP3 clients doubles, and pairs nested in arrays
+	decoder := resp.NewDecoder(reader)
+	conn.Write([]byte("HELLO 3\r\n"))
+	_, err := decoder.Decode()
+	assert.Nil(t, err)
+	conn.Write([]byte("ZRANGE z 0 -1 WITHSCORES\r\n"))
+	reply, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, &rtypes.Array{Elements: []rtypes.RespDataType{
+		&rtypes.Array{Elements: []rtypes.RespDataType{rtypes.NewBulkString("a"), &rtypes.Double{Value: 1.6}}},
+		&rtypes.Array{Elements: []rtypes.RespDataType{rtypes.NewBulkString("b"), &rtypes.Double{Value: math.Inf(1)}}},
+	}}, reply)
+	// A pop without a count is not nested
+	conn.Write([]byte("ZPOPMIN z\r\n"))
+	reply, err = decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, &rtypes.Array{Elements: []rtypes.RespDataType{
+		rtypes.NewBulkString("a"), &rtypes.Double{Value: 1.6},
+	}}, reply)
+}
+
+func TestZSetScoresAreFormattedLikeRedis(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	conn, reader := dialTestServer(t, hostPort)
+
+	sendAndExpect(t, conn, reader, "ZADD z 1000000 a 1234567.5 b 0.00001 c 1.5e-7 d 1e21 e\r\n", ":5\r\n")
+	sendAndExpect(t, conn, reader, "ZSCORE z a\r\n", "$7\r\n1000000\r\n")
+	sendAndExpect(t, conn, reader, "ZSCORE z b\r\n", "$9\r\n1234567.5\r\n")
+	sendAndExpect(t, conn, reader, "ZRANGE z 0 -1 WITHSCORES\r\n",
+		"*10\r\n$1\r\nd\r\n$6\r\n1.5e-7\r\n$1\r\nc\r\n$7\r\n0.00001\r\n"+
+			"$1\r\na\r\n$7\r\n1000000\r\n$1\r\nb\r\n$9\r\n1234567.5\r\n$1\r\ne\r\n$5\r\n1e+21\r\n")
+
+	conn.Write([]byte("HELLO 3\r\n"))
+	_, err := resp.NewDecoder(reader).Decode()
+	assert.Nil(t, err)
+	sendAndExpect(t, conn, reader, "ZSCORE z a\r\n", ",1000000\r\n")
+	sendAndExpect(t, conn, reader, "ZRANGE z 1 2 WITHSCORES\r\n",
+		"*2\r\n*2\r\n$1\r\nc\r\n,0.00001\r\n*2\r\n$1\r\na\r\n,1000000\r\n")
+}
+
+func TestZRankZScoreAndZRem(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 10, Member: "a"}, redis.Z{Score: 20, Member: "b"},
+		redis.Z{Score: 30, Member: "c"}).Err())
+
+	assert.Equal(t, int64(0), rdb.ZRank(ctx, "z", "a").Val())
+	assert.Equal(t, int64(2), rdb.ZRank(ctx, "z", "c").Val())
+	assert.Equal(t, int64(0), rdb.ZRevRank(ctx, "z", "c").Val())
+	assert.Equal(t, redis.RankScore{Rank: 1, Score: 20}, rdb.ZRankWithScore(ctx, "z", "b").Val())
+	assert.Equal(t, redis.RankScore{Rank: 2, Score: 10}, rdb.ZRevRankWithScore(ctx, "z", "a").Val())
+	assert.Equal(t, redis.Nil, rdb.ZRank(ctx, "z", "missing").Err())
+	assert.Equal(t, redis.Nil, rdb.ZRank(ctx, "nokey", "a").Err())
+	assert.EqualError(t, rdb.Do(ctx, "zrank", "z", "a", "x").Err(), "ERR syntax error")
+
+	assert.Equal(t, []float64{10, 0, 30}, rdb.ZMScore(ctx, "z", "a", "missing", "c").Val())
+	assert.Equal(t, []any{float64(10), nil}, rdb.Do(ctx, "zmscore", "z", "a", "missing").Val())
+	assert.Equal(t, []any{nil}, rdb.Do(ctx, "zmscore", "nokey", "a").Val())
+
+	assert.Equal(t, int64(2), rdb.ZRem(ctx, "z", "a", "c", "missing").Val())
+	assert.Equal(t, int64(0), rdb.ZRem(ctx, "nokey", "a").Val())
+	assert.Equal(t, int64(0), rdb.ZRank(ctx, "z", "b").Val(), "ranks follow removals")
+	assert.Equal(t, int64(1), rdb.ZRem(ctx, "z", "b").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "z").Val(), "the key is deleted with its last member")
+}
+
+func TestZSetRanksOnLargeSortedSets(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+
+	// Build a sorted set in random order with repeated scores, then update
+	// and remove some members, and check every rank against a sorted copy
+	scores := map[string]float64{}
+	members := make([]redis.Z, 0, 1000)
+	for i := range 1000 {
+		member := "m" + strconv.Itoa(i)
+		scores[member] = float64(rand.IntN(200))
+		members = append(members, redis.Z{Score: scores[member], Member: member})
+	}
+	rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	assert.Nil(t, rdb.ZAdd(ctx, "z", members...).Err())
+	for i := 0; i < 1000; i += 7 {
+		member := "m" + strconv.Itoa(i)
+		scores[member] = float64(rand.IntN(200))
+		assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: scores[member], Member: member}).Err())
+	}
+	for i := 3; i < 1000; i += 11 {
+		member := "m" + strconv.Itoa(i)
+		delete(scores, member)
+		assert.Nil(t, rdb.ZRem(ctx, "z", member).Err())
+	}
+
+	expected := make([]string, 0, len(scores))
+	for member := range scores {
+		expected = append(expected, member)
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		a, b := expected[i], expected[j]
+		return scores[a] < scores[b] || (scores[a] == scores[b] && a < b)
+	})
+	assert.Equal(t, expected, rdb.ZRange(ctx, "z", 0, -1).Val())
+	pipe := rdb.Pipeline()
+	ranks := make([]*redis.IntCmd, len(expected))
+	for i, member := range expected {
+		ranks[i] = pipe.ZRank(ctx, "z", member)
+	}
+	_, err := pipe.Exec(ctx)
+	assert.Nil(t, err)
+	for i, rank := range ranks {
+		assert.Equal(t, int64(i), rank.Val())
+	}
+	assert.Equal(t, expected[500:510], rdb.ZRange(ctx, "z", 500, 509).Val())
+	reversed := slices.Clone(expected)
+	slices.Reverse(reversed)
+	assert.Equal(t, reversed[100:120], rdb.ZRevRange(ctx, "z", 100, 119).Val())
+
+	inRange := 0
+	for _, score := range scores {
+		if score > 50 && score <= 120 {
+			inRange++
+		}
+	}
+	assert.Equal(t, int64(inRange), rdb.ZCount(ctx, "z", "(50", "120").Val())
+}
+
+func TestZPopAndZMPop(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"},
+		redis.Z{Score: 3, Member: "c"}, redis.Z{Score: 4, Member: "d"}).Err())
+
+	assert.Equal(t, []redis.Z{{Score: 1, Member: "a"}}, rdb.ZPopMin(ctx, "z").Val())
+	assert.Equal(t, []redis.Z{{Score: 4, Member: "d"}, {Score: 3, Member: "c"}}, rdb.ZPopMax(ctx, "z", 2).Val())
+	assert.Empty(t, rdb.ZPopMin(ctx, "z", 0).Val())
+	assert.Equal(t, []redis.Z{{Score: 2, Member: "b"}}, rdb.ZPopMin(ctx, "z", 10).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "z").Val(), "the key is deleted with its last member")
+	assert.Empty(t, rdb.ZPopMin(ctx, "z").Val())
+	assert.EqualError(t, rdb.Do(ctx, "zpopmin", "z", "-1").Err(), "ERR value is out of range, must be positive")
+
+	assert.Nil(t, rdb.ZAdd(ctx, "z2", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"}).Err())
+	key, popped, err := rdb.ZMPop(ctx, "max", 5, "z", "z2").Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "z2", key)
+	assert.Equal(t, []redis.Z{{Score: 2, Member: "b"}, {Score: 1, Member: "a"}}, popped)
+	assert.Equal(t, redis.Nil, rdb.ZMPop(ctx, "min", 1, "z", "z2").Err())
+	assert.EqualError(t, rdb.Do(ctx, "zmpop", "1", "z", "middle").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zmpop", "0", "z", "min").Err(), "ERR numkeys should be greater than 0")
+
+	// RESP2 clients get the popped entries nested too
+	conn, reader := dialTestServer(t, hostPort)
+	sendAndExpect(t, conn, reader, "ZADD z 1 a\r\n", ":1\r\n")
+	sendAndExpect(t, conn, reader, "ZMPOP 1 z MIN\r\n", "*2\r\n$1\r\nz\r\n*1\r\n*2\r\n$1\r\na\r\n$1\r\n1\r\n")
+}
+
+func TestZSetOperations(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.ZAdd(ctx, "z1", redis.Z{Score: 1, Member: "a"}, redis.Z{Score: 2, Member: "b"},
+		redis.Z{Score: 3, Member: "c"}).Err())
+	assert.Nil(t, rdb.ZAdd(ctx, "z2", redis.Z{Score: 10, Member: "b"}, redis.Z{Score: 20, Member: "c"},
+		redis.Z{Score: 30, Member: "d"}).Err())
+	assert.Nil(t, rdb.SAdd(ctx, "s", "c", "d").Err())
+
+	assert.Equal(t, []redis.Z{{Score: 1, Member: "a"}, {Score: 12, Member: "b"}, {Score: 23, Member: "c"},
+		{Score: 30, Member: "d"}}, rdb.ZUnionWithScores(ctx, redis.ZStore{Keys: []string{"z1", "z2", "nokey"}}).Val())
+	assert.Equal(t, []redis.Z{{Score: 2, Member: "a"}, {Score: 14, Member: "b"}, {Score: 26, Member: "c"},
+		{Score: 30, Member: "d"}},
+		rdb.ZUnionWithScores(ctx, redis.ZStore{Keys: []string{"z1", "z2"}, Weights: []float64{2, 1}}).Val())
+	assert.Equal(t, []redis.Z{{Score: 10, Member: "b"}, {Score: 20, Member: "c"}},
+		rdb.ZInterWithScores(ctx, &redis.ZStore{Keys: []string{"z1", "z2"}, Aggregate: "max"}).Val())
+	assert.Equal(t, []string{"b", "c"}, rdb.ZInter(ctx, &redis.ZStore{Keys: []string{"z2", "z1"}, Aggregate: "min"}).Val())
+	assert.Empty(t, rdb.ZInter(ctx, &redis.ZStore{Keys: []string{"z1", "nokey"}}).Val())
+	assert.Equal(t, []redis.Z{{Score: 1, Member: "a"}, {Score: 2, Member: "b"}},
+		rdb.ZDiffWithScores(ctx, "z1", "s").Val())
+	assert.Equal(t, []string{"a"}, rdb.ZDiff(ctx, "z1", "z2").Val())
+
+	// Sets count as sorted sets whose members all score 1
+	assert.Equal(t, []redis.Z{{Score: 21, Member: "c"}, {Score: 31, Member: "d"}},
+		rdb.ZInterWithScores(ctx, &redis.ZStore{Keys: []string{"z2", "s"}}).Val())
+	// and a weight of 0 takes infinite scores to 0
+	assert.Nil(t, rdb.ZAdd(ctx, "inf", redis.Z{Score: math.Inf(1), Member: "a"}).Err())
+	assert.Equal(t, []redis.Z{{Score: 0, Member: "a"}},
+		rdb.ZUnionWithScores(ctx, redis.ZStore{Keys: []string{"inf"}, Weights: []float64{0}}).Val())
+
+	assert.Equal(t, int64(4), rdb.ZUnionStore(ctx, "dst", &redis.ZStore{Keys: []string{"z1", "z2"}}).Val())
+	assert.Equal(t, float64(23), rdb.ZScore(ctx, "dst", "c").Val())
+	assert.Equal(t, int64(2), rdb.ZInterStore(ctx, "dst", &redis.ZStore{Keys: []string{"z1", "z2"}}).Val())
+	assert.Equal(t, []string{"b", "c"}, rdb.ZRange(ctx, "dst", 0, -1).Val())
+	assert.Equal(t, int64(1), rdb.ZDiffStore(ctx, "dst", "z1", "z2").Val())
+	assert.Equal(t, int64(0), rdb.ZInterStore(ctx, "dst", &redis.ZStore{Keys: []string{"z1", "nokey"}}).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "dst").Val(), "an empty result deletes the destination")
+
+	assert.EqualError(t, rdb.Do(ctx, "zunion", "0", "z1").Err(), "ERR at least 1 input key is needed for 'zunion' command")
+	assert.EqualError(t, rdb.Do(ctx, "zunionstore", "dst", "0", "z1").Err(),
+		"ERR at least 1 input key is needed for 'zunionstore' command")
+	assert.EqualError(t, rdb.Do(ctx, "zunion", "3", "z1", "z2").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zunion", "2", "z1", "z2", "weights", "1", "x").Err(), "ERR weight value is not a float")
+	assert.EqualError(t, rdb.Do(ctx, "zunion", "1", "z1", "aggregate", "avg").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zdiff", "1", "z1", "weights", "1").Err(), "ERR syntax error")
+	assert.EqualError(t, rdb.Do(ctx, "zunionstore", "dst", "1", "z1", "withscores").Err(), "ERR syntax error")
+
+	keys := rdb.CommandGetKeys(ctx, "zunionstore", "dst", "2", "z1", "z2", "weights", "1", "2").Val()
+	assert.Equal(t, []string{"dst", "z1", "z2"}, keys)
+}
+
+func TestZRangeStoreAndZRemRange(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	members := []redis.Z{}
+	for i, member := range []string{"a", "b", "c", "d", "e", "f"} {
+		members = append(members, redis.Z{Score: float64(i), Member: member})
+	}
+	assert.Nil(t, rdb.ZAdd(ctx, "z", members...).Err())
+
+	assert.Equal(t, int64(3), rdb.ZRangeStore(ctx, "dst", redis.ZRangeArgs{Key: "z", Start: "(1", Stop: "4",
+		ByScore: true}).Val())
+	assert.Equal(t, []redis.Z{{Score: 2, Member: "c"}, {Score: 3, Member: "d"}, {Score: 4, Member: "e"}},
+		rdb.ZRangeWithScores(ctx, "dst", 0, -1).Val())
+	assert.Equal(t, int64(2), rdb.ZRangeStore(ctx, "dst", redis.ZRangeArgs{Key: "z", Start: 0, Stop: 1, Rev: true}).Val())
+	assert.Equal(t, []string{"e", "f"}, rdb.ZRange(ctx, "dst", 0, -1).Val())
+	assert.Equal(t, int64(0), rdb.ZRangeStore(ctx, "dst", redis.ZRangeArgs{Key: "nokey", Start: 0, Stop: -1}).Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "dst").Val())
+	assert.EqualError(t, rdb.Do(ctx, "zrangestore", "dst", "z", "0", "1", "withscores").Err(), "ERR syntax error")
+
+	assert.Equal(t, int64(2), rdb.ZRemRangeByRank(ctx, "z", -2, -1).Val())
+	assert.Equal(t, int64(2), rdb.ZRemRangeByScore(ctx, "z", "(0", "2").Val())
+	assert.Equal(t, []string{"a", "d"}, rdb.ZRange(ctx, "z", 0, -1).Val())
+	assert.Equal(t, int64(0), rdb.ZRemRangeByLex(ctx, "z", "[x", "+").Val())
+	assert.Equal(t, int64(2), rdb.ZRemRangeByLex(ctx, "z", "-", "+").Val())
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "z").Val(), "the key is deleted with its last member")
+	assert.EqualError(t, rdb.Do(ctx, "zremrangebyscore", "z", "a", "1").Err(), "ERR min or max is not a float")
+}
+
+func TestZRandMemberAndZScan(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	members := []string{"a", "b", "c", "d", "e"}
+	for i, member := range members {
+		assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: float64(i), Member: member}).Err())
+	}
+
+	assert.Contains(t, members, rdb.ZRandMember(ctx, "z", 1).Val()[0])
+	assert.Equal(t, redis.Nil, rdb.Do(ctx, "zrandmember", "nokey").Err())
+	for _, count := range []int{1, 2, 4, 5, 10} {
+		picked := rdb.ZRandMember(ctx, "z", count).Val()
+		assert.Len(t, picked, min(count, 5))
+		assert.ElementsMatch(t, picked, uniqueStrings(picked), "positive counts pick distinct members")
+	}
+	assert.Len(t, rdb.ZRandMember(ctx, "z", -20).Val(), 20, "negative counts may repeat members")
+	for _, entry := range rdb.ZRandMemberWithScores(ctx, "z", -10).Val() {
+		assert.Equal(t, float64(slices.Index(members, entry.Member.(string))), entry.Score)
+	}
+	assert.Empty(t, rdb.ZRandMember(ctx, "nokey", 3).Val())
+
+	entries, cursor := rdb.ZScan(ctx, "z", 0, "", 0).Val()
+	assert.Equal(t, uint64(0), cursor)
+	assert.Equal(t, []string{"a", "0", "b", "1", "c", "2", "d", "3", "e", "4"}, sortedScanPairs(entries))
+	scanned, err := rdb.Do(ctx, "zscan", "z", "0", "match", "[ab]", "noscores").Slice()
+	assert.Nil(t, err)
+	matched := []string{}
+	for _, member := range scanned[1].([]any) {
+		matched = append(matched, member.(string))
+	}
+	sort.Strings(matched)
+	assert.Equal(t, []string{"a", "b"}, matched)
+	assert.EqualError(t, rdb.Do(ctx, "zscan", "z", "x").Err(), "ERR invalid cursor")
+}
+
+func TestSortedSetsAndWrongType(t *testing.T) {
+	_, hostPort := startTestServer(t)
+	rdb := getRedisClient(t, hostPort)
+	ctx := context.Background()
+	assert.Nil(t, rdb.Set(ctx, "str", "v", 0).Err())
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 1, Member: "a"}).Err())
+
+	wrongType := "WRONGTYPE Operation against a key holding the wrong kind of value"
+	assert.EqualError(t, rdb.ZAdd(ctx, "str", redis.Z{Score: 1, Member: "a"}).Err(), wrongType)
+	assert.EqualError(t, rdb.ZRange(ctx, "str", 0, -1).Err(), wrongType)
+	assert.EqualError(t, rdb.ZScore(ctx, "str", "a").Err(), wrongType)
+	assert.EqualError(t, rdb.ZPopMin(ctx, "str").Err(), wrongType)
+	assert.EqualError(t, rdb.ZUnion(ctx, redis.ZStore{Keys: []string{"z", "str"}}).Err(), wrongType)
+	assert.EqualError(t, rdb.ZRangeStore(ctx, "dst", redis.ZRangeArgs{Key: "str", Start: 0, Stop: -1}).Err(), wrongType)
+	assert.EqualError(t, rdb.SAdd(ctx, "z", "a").Err(), wrongType)
+	assert.Equal(t, "zset", rdb.Type(ctx, "z").Val())
+	assert.Equal(t, "skiplist", rdb.ObjectEncoding(ctx, "z").Val())
+
+	// Copies keep the members and scores
+	assert.Nil(t, rdb.Copy(ctx, "z", "z2", 0, false).Err())
+	assert.Nil(t, rdb.ZAdd(ctx, "z", redis.Z{Score: 2, Member: "b"}).Err())
+	assert.Equal(t, []redis.Z{{Score: 1, Member: "a"}}, rdb.ZRangeWithScores(ctx, "z2", 0, -1).Val())
+}
+
+// sortedScanPairs sorts the member and score pairs of a scan by member.
+func sortedScanPairs(entries []string) []string {
+	pairs := make([][2]string, 0, len(entries)/2)
+	for i := 0; i < len(entries); i += 2 {
+		pairs = append(pairs, [2]string{entries[i], entries[i+1]})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	sorted := make([]string, 0, len(entries))
+	for _, pair := range pairs {
+		sorted = append(sorted, pair[0], pair[1])
+	}
+	return sorted
+}